@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("agent", "", "reconcile interfaces to the desired state of a config file, for running as a DaemonSet")
+	config := c.fs.String("config", envOr("DCB_AGENT_CONFIG", "/etc/go-dcb/desired.json"), "desired state, a snapshot file, re-read when it changes (env DCB_AGENT_CONFIG)")
+	interval := c.fs.Duration("interval", 30*time.Second, "reconcile interval")
+	health := c.fs.String("health", ":8081", "serve /healthz, /readyz and /peers on this address, empty to disable")
+	linkEvents := c.fs.Bool("link-events", true, "reconcile an interface as soon as it comes up or is re-created rather than at the next tick")
+	hks := hooks{}
+	c.fs.Var(hks, "hook", "run a shell command with the event as JSON on stdin, as event=command, repeatable; events: pfc-storm, peer-changed, peer-flapping, drift-reapplied, * for all")
+	stormRate := c.fs.Float64("storm-rate", 1000, "rate of received PFC frames per second on a priority reported as pfc-storm")
+	debounce := c.fs.Duration("debounce", time.Second, "coalesce the link events of an interface within this window into one reapply")
+	cooldown := c.fs.Duration("cooldown", 10*time.Second, "reapply an interface at most once per cooldown, holding back drift found sooner until it ends, 0 to disable")
+	historySize := c.fs.Int("peer-history", 64, "distinct ETS and PFC advertisements of the link partner kept per interface, served on /peers, 0 to keep none")
+	peerFlaps := c.fs.Int("peer-flaps", 3, "changes of the peer advertisement within -peer-flap-window reported as peer-flapping")
+	flapWindow := c.fs.Duration("peer-flap-window", 10*time.Minute, "window peer changes are counted over for -peer-flaps")
+	c.run = func(args []string) int {
+		if len(args) != 0 || *interval <= 0 || *debounce < 0 || *cooldown < 0 || *historySize < 0 || *peerFlaps <= 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if err := checkWritable(); err != nil {
+			log.Error(err)
+			return exitNetlink
+		}
+		return withClient(func(cl *dcb.Client) int {
+			a := &agent{cl: cl, path: *config, interval: *interval, hooks: hks, stormRate: *stormRate,
+				debounce: *debounce, cooldown: *cooldown, pending: map[string]*pendingReapply{}, reapplied: map[string]time.Time{},
+				peerHistory: *historySize, peerFlaps: *peerFlaps, flapWindow: *flapWindow, peers: map[string]*peerHistory{}}
+			if *health != "" {
+				ln, err := listen(*health)
+				if err != nil {
+					log.Error(err)
+					return exitFailure
+				}
+				go http.Serve(ln, a.healthHandler())
+				log.Infof("serving health checks on %v", ln.Addr())
+			}
+			lc, err := cl.NewLinkCache()
+			if err != nil {
+				log.Error(err)
+				return exitNetlink
+			}
+			defer lc.Close()
+			a.linkCache = lc
+			if *linkEvents {
+				w, err := dcb.WatchLinks()
+				if err != nil {
+					log.Error(err)
+					return exitNetlink
+				}
+				defer w.Close()
+				a.links = make(chan string, 16)
+				go a.watchLinks(w)
+			}
+			return a.run()
+		})
+	}
+}
+
+// An agent periodically makes the interfaces match a desired state file.
+type agent struct {
+	cl       *dcb.Client
+	path     string
+	interval time.Duration
+	// linkCache follows the interfaces that configs with templates or
+	// patterns expand to, without a link dump per tick.
+	linkCache *dcb.LinkCache
+
+	raw     []byte // content of the last loaded file
+	config  *configFile
+	desired []*dcb.Snapshot
+	// links receives the interfaces that came up or were re-created, and ""
+	// when link events were lost.
+	links chan string
+
+	hooks     hooks
+	stormRate float64
+	observed  map[string]*observation
+
+	debounce time.Duration
+	cooldown time.Duration
+	// pending holds the interfaces to reapply outside of the ticks, after
+	// link events or a cooldown, and reapplied when each was last reapplied.
+	pending   map[string]*pendingReapply
+	reapplied map[string]time.Time
+
+	peerHistory int
+	peerFlaps   int
+	flapWindow  time.Duration
+
+	mu       sync.Mutex
+	lastLoop time.Time
+	ready    bool
+	problem  string
+	peers    map[string]*peerHistory
+}
+
+func (a *agent) run() int {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	if wd := sdWatchdog(); wd > 0 && a.interval > wd/2 {
+		log.Warnf("interval %v exceeds half the systemd watchdog timeout %v, the unit will be restarted", a.interval, wd)
+	}
+
+	notified := false
+	full := true
+	for {
+		if full && a.loop() && !notified {
+			sdNotify("READY=1")
+			notified = true
+		}
+		sdNotify("WATCHDOG=1")
+		full = true
+		var retry <-chan time.Time
+		if due := a.nextDue(); !due.IsZero() {
+			retry = time.After(time.Until(due))
+		}
+		select {
+		case sig := <-sigs:
+			if sig == syscall.SIGHUP {
+				// reload and reconcile now rather than at the next tick
+				log.Infof("%v received, reloading %s", sig, a.path)
+				ticker.Reset(a.interval)
+				continue
+			}
+			log.Infof("%v received, stopping", sig)
+			sdNotify("STOPPING=1")
+			return exitOK
+		case ifname := <-a.links:
+			if ifname == "" {
+				log.Warn("link events lost, reconciling every interface")
+				ticker.Reset(a.interval)
+				continue
+			}
+			a.linkEvent(ifname)
+			full = false
+		case <-retry:
+			a.flush()
+			full = false
+		case <-ticker.C:
+		}
+	}
+}
+
+// A pendingReapply is a reconcile of an interface waiting for due: the
+// end of the debounce window of its first link event, or of its cooldown.
+type pendingReapply struct {
+	due    time.Time
+	events int // link events coalesced, 0 if only held back by the cooldown
+}
+
+// linkEvent schedules the reconcile of ifname after it came up or was
+// re-created, without waiting for the next tick. Events within the
+// debounce window of the first are coalesced into that reconcile, so a
+// flapping link is reapplied once.
+func (a *agent) linkEvent(ifname string) {
+	p := a.pending[ifname]
+	if p == nil {
+		p = &pendingReapply{due: time.Now().Add(a.debounce)}
+		a.pending[ifname] = p
+	}
+	p.events++
+}
+
+// nextDue returns when the earliest pending reconcile is due, zero if none
+// is pending.
+func (a *agent) nextDue() time.Time {
+	var next time.Time
+	for _, p := range a.pending {
+		if next.IsZero() || p.due.Before(next) {
+			next = p.due
+		}
+	}
+	return next
+}
+
+// flush reconciles the interfaces whose pending reconcile is due.
+func (a *agent) flush() {
+	now := time.Now()
+	for ifname, p := range a.pending {
+		if p.due.After(now) {
+			continue
+		}
+		delete(a.pending, ifname)
+		for _, want := range a.desired {
+			if want.Ifname != ifname {
+				continue
+			}
+			switch {
+			case p.events > 1:
+				log.Infof("ifname: %v, %d link events within %v coalesced, reapplying", ifname, p.events, a.debounce)
+			case p.events == 1:
+				log.Infof("ifname: %v, link up or re-created, reapplying", ifname)
+			}
+			if err := a.reconcileOne(want); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
+
+// watchLinks sends to a.links the interfaces that come up, or appear while
+// up, until w is closed. Links are seeded from the current interfaces so
+// only changes are reported; other notifications, such as of address or
+// MTU changes, are ignored.
+func (a *agent) watchLinks(w *dcb.LinkWatcher) {
+	type link struct {
+		index int
+		up    bool
+	}
+	links := map[string]link{}
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			up := iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagRunning != 0
+			links[iface.Name] = link{index: iface.Index, up: up}
+		}
+	}
+	for {
+		events, err := w.Next()
+		if errors.Is(err, syscall.ENOBUFS) {
+			a.links <- ""
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				log.Errorf("link events stopped: %v", err)
+			}
+			return
+		}
+		for _, ev := range events {
+			old, known := links[ev.Ifname]
+			if ev.Deleted {
+				delete(links, ev.Ifname)
+				continue
+			}
+			links[ev.Ifname] = link{index: ev.Index, up: ev.Up}
+			if ev.Up && (!known || !old.up || old.index != ev.Index) {
+				a.links <- ev.Ifname
+			}
+		}
+	}
+}
+
+// loop re-reads the config file if it changed and reconciles every
+// interface of it once. It reports whether all of it succeeded.
+func (a *agent) loop() bool {
+	loadErr := a.load()
+	if loadErr != nil {
+		log.Error(loadErr)
+	}
+	// reconcile logs the failure of each interface
+	err := a.reconcile()
+	if loadErr != nil {
+		err = loadErr
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastLoop = time.Now()
+	a.ready = err == nil
+	a.problem = ""
+	if err != nil {
+		a.problem = err.Error()
+	}
+	return a.ready
+}
+
+// load reads the config file, keeping the previous desired state if it is
+// unchanged. A file that cannot be read or decoded makes the agent unready
+// but keeps the previous state, which is still reconciled. ConfigMap
+// volumes are updated by swapping a symlink, so the content is compared
+// rather than the modification time. A templated file, see configFile, is
+// expanded again every time, for the interfaces and link speeds of the
+// moment.
+func (a *agent) load() error {
+	b, err := os.ReadFile(a.path)
+	if err != nil {
+		return err
+	}
+	if a.config == nil || !bytes.Equal(b, a.raw) {
+		config, err := parseConfig(a.path, b)
+		if err != nil {
+			return err
+		}
+		if a.config != nil {
+			log.Infof("config %s changed, reloaded", a.path)
+		}
+		a.raw, a.config = b, config
+	} else if a.config.plain() {
+		return nil
+	}
+	desired := a.config.snaps
+	if !a.config.plain() {
+		if err := a.linkCache.Err(); err != nil {
+			return fmt.Errorf("follow links: %w", err)
+		}
+		ifnames := a.linkCache.Names()
+		slices.SortFunc(ifnames, dcb.CompareIfnames)
+		var err error
+		if desired, err = a.config.expand(ifnames); err != nil {
+			return err
+		}
+	}
+	// the filter may exclude interfaces the config names or matches
+	a.desired = slices.DeleteFunc(slices.Clone(desired), func(s *dcb.Snapshot) bool { return !global.ifaces.allows(s.Ifname) })
+	return nil
+}
+
+// reconcile applies the desired state of each interface that drifted from
+// it, and returns the first error.
+func (a *agent) reconcile() error {
+	var first error
+	for _, want := range a.desired {
+		if err := a.reconcileOne(want); err != nil {
+			log.Error(err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (a *agent) reconcileOne(want *dcb.Snapshot) error {
+	have, err := a.cl.Snapshot(want.Ifname)
+	if err != nil {
+		return err
+	}
+	if len(a.hooks) > 0 || a.peerHistory > 0 {
+		a.observe(have)
+	}
+	// objects the desired state leaves out and read-only fields such as
+	// pfc_cap are not drift
+	changes := dcb.Pending(have, want)
+	if len(changes) == 0 {
+		return nil
+	}
+	if last, ok := a.reapplied[want.Ifname]; ok && time.Since(last) < a.cooldown {
+		// another writer or a flapping peer undoing the config at once
+		// would otherwise get it reapplied as fast as drift is seen
+		due := last.Add(a.cooldown)
+		if p := a.pending[want.Ifname]; p == nil || p.due.Before(due) {
+			log.Warnf("ifname: %v, drifted again %v after the last reapply, suppressed until %v", want.Ifname,
+				time.Since(last).Round(time.Millisecond), due.Format(time.TimeOnly))
+			a.pending[want.Ifname] = &pendingReapply{due: due}
+		}
+		return nil
+	}
+	for _, ch := range changes {
+		log.Infof("ifname: %v, drift %v", want.Ifname, ch)
+	}
+	a.reapplied[want.Ifname] = time.Now()
+	var skipped []dcb.Object
+	err = audited(a.cl, "agent", "reconcile "+a.path, want.Ifname, func() error {
+		var err error
+		skipped, err = restore(a.cl, want.Ifname, want)
+		return err
+	})
+	for _, obj := range skipped {
+		log.Warnf("ifname: %v, %s not available on this driver or kernel, skipped", want.Ifname, obj)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("ifname: %v, reconciled", want.Ifname)
+	a.hooks.fire(eventDriftReapplied, want.Ifname, changes)
+	return nil
+}
+
+// An observation is what the agent last saw of an interface, which events
+// are detected against.
+type observation struct {
+	time     time.Time
+	pfc      *dcb.IEEEPFC
+	peer     *dcb.Peer
+	storming bool
+}
+
+// A stormPriority is a priority of a pfc-storm event.
+type stormPriority struct {
+	Priority int     `json:"priority"`
+	Rate     float64 `json:"rate"` // received PFC frames per second
+}
+
+// observe fires pfc-storm when the rate of received PFC frames on a
+// priority exceeds the storm rate, once per storm, and peer-changed when
+// the link partner advertises another config than at the last look.
+func (a *agent) observe(have *dcb.Snapshot) {
+	if a.observed == nil {
+		a.observed = map[string]*observation{}
+	}
+	now := &observation{time: time.Now(), pfc: have.PFC}
+	if peer, err := a.cl.GetPeer(have.Ifname); err == nil {
+		now.peer = peer
+		if a.peerHistory > 0 {
+			a.recordPeer(have.Ifname, now.time, peer)
+		}
+	}
+	last := a.observed[have.Ifname]
+	a.observed[have.Ifname] = now
+	if last == nil {
+		return
+	}
+
+	if last.pfc != nil && now.pfc != nil && a.stormRate > 0 {
+		var storm []stormPriority
+		secs := now.time.Sub(last.time).Seconds()
+		for prio := range now.pfc.Indications {
+			// counters restart when the driver resets
+			if now.pfc.Indications[prio] < last.pfc.Indications[prio] {
+				continue
+			}
+			rate := float64(now.pfc.Indications[prio]-last.pfc.Indications[prio]) / secs
+			if rate >= a.stormRate {
+				storm = append(storm, stormPriority{prio, rate})
+			}
+		}
+		now.storming = len(storm) > 0
+		if now.storming && !last.storming {
+			log.Warnf("ifname: %v, pfc storm: %+v", have.Ifname, storm)
+			a.hooks.fire(eventPFCStorm, have.Ifname, storm)
+		}
+	}
+	if last.peer != nil && now.peer != nil && !reflect.DeepEqual(last.peer, now.peer) {
+		log.Infof("ifname: %v, peer config changed", have.Ifname)
+		a.hooks.fire(eventPeerChanged, have.Ifname, map[string]*dcb.Peer{"old": last.peer, "new": now.peer})
+	}
+}
+
+// recordPeer adds peer to the history of ifname, firing peer-flapping when
+// it changed -peer-flaps times within -peer-flap-window, once per episode.
+func (a *agent) recordPeer(ifname string, t time.Time, peer *dcb.Peer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	h := a.peers[ifname]
+	if h == nil {
+		h = &peerHistory{}
+		a.peers[ifname] = h
+	}
+	if !h.add(t, peer, a.peerHistory) {
+		return
+	}
+	changes := h.changesSince(t.Add(-a.flapWindow))
+	flapping := changes >= a.peerFlaps
+	if flapping && !h.Flapping {
+		log.Warnf("ifname: %v, peer flapping, %d changes of its advertisement within %v", ifname, changes, a.flapWindow)
+		a.hooks.fire(eventPeerFlapping, ifname, h.Records)
+	}
+	h.Flapping = flapping
+}
+
+// healthHandler serves /healthz, failing when the reconcile loop has not
+// run for three intervals, /readyz, failing until a reconcile of every
+// interface succeeded and whenever the last one did not, and /peers, the
+// peer histories by interface, or /peers/{ifname} for one.
+func (a *agent) healthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		last := a.lastLoop
+		a.mu.Unlock()
+		if !last.IsZero() && time.Since(last) > 3*a.interval {
+			http.Error(w, fmt.Sprintf("reconcile loop stalled since %v", last.Format(time.RFC3339)), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		ready, problem := a.ready, a.problem
+		a.mu.Unlock()
+		if !ready {
+			if problem == "" {
+				problem = "not reconciled yet"
+			}
+			http.Error(w, problem, http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /peers", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		writeJSON(w, http.StatusOK, a.peers)
+	})
+	mux.HandleFunc("GET /peers/{ifname}", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		h := a.peers[r.PathValue("ifname")]
+		if h == nil {
+			http.Error(w, "no peer history of "+r.PathValue("ifname"), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, h)
+	})
+	return mux
+}
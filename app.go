@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	g := newGroup("app", "show or edit the APP priority table")
+
+	show := newSubcommand(g, "show", "<ifname>", "show the APP table, DSCP map and trust state")
+	show.ifaceArgs = true
+	show.run = func(args []string) int {
+		if len(args) != 1 {
+			show.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			apps, err := cl.GetApp(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			trust, err := cl.GetEffectiveTrust(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printApps(args[0], apps, trust)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(g, "dscp-set", "<ifname> <dscp[-dscp]>:<prio>...", "map DSCP values or ranges to a priority")
+	set.ifaceArgs = true
+	set.run = func(args []string) int {
+		if len(args) < 2 {
+			set.fs.Usage()
+			return exitUsage
+		}
+		byPrio := map[uint8][]uint8{}
+		for _, arg := range args[1:] {
+			r, p, ok := strings.Cut(arg, ":")
+			prio, err := strconv.ParseUint(p, 10, 8)
+			if !ok || err != nil || prio >= dcb.IEEE_8021Q_MAX_PRIORITIES {
+				log.Errorf("invalid mapping %q", arg)
+				return exitUsage
+			}
+			from, to, err := parseDSCPRange(r)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			for d := int(from); d <= int(to); d++ {
+				byPrio[uint8(prio)] = append(byPrio[uint8(prio)], uint8(d))
+			}
+		}
+		return withClient(func(cl *dcb.Client) int {
+			for prio := uint8(0); prio < dcb.IEEE_8021Q_MAX_PRIORITIES; prio++ {
+				if len(byPrio[prio]) == 0 {
+					continue
+				}
+				if err := audited(cl, cliUser(), "app dscp-set", args[0], func() error { return cl.SetDSCP(args[0], prio, byPrio[prio]...) }); err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+			}
+			return exitOK
+		})
+	}
+
+	sa := newSubcommand(setGroup, "app", "<ifname>", "add, reprioritize or remove APP entries, leaving the rest of the table as it is")
+	sa.ifaceArgs = true
+	add := sa.fs.String("add", "", "comma-separated selector:protocol:prio entries, such as dscp:46:5 or ethertype:0x8915:3, replacing the priority of an entry of the same selector and protocol")
+	remove := sa.fs.String("del", "", "comma-separated selector:protocol entries to remove, or selector:protocol:prio to remove that priority only")
+	sa.run = func(args []string) int {
+		if len(args) != 1 || (*add == "" && *remove == "") {
+			sa.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			apps, err := cl.GetApp(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			if apps, err = editApps(apps, *add, *remove); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if err := audited(cl, cliUser(), "set app", args[0], func() error { return cl.SetApps(args[0], apps) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+
+	del := newSubcommand(g, "dscp-clear", "<ifname> <dscp[-dscp]>...|all", "remove the APP entries of DSCP values or ranges")
+	del.ifaceArgs = true
+	del.run = func(args []string) int {
+		if len(args) < 2 {
+			del.fs.Usage()
+			return exitUsage
+		}
+		var dscps []uint8
+		for _, arg := range args[1:] {
+			if arg == "all" {
+				arg = fmt.Sprintf("0-%d", dcb.DSCPMax)
+			}
+			from, to, err := parseDSCPRange(arg)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			for d := int(from); d <= int(to); d++ {
+				dscps = append(dscps, uint8(d))
+			}
+		}
+		return withClient(func(cl *dcb.Client) int {
+			if err := audited(cl, cliUser(), "app dscp-clear", args[0], func() error { return cl.ClearDSCP(args[0], dscps...) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+
+	ceeGet := newSubcommand(g, "cee-get", "<ifname> <selector:protocol>...", "show the priorities of protocols in the CEE APP map, for drivers without the IEEE APP table")
+	ceeGet.ifaceArgs = true
+	ceeGet.run = func(args []string) int {
+		if len(args) < 2 {
+			ceeGet.fs.Usage()
+			return exitUsage
+		}
+		var keys []dcb.App
+		for _, arg := range args[1:] {
+			a, anyPrio, err := parseAppEntry(arg, true)
+			if err != nil || !anyPrio {
+				log.Errorf("invalid protocol %q, want selector:protocol", arg)
+				return exitUsage
+			}
+			keys = append(keys, a)
+		}
+		return withClient(func(cl *dcb.Client) int {
+			fmt.Printf("ifname: %s\n", args[0])
+			for _, k := range keys {
+				apps, err := cl.GetCEEApp(args[0], k.Selector, k.Protocol)
+				if err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+				for _, a := range apps {
+					printApp("cee app", a)
+				}
+			}
+			return exitOK
+		})
+	}
+
+	ceeSet := newSubcommand(g, "cee-set", "<ifname> <selector:protocol[:prio]>...", "map protocols to priorities in the CEE APP map; entries of the same protocol add up, and one without prio removes it")
+	ceeSet.ifaceArgs = true
+	ceeSet.run = func(args []string) int {
+		if len(args) < 2 {
+			ceeSet.fs.Usage()
+			return exitUsage
+		}
+		var keys []dcb.App
+		prios := map[dcb.App][]uint8{}
+		for _, arg := range args[1:] {
+			a, anyPrio, err := parseAppEntry(arg, true)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			k := dcb.App{Selector: a.Selector, Protocol: a.Protocol}
+			if _, ok := prios[k]; !ok {
+				keys = append(keys, k)
+				prios[k] = []uint8{}
+			}
+			if !anyPrio {
+				prios[k] = append(prios[k], a.Priority)
+			}
+		}
+		return withClient(func(cl *dcb.Client) int {
+			for _, k := range keys {
+				if err := audited(cl, cliUser(), "app cee-set", args[0], func() error { return cl.SetCEEApp(args[0], k.Selector, k.Protocol, prios[k]...) }); err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+			}
+			return exitOK
+		})
+	}
+}
+
+// editApps returns apps with the entries of the set app -del flag removed
+// and those of -add added, an added entry replacing those of the same
+// selector and protocol.
+func editApps(apps []dcb.App, add, remove string) ([]dcb.App, error) {
+	apps = slices.Clone(apps)
+	for _, e := range splitList(remove) {
+		if e == "" {
+			continue
+		}
+		a, anyPrio, err := parseAppEntry(e, true)
+		if err != nil {
+			return nil, fmt.Errorf("del: %w", err)
+		}
+		n := len(apps)
+		apps = slices.DeleteFunc(apps, func(x dcb.App) bool {
+			return x.Selector == a.Selector && x.Protocol == a.Protocol && (anyPrio || x.Priority == a.Priority)
+		})
+		if len(apps) == n {
+			return nil, fmt.Errorf("del: no entry %s in the app table", e)
+		}
+	}
+	for _, e := range splitList(add) {
+		if e == "" {
+			continue
+		}
+		a, _, err := parseAppEntry(e, false)
+		if err != nil {
+			return nil, fmt.Errorf("add: %w", err)
+		}
+		apps = slices.DeleteFunc(apps, func(x dcb.App) bool {
+			return x.Selector == a.Selector && x.Protocol == a.Protocol
+		})
+		apps = append(apps, a)
+	}
+	return apps, nil
+}
+
+// parseAppEntry parses an APP entry as selector:protocol:prio, protocols
+// in decimal or 0x hex. If optPrio is set the priority may be left out,
+// which anyPrio reports.
+func parseAppEntry(s string, optPrio bool) (a dcb.App, anyPrio bool, err error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 && !(optPrio && len(parts) == 2) {
+		return a, false, fmt.Errorf("invalid app entry %q, want selector:protocol:prio", s)
+	}
+	if a.Selector, err = dcb.ParseSelector(parts[0]); err != nil {
+		return a, false, err
+	}
+	proto, err := strconv.ParseUint(parts[1], 0, 16)
+	if err != nil {
+		return a, false, fmt.Errorf("invalid protocol %q in %q", parts[1], s)
+	}
+	a.Protocol = uint16(proto)
+	if len(parts) == 2 {
+		return a, true, nil
+	}
+	prio, err := strconv.ParseUint(parts[2], 10, 8)
+	if err != nil || prio >= dcb.IEEE_8021Q_MAX_PRIORITIES {
+		return a, false, fmt.Errorf("invalid priority %q in %q", parts[2], s)
+	}
+	a.Priority = uint8(prio)
+	return a, false, nil
+}
+
+// printApp prints an APP entry, naming the protocol it matches if known.
+func printApp(label string, a dcb.App) {
+	if name := a.Known(); name != "" {
+		fmt.Printf("%s: %+v (%s)\n", label, a, name)
+		return
+	}
+	fmt.Printf("%s: %+v\n", label, a)
+}
+
+func printApps(ifname string, apps []dcb.App, trust dcb.Trust) {
+	fmt.Printf("ifname: %s\n", ifname)
+	for _, a := range apps {
+		printApp("app", a)
+	}
+	fmt.Printf("dscp map: %v\n", dcb.DSCPApps(apps))
+	if trust.Inferred && !dcb.TrustTableAvailable() {
+		fmt.Printf("trust: %s (inferred from app table, trust table not available on this kernel)\n", trust.Mode)
+	} else if trust.Inferred {
+		fmt.Printf("trust: %s (inferred from app table)\n", trust.Mode)
+	} else {
+		fmt.Printf("trust: %s (trust table: %v)\n", trust.Mode, trust.Selectors)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("apply", "<file> <ifname>", "apply a snapshot file or mlnx_qos description to an interface, or with -adapter to every port of its adapter, all or nothing")
+	adapter := c.fs.Bool("adapter", false, "apply to every port sharing the PCI device or switch of ifname, rolling all of them back if one fails")
+	check := c.fs.Bool("check", false, "change nothing, print a JSON report of what would change per interface and object instead")
+	format := c.fs.String("format", "auto", "file format: json for snapshot files, mlnx_qos for lines of mlnx_qos options such as --trust dscp --pfc 0,0,0,1,0,0,0,0, or auto to tell them apart")
+	c.run = func(args []string) int {
+		if len(args) != 2 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		path, ifname := args[0], args[1]
+		b, err := os.ReadFile(path)
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		if *format == "auto" {
+			*format = "mlnx_qos"
+			if t := bytes.TrimSpace(b); len(t) > 0 && (t[0] == '[' || t[0] == '{') {
+				*format = "json"
+			}
+		}
+		var config *configFile
+		var lines []*mlnxQOSLine
+		switch *format {
+		case "json":
+			config, err = parseConfig(path, b)
+		case "mlnx_qos":
+			lines, err = readMLNXQOS(bytes.NewReader(b))
+		default:
+			log.Errorf("unknown file format %q", *format)
+			return exitUsage
+		}
+		if err != nil {
+			log.Errorf("%s: %v", path, err)
+			return exitUsage
+		}
+
+		ports := []string{ifname}
+		if *adapter {
+			if ports, err = dcb.SiblingPorts(ifname); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			log.Infof("ifname: %v, adapter ports %s", ifname, strings.Join(ports, ", "))
+		}
+		var targets []*dcb.Snapshot
+		if config != nil {
+			if targets, err = snapshotsFor(ports, config); err != nil {
+				log.Errorf("%s: %v", path, err)
+				return exitUsage
+			}
+		}
+		return withClient(func(cl *dcb.Client) int {
+			if lines != nil {
+				// mlnx_qos options change the current state of each port
+				for _, port := range ports {
+					base, err := cl.Snapshot(port)
+					if err != nil {
+						log.Error(err)
+						return exitCode(err)
+					}
+					t, err := mlnxQOSSnapshot(lines, port, base)
+					if err != nil {
+						log.Errorf("%s: %v", path, err)
+						return exitUsage
+					}
+					targets = append(targets, t)
+				}
+			}
+			if *check {
+				return applyCheck(cl, ports, targets)
+			}
+			var skipped map[string][]dcb.Object
+			err := auditedAll(cl, cliUser(), "apply "+path, ports, func() error {
+				var err error
+				skipped, err = cl.RestoreAll(ports, targets)
+				return err
+			})
+			for _, port := range ports {
+				for _, obj := range skipped[port] {
+					log.Warnf("ifname: %v, %s not available on this driver or kernel, skipped", port, obj)
+				}
+			}
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Infof("applied %s to %s", path, strings.Join(ports, ", "))
+			return exitOK
+		})
+	}
+}
+
+// snapshotsFor picks the snapshot of each of ifnames from config: the one
+// snapshot of a single-interface file for all of them, or else the one
+// saved from the interface of the same name, or for a templated file the
+// one it expands to.
+func snapshotsFor(ifnames []string, config *configFile) ([]*dcb.Snapshot, error) {
+	out := make([]*dcb.Snapshot, len(ifnames))
+	snaps := config.snaps
+	for i, ifname := range ifnames {
+		if !config.plain() {
+			s, err := config.expand([]string{ifname})
+			if err != nil {
+				return nil, err
+			}
+			if len(s) == 0 {
+				return nil, fmt.Errorf("no snapshot or pattern for %s in the file", ifname)
+			}
+			out[i] = s[0]
+			continue
+		}
+		if len(snaps) == 1 {
+			out[i] = snaps[0]
+			continue
+		}
+		for _, s := range snaps {
+			if s.Ifname == ifname {
+				out[i] = s
+			}
+		}
+		if out[i] == nil {
+			return nil, fmt.Errorf("no snapshot of %s among the %d in the file", ifname, len(snaps))
+		}
+	}
+	return out, nil
+}
+
+// checkSchema is the version of checkReport, see dcb.SnapshotSchema.
+const checkSchema = 1
+
+// A checkReport is the output of apply -check, described by
+// schema/check.json.
+type checkReport struct {
+	Schema     int          `json:"schema"`
+	Changed    bool         `json:"changed"`
+	Interfaces []checkIface `json:"interfaces"`
+}
+
+type checkIface struct {
+	Ifname  string `json:"ifname"`
+	Changed bool   `json:"changed"`
+	// Objects holds changed, unchanged or unsupported for each object the
+	// file sets.
+	Objects map[dcb.Object]string `json:"objects"`
+	Changes []dcb.Change          `json:"changes"`
+}
+
+// applyCheck prints what applying targets to ports would change. Objects
+// the driver lacks, which apply skips, are reported unsupported rather than
+// changed, so that a run after apply reports no change.
+func applyCheck(cl *dcb.Client, ports []string, targets []*dcb.Snapshot) int {
+	report := checkReport{Schema: checkSchema, Interfaces: []checkIface{}}
+	for i, port := range ports {
+		have, err := cl.Snapshot(port)
+		if err != nil {
+			log.Error(err)
+			return exitCode(err)
+		}
+		caps, err := cl.Probe(port)
+		if err != nil {
+			log.Error(err)
+			return exitCode(err)
+		}
+		ci := checkIface{Ifname: port, Objects: map[dcb.Object]string{}, Changes: []dcb.Change{}}
+		for _, obj := range snapshotObjectsOf(targets[i]) {
+			ci.Objects[obj] = "unchanged"
+			if !caps.Supports(obj) {
+				ci.Objects[obj] = "unsupported"
+			}
+		}
+		for _, ch := range dcb.Pending(have, targets[i]) {
+			obj := changeObject(ch)
+			if ci.Objects[obj] == "unsupported" {
+				continue
+			}
+			ci.Objects[obj] = "changed"
+			ci.Changes = append(ci.Changes, ch)
+			ci.Changed = true
+		}
+		report.Changed = report.Changed || ci.Changed
+		report.Interfaces = append(report.Interfaces, ci)
+	}
+	if err := printJSON(&report, true); err != nil {
+		log.Error(err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// changeObject returns the object the change ch is in.
+func changeObject(ch dcb.Change) dcb.Object {
+	name, _, _ := strings.Cut(ch.Path, ".")
+	name, _, _ = strings.Cut(name, "[")
+	if name == "apps" {
+		return dcb.ObjectApp
+	}
+	return dcb.Object(name)
+}
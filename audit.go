@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// auditOptions configure the audit trail of configuration changes, off
+// unless a log file or syslog is requested.
+type auditOptions struct {
+	path   string
+	syslog bool
+
+	once   sync.Once
+	writer syslogWriter
+}
+
+// A syslogWriter sends audit records to syslog, where the platform has it.
+type syslogWriter interface {
+	Notice(m string) error
+}
+
+func (o *auditOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.path, "audit-log", envOr("DCB_AUDIT_LOG", ""),
+		"append a JSON record of every configuration change to this file (env DCB_AUDIT_LOG)")
+	fs.BoolVar(&o.syslog, "audit-syslog", false, "also send audit records to syslog")
+}
+
+func (o *auditOptions) enabled() bool {
+	return o.path != "" || o.syslog
+}
+
+// An auditRecord is one line of the audit log.
+type auditRecord struct {
+	Time    time.Time    `json:"time"`
+	Who     string       `json:"who"`
+	Op      string       `json:"op"`
+	Ifname  string       `json:"ifname"`
+	Changes []dcb.Change `json:"changes"`
+}
+
+// audited runs the change fn against ifname and, if it succeeds and
+// auditing is enabled, records the difference of the DCB state before and
+// after it. who identifies the origin of the change and op what was run.
+// Changes to interfaces owned by lldpad or the firmware are refused unless
+// forced.
+func audited(cl *dcb.Client, who, op, ifname string, fn func() error) error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	if err := cl.CheckOwner(ifname); err != nil {
+		if !global.force || !errors.As(err, new(*dcb.OwnerError)) {
+			return err
+		}
+		log.Warnf("%v, forced", err)
+	}
+	return recorded(cl, who, op, ifname, fn)
+}
+
+// auditedAll is audited for a change fn spanning the interfaces ifnames,
+// as dcb.Client.RestoreAll makes: every owner is checked before fn runs,
+// and a record is written per interface.
+func auditedAll(cl *dcb.Client, who, op string, ifnames []string, fn func() error) error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	for _, ifname := range ifnames {
+		if err := cl.CheckOwner(ifname); err != nil {
+			if !global.force || !errors.As(err, new(*dcb.OwnerError)) {
+				return err
+			}
+			log.Warnf("%v, forced", err)
+		}
+	}
+	before := make(map[string]*dcb.Snapshot)
+	if global.audit.enabled() {
+		for _, ifname := range ifnames {
+			before[ifname], _ = cl.Snapshot(ifname)
+		}
+	}
+	if err := fn(); err != nil {
+		var re *dcb.RestoreAllError
+		if errors.As(err, &re) {
+			var be *dcb.BlockedError
+			if errors.As(cl.Diagnose(re.Ifname, re.Err), &be) {
+				return fmt.Errorf("%w; %s", err, strings.ReplaceAll(blockHints[be.Reason], "<ifname>", re.Ifname))
+			}
+		}
+		return err
+	}
+	if !global.audit.enabled() {
+		return nil
+	}
+	for _, ifname := range ifnames {
+		rec := auditRecord{Time: time.Now(), Who: who, Op: op, Ifname: ifname, Changes: []dcb.Change{}}
+		if after, err := cl.Snapshot(ifname); err == nil && before[ifname] != nil {
+			rec.Changes = append(rec.Changes, dcb.Diff(before[ifname], after)...)
+		}
+		global.audit.write(&rec)
+	}
+	return nil
+}
+
+// blockHints tell how to lift the blocks dcb.Client.Diagnose finds.
+var blockHints = map[dcb.BlockReason]string{
+	dcb.BlockedByFirmware: "run `set dcbx <ifname> host` first",
+	dcb.BlockedByLLDPAD:   "stop lldpad or configure the port with lldptool",
+	dcb.BlockedByWilling:  "run `set ets <ifname> -willing off` first",
+}
+
+// recorded is audited without the owner check, for the changes that hand
+// the config over to the host.
+func recorded(cl *dcb.Client, who, op, ifname string, fn func() error) error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	run := func() error {
+		err := cl.Diagnose(ifname, fn())
+		var be *dcb.BlockedError
+		if errors.As(err, &be) {
+			return fmt.Errorf("%w; %s", err, strings.ReplaceAll(blockHints[be.Reason], "<ifname>", ifname))
+		}
+		return err
+	}
+	if !global.audit.enabled() {
+		return run()
+	}
+	before, _ := cl.Snapshot(ifname)
+	if err := run(); err != nil {
+		return err
+	}
+	rec := auditRecord{Time: time.Now(), Who: who, Op: op, Ifname: ifname, Changes: []dcb.Change{}}
+	if after, err := cl.Snapshot(ifname); err == nil && before != nil {
+		rec.Changes = append(rec.Changes, dcb.Diff(before, after)...)
+	}
+	global.audit.write(&rec)
+	return nil
+}
+
+// write appends rec to the log file and syslog. A failing audit trail is
+// logged but does not fail the change, which already happened.
+func (o *auditOptions) write(rec *auditRecord) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		log.Errorf("audit: encode record: %v", err)
+		return
+	}
+	if o.path != "" {
+		// O_APPEND writes of a single line are atomic, several processes
+		// can share the file
+		f, err := os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err == nil {
+			_, err = f.Write(append(b, '\n'))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			log.Errorf("audit: %v", err)
+		}
+	}
+	if o.syslog {
+		o.once.Do(func() {
+			w, err := dialSyslog()
+			if err != nil {
+				log.Errorf("audit: connect to syslog: %v", err)
+				return
+			}
+			o.writer = w
+		})
+		if o.writer != nil {
+			if err := o.writer.Notice(string(b)); err != nil {
+				log.Errorf("audit: syslog: %v", err)
+			}
+		}
+	}
+}
+
+// cliUser identifies the operator running a command, preferring the user
+// who invoked sudo over root. SUDO_USER is only believed when the process
+// runs as root and SUDO_UID is that user's, as any user can set it and a
+// setcap binary runs with the caller's uid.
+func cliUser() string {
+	if name := os.Getenv("SUDO_USER"); name != "" && os.Geteuid() == 0 {
+		if u, err := user.Lookup(name); err == nil && u.Uid == os.Getenv("SUDO_UID") {
+			return name + " (sudo)"
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return fmt.Sprintf("uid %d", os.Getuid())
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// dialSyslog fails where log/syslog does not exist; -audit-log still works.
+func dialSyslog() (syslogWriter, error) {
+	return nil, fmt.Errorf("syslog is not available on %s", runtime.GOOS)
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !plan9
+
+package main
+
+import "log/syslog"
+
+// dialSyslog connects to the local syslog daemon, logging as the daemon
+// facility under the program name.
+func dialSyslog() (syslogWriter, error) {
+	w, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_DAEMON, progName())
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
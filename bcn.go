@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("bcn", "<ifname>", "show the CEE backward congestion notification config")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			bcn, err := cl.GetBCN(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			fmt.Printf("ifname: %s\n", args[0])
+			fmt.Printf("cee bcn: %+v\n", *bcn)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(setGroup, "bcn", "<ifname> <key>=<value>...", "change the CEE BCN config and commit it, keys: rp0..rp7 "+strings.Join(bcnKeys, " "))
+	set.ifaceArgs = true
+	set.run = func(args []string) int {
+		if len(args) < 2 {
+			set.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			bcn, err := cl.GetBCN(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			if err := applyBCN(bcn, args[1:]); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			var status uint8
+			err = audited(cl, cliUser(), "set bcn", args[0], func() error {
+				if err := cl.SetBCN(args[0], bcn); err != nil {
+					return err
+				}
+				var err error
+				status, err = cl.CommitCEE(args[0])
+				return err
+			})
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Debugf("ifname: %v, cee config committed, status %d", args[0], status)
+			return exitOK
+		})
+	}
+}
+
+// bcnKeys are the u32 BCN parameters in DCB_BCN_ATTR_* order.
+var bcnKeys = []string{"bcna0", "bcna1", "alpha", "beta", "gd", "gi", "tmax", "td", "rmin", "w", "rd", "ru", "wrtt", "ri"}
+
+// applyBCN sets the key=value pairs of args on bcn.
+func applyBCN(bcn *dcb.BCN, args []string) error {
+	params := map[string]*uint32{}
+	for i, p := range []*uint32{
+		&bcn.BCNA[0], &bcn.BCNA[1], &bcn.Alpha, &bcn.Beta, &bcn.GD, &bcn.GI, &bcn.TMax,
+		&bcn.TD, &bcn.RMin, &bcn.W, &bcn.RD, &bcn.RU, &bcn.WRTT, &bcn.RI,
+	} {
+		params[bcnKeys[i]] = p
+	}
+
+	for _, arg := range args {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("invalid setting %q, want <key>=<value>", arg)
+		}
+		if rp, found := strings.CutPrefix(k, "rp"); found {
+			prio, err := strconv.ParseUint(rp, 10, 8)
+			if err != nil || prio >= dcb.CEE_DCBX_MAX_PRIO {
+				return fmt.Errorf("invalid key %q", k)
+			}
+			val, err := strconv.ParseUint(v, 10, 8)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q", k, v)
+			}
+			bcn.RP[prio] = uint8(val)
+			continue
+		}
+		p, found := params[k]
+		if !found {
+			return fmt.Errorf("invalid key %q", k)
+		}
+		val, err := strconv.ParseUint(v, 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %q", k, v)
+		}
+		*p = uint32(val)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("bench", "<ifname> [ifname...]", "measure the latency and throughput of DCB_CMD_IEEE_GET, for sizing polling intervals")
+	c.ifaceArgs = true
+	n := c.fs.Int("n", 1000, "queries per interface")
+	concurrency := c.fs.Int("concurrency", 1, "queries in flight at once, each on its own socket")
+	perQuery := c.fs.Bool("dial", false, "dial a socket per query, as the package-level functions do, instead of reusing a Client's")
+	c.run = func(ifnames []string) int {
+		if len(ifnames) == 0 || *n <= 0 || *concurrency <= 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		get := dcb.GetPFC
+		mode := "a socket per query"
+		if !*perQuery {
+			cl, err := dial(*concurrency)
+			if err != nil {
+				log.Error(err)
+				return exitNetlink
+			}
+			defer cl.Close()
+			get = cl.GetPFC
+			mode = "reused sockets"
+		}
+		fmt.Printf("ieee get: %d queries on %d interfaces, concurrency %d, %s\n", *n*len(ifnames), len(ifnames), *concurrency, mode)
+		r := runBench(get, ifnames, *n, *concurrency)
+		r.print(ifnames)
+		if r.errors == *n*len(ifnames) {
+			return exitCode(r.firstErr)
+		}
+		return exitOK
+	}
+}
+
+// benchResult holds the latencies of a bench run per interface.
+type benchResult struct {
+	latencies [][]time.Duration
+	errors    int
+	firstErr  error
+	elapsed   time.Duration
+}
+
+// runBench queries each of ifnames n times with get, keeping concurrency
+// queries in flight, and times them. The interfaces are interleaved so a
+// slow one does not skew only the end of the run.
+func runBench(get func(string) (*dcb.IEEEPFC, error), ifnames []string, n, concurrency int) *benchResult {
+	r := &benchResult{latencies: make([][]time.Duration, len(ifnames))}
+	for i := range r.latencies {
+		r.latencies[i] = make([]time.Duration, 0, n)
+	}
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				t := time.Now()
+				_, err := get(ifnames[i])
+				d := time.Since(t)
+				mu.Lock()
+				// failed queries made the round trip as well, time them too
+				r.latencies[i] = append(r.latencies[i], d)
+				if err != nil {
+					if r.errors == 0 {
+						r.firstErr = err
+					}
+					r.errors++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for k := 0; k < n; k++ {
+		for i := range ifnames {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	r.elapsed = time.Since(start)
+	return r
+}
+
+func (r *benchResult) print(ifnames []string) {
+	all := slices.Concat(r.latencies...)
+	if r.errors > 0 {
+		log.Warnf("%d of %d queries failed, first: %v", r.errors, len(all), r.firstErr)
+	}
+	fmt.Printf("latency: %s\n", latencySummary(all))
+	fmt.Printf("throughput: %.0f queries/s over %v\n", float64(len(all))/r.elapsed.Seconds(), r.elapsed.Round(time.Millisecond))
+	if len(ifnames) > 1 {
+		for i, ifname := range ifnames {
+			fmt.Printf("  %s: %s\n", ifname, latencySummary(r.latencies[i]))
+		}
+	}
+}
+
+// latencySummary formats the min, median, 99th percentile and max of ds,
+// which it sorts.
+func latencySummary(ds []time.Duration) string {
+	if len(ds) == 0 {
+		return "no queries"
+	}
+	slices.Sort(ds)
+	return fmt.Sprintf("min %v p50 %v p99 %v max %v", ds[0], percentile(ds, 50), percentile(ds, 99), ds[len(ds)-1])
+}
+
+// percentile returns the p-th percentile of the sorted ds by the nearest
+// rank method.
+func percentile(ds []time.Duration, p int) time.Duration {
+	rank := (p*len(ds) + 99) / 100
+	return ds[max(rank-1, 0)]
+}
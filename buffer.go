@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	g := newGroup("buffer", "show or size the port buffers (dcbnl_buffer)")
+
+	show := newSubcommand(g, "show", "<ifname>", "show the priority to buffer mapping and buffer sizes")
+	show.ifaceArgs = true
+	show.run = func(args []string) int {
+		if len(args) != 1 {
+			show.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			buf, err := cl.GetBuffer(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printBuffer(args[0], buf)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(setGroup, "buffer", "<ifname>", "change the priority to buffer mapping or buffer sizes, leaving the rest as it is")
+	set.ifaceArgs = true
+	prioBuffer := set.fs.String("prio-buffer", "", "comma-separated buffer per priority; empty entries are left as is, or give prio:buffer pairs such as 3:1")
+	sizes := set.fs.String("size", "", "comma-separated size per buffer, such as 256KiB or a number of bytes; empty entries are left as is, or give buffer:size pairs such as 1:256KiB")
+	set.run = func(args []string) int {
+		if len(args) != 1 || (*prioBuffer == "" && *sizes == "") {
+			set.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			buf, err := cl.GetBuffer(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			if err := applyBufferFlags(buf, *prioBuffer, *sizes); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if err := audited(cl, cliUser(), "set buffer", args[0], func() error { return cl.SetBuffer(args[0], buf) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+
+	calc := newSubcommand(g, "calc", "[ifname]", "compute a lossless buffer configuration, optionally applying it")
+	calc.ifaceArgs = true
+	speed := calc.fs.String("speed", "", "link speed, e.g. 100G (default: speed of ifname)")
+	cable := calc.fs.String("cable", "", "cable length, e.g. 3m")
+	mtu := calc.fs.Int("mtu", 9000, "MTU of the link in bytes")
+	response := calc.fs.Uint64("response-bits", dcb.DefaultResponseBits, "MAC/PHY response allowance in bit times")
+	prios := calc.fs.String("prio", "", "lossless priorities, e.g. 3,4 (default: PFC-enabled priorities of ifname)")
+	total := calc.fs.String("total", "", "buffer memory of the port, e.g. 2MiB or a number of bytes (default: total size of ifname)")
+	apply := calc.fs.Bool("apply", false, "configure the computed buffers on ifname")
+	calc.run = func(args []string) int {
+		if len(args) > 1 || *cable == "" || (*apply && len(args) == 0) {
+			calc.fs.Usage()
+			return exitUsage
+		}
+		var ifname string
+		if len(args) == 1 {
+			ifname = args[0]
+		}
+
+		var cl *dcb.Client
+		if ifname != "" {
+			var err error
+			if cl, err = dial(1); err != nil {
+				log.Error(err)
+				return exitNetlink
+			}
+			defer cl.Close()
+		}
+
+		if *speed == "" {
+			if ifname == "" || linkSpeed(ifname) == 0 {
+				log.Error("link speed unknown, use -speed")
+				return exitUsage
+			}
+			*speed = fmt.Sprint(linkSpeed(ifname))
+		}
+		dp, err := delayParams(*speed, *cable, *mtu, *response)
+		if err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		p := dcb.BufferPlanParams{DelayParams: dp}
+		if *total != "" {
+			size, err := parseSize(*total)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if size > math.MaxUint32 {
+				log.Errorf("total %s exceeds 32 bits", *total)
+				return exitUsage
+			}
+			p.TotalSize = uint32(size)
+		}
+
+		if *prios != "" {
+			if p.LosslessPrios, err = parsePrios(*prios); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+		} else if cl != nil {
+			pfc, err := cl.GetPFC(ifname)
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			for prio := uint8(0); prio < dcb.IEEE_8021Q_MAX_PRIORITIES; prio++ {
+				if pfc.PFCEn&(1<<prio) != 0 {
+					p.LosslessPrios = append(p.LosslessPrios, prio)
+				}
+			}
+		} else {
+			log.Error("lossless priorities unknown, use -prio")
+			return exitUsage
+		}
+
+		if p.TotalSize == 0 && cl != nil {
+			cur, err := cl.GetBuffer(ifname)
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			p.TotalSize = cur.TotalSize
+		}
+
+		buf, err := dcb.PlanBuffer(p)
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		printBuffer(ifname, buf)
+		if p.TotalSize == 0 {
+			log.Warn("total buffer size unknown, buffer 0 (lossy) left at 0")
+		}
+
+		if *apply {
+			if err := audited(cl, cliUser(), "buffer calc -apply", ifname, func() error { return cl.SetBuffer(ifname, buf) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Infof("ifname: %v, buffers configured", ifname)
+		}
+		return exitOK
+	}
+}
+
+// applyBufferFlags updates buf from the set buffer flags. The sizes may
+// not exceed the total size of the port, when the driver reports it.
+func applyBufferFlags(buf *dcb.Buffer, prioBuffer, sizes string) error {
+	if prioBuffer != "" {
+		err := editList(prioBuffer, len(buf.Prio2Buffer), func(prio int, s string) error {
+			b, err := strconv.ParseUint(s, 10, 8)
+			if err != nil || b >= dcb.DCBX_MAX_BUFFERS {
+				return fmt.Errorf("prio %d: invalid buffer %q, want 0 to %d", prio, s, dcb.DCBX_MAX_BUFFERS-1)
+			}
+			buf.Prio2Buffer[prio] = uint8(b)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("prio-buffer: %w", err)
+		}
+	}
+	if sizes != "" {
+		err := editList(sizes, len(buf.BufferSize), func(i int, s string) error {
+			size, err := parseSize(s)
+			if err != nil {
+				return fmt.Errorf("buffer %d: %w", i, err)
+			}
+			if size > math.MaxUint32 {
+				return fmt.Errorf("buffer %d: size %s exceeds 32 bits", i, s)
+			}
+			buf.BufferSize[i] = uint32(size)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("size: %w", err)
+		}
+	}
+	var total uint64
+	for _, size := range buf.BufferSize {
+		total += uint64(size)
+	}
+	if buf.TotalSize > 0 && total > uint64(buf.TotalSize) {
+		return fmt.Errorf("buffer sizes sum to %s, more than the %s of the port", formatSize(total), formatSize(uint64(buf.TotalSize)))
+	}
+	return nil
+}
+
+func printBuffer(ifname string, buf *dcb.Buffer) {
+	if ifname != "" {
+		fmt.Printf("ifname: %s\n", ifname)
+	}
+	fmt.Printf("prio_buffer: %v\n", buf.Prio2Buffer)
+	for i, size := range buf.BufferSize {
+		fmt.Printf("buffer %d: %s\n", i, formatSize(uint64(size)))
+	}
+	fmt.Printf("total_size: %s\n", formatSize(uint64(buf.TotalSize)))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("caps", "<ifname>", "show which parts of the DCB configuration the driver implements")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			caps, err := cl.Probe(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printCaps(args[0], caps, linkInfo(cl, args[0]))
+			if owner, err := cl.Owner(args[0]); err == nil {
+				fmt.Printf("owner: %s\n", owner)
+			}
+			if len(caps.List()) == 0 {
+				return exitNotCapable
+			}
+			return exitOK
+		})
+	}
+}
+
+func printCaps(ifname string, caps *dcb.Capabilities, li *dcb.LinkInfo) {
+	fmt.Printf("ifname: %s\n", ifname)
+	fmt.Printf("kernel: %v\n", dcb.RunningKernel())
+	if li != nil {
+		printLink(li)
+	}
+	fmt.Printf("ieee: %v\n", onOff(caps.IEEE))
+	for _, obj := range []dcb.Object{
+		dcb.ObjectPFC, dcb.ObjectETS, dcb.ObjectMaxrate, dcb.ObjectApp,
+		dcb.ObjectBuffer, dcb.ObjectTrust, dcb.ObjectPCPApp, dcb.ObjectDCBX, dcb.ObjectCEE,
+	} {
+		state := "unsupported"
+		if caps.Supports(obj) {
+			state = "supported"
+		}
+		fmt.Printf("%s: %s\n", obj, state)
+	}
+	if caps.GCAP {
+		fmt.Printf("cee cap: pg %s pfc %s up2tc %s gsp %s bcn %s pg_tcs %#x pfc_tcs %#x dcbx %s\n",
+			onOff(caps.PG), onOff(caps.PFC), onOff(caps.UP2TC), onOff(caps.GSP), onOff(caps.BCN),
+			caps.PGTCs, caps.PFCTCs, dcbxModes(caps.DCBX))
+	}
+}
+
+// dcbxFlags names the DCB_CAP_DCBX_* flags.
+var dcbxFlags = []struct {
+	bit  uint8
+	name string
+}{
+	{dcb.DCB_CAP_DCBX_HOST, "host"},
+	{dcb.DCB_CAP_DCBX_LLD_MANAGED, "lld-managed"},
+	{dcb.DCB_CAP_DCBX_VER_CEE, "cee"},
+	{dcb.DCB_CAP_DCBX_VER_IEEE, "ieee"},
+	{dcb.DCB_CAP_DCBX_STATIC, "static"},
+}
+
+// dcbxModes names the DCB_CAP_DCBX_* flags set in mode.
+func dcbxModes(mode uint8) string {
+	var names []string
+	for _, f := range dcbxFlags {
+		if mode&f.bit != 0 {
+			names = append(names, f.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("replay-decode", "<file>", "run the decoders on the replies recorded with -nl-capture, for debugging without the hardware")
+	raw := c.fs.Bool("hex", false, "also dump the requests and replies as annotated hex, see -debug-netlink")
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		defer f.Close()
+		exs, readErr := dcb.ReadCapture(f)
+		code := exitOK
+		for i := range exs {
+			if !replayExchange(i, &exs[i], *raw) {
+				code = exitFailure
+			}
+		}
+		if readErr != nil {
+			log.Errorf("%s: %v", args[0], readErr)
+			return exitFailure
+		}
+		return code
+	}
+}
+
+// replayExchange prints the decoded exchange ex and reports whether it
+// decoded cleanly.
+func replayExchange(i int, ex *dcb.Exchange, raw bool) bool {
+	fmt.Printf("#%d %s %v, %d replies\n", i, ex.Time.Format(time.RFC3339Nano), ex, len(ex.Replies))
+	if raw {
+		fmt.Printf("request:\n%s", dcb.DumpMessage(ex.Request))
+		for j, m := range ex.Replies {
+			fmt.Printf("reply %d:\n%s", j, dcb.DumpMessage(m))
+		}
+	}
+	if ex.Err != "" {
+		fmt.Printf("error: %s\n", ex.Err)
+		return true
+	}
+	v, err := dcb.DecodeExchange(ex)
+	switch {
+	case errors.Is(err, dcb.ErrNoDecoder):
+		fmt.Println("not decoded")
+		return true
+	case err != nil:
+		fmt.Printf("decode: %v\n", err)
+		return false
+	case v == nil:
+		fmt.Println("ok")
+		return true
+	}
+	b, err := marshalJSON(v, true)
+	if err != nil {
+		fmt.Printf("encode: %v\n", err)
+		return false
+	}
+	fmt.Printf("%s\n", b)
+	return true
+}
+
+// openCapture starts recording the netlink messages of the Clients dialed
+// hereafter to path. The returned function finishes the file.
+func openCapture(path string) (func(), error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("nl-capture: %w", err)
+	}
+	cp, err := dcb.NewCapture(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("nl-capture: %w", err)
+	}
+	global.capture = cp
+	return func() {
+		if err := cp.Err(); err != nil {
+			log.Errorf("nl-capture: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			log.Errorf("nl-capture: %v", err)
+		}
+	}, nil
+}
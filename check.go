@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	g := newGroup("check", "verify an interface is ready for a workload")
+
+	roce := newSubcommand(g, "roce", "<ifname>", "verify RoCE runs lossless on a priority")
+	roce.ifaceArgs = true
+	prio := roce.fs.Uint("prio", 3, "priority carrying RoCE")
+	roce.run = func(args []string) int {
+		if len(args) != 1 || *prio >= dcb.IEEE_8021Q_MAX_PRIORITIES {
+			roce.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			checks, err := cl.CheckRoCE(args[0], uint8(*prio))
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printChecks(args[0], checks)
+			if dcb.Failed(checks) {
+				return exitFailure
+			}
+			return exitOK
+		})
+	}
+
+	th := newSubcommand(g, "thresholds", "<ifname>", "sample the PFC counters over a window and fail if a -fail-if expression holds, for Nagios-style checks")
+	th.ifaceArgs = true
+	var failIf thresholds
+	th.fs.Var(&failIf, "fail-if", "threshold such as 'pfc_rx_rate[prio3] > 1000/s', repeatable; metrics: pfc_tx, pfc_rx (frames in the window), pfc_tx_rate, pfc_rx_rate")
+	window := th.fs.Duration("window", 10*time.Second, "sampling window")
+	th.run = func(args []string) int {
+		if len(args) != 1 || len(failIf) == 0 || *window <= 0 {
+			th.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			first, err := cl.GetPFC(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			start := time.Now()
+			time.Sleep(*window)
+			last, err := cl.GetPFC(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			secs := time.Since(start).Seconds()
+			var checks []dcb.Check
+			for _, t := range failIf {
+				checks = append(checks, t.eval(first, last, secs))
+			}
+			printChecks(args[0], checks)
+			if dcb.Failed(checks) {
+				return exitFailure
+			}
+			return exitOK
+		})
+	}
+}
+
+func printChecks(ifname string, checks []dcb.Check) {
+	fmt.Printf("ifname: %s\n", ifname)
+	for _, c := range checks {
+		fmt.Printf("%-4s %-14s %s\n", c.Status, c.Name, c.Detail)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("clear", "<ifname>", "remove every APP entry of an interface")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			var apps []dcb.App
+			err := audited(cl, cliUser(), "clear", args[0], func() error {
+				var err error
+				apps, err = cl.ClearApp(args[0])
+				return err
+			})
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Infof("ifname: %v, removed %d app entries", args[0], len(apps))
+			return exitOK
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// A command is a subcommand of the tool with its own flag set.
+type command struct {
+	name string
+	// args is the synopsis of the positional arguments.
+	args string
+	help string
+	// ifaceArgs is set when the positional arguments are interface names,
+	// which lets shell completion offer the interfaces of the host.
+	ifaceArgs bool
+	// choices lists the fixed values of the positional argument, if any.
+	choices []string
+
+	fs  *flag.FlagSet
+	run func(args []string) int
+	// subs holds the commands of a group such as "buffer calc".
+	subs map[string]*command
+}
+
+var commands = map[string]*command{}
+
+// newCommand registers a command. The caller adds its flags to the returned
+// command's flag set and sets run.
+func newCommand(name, args, help string) *command {
+	c := &command{
+		name: name,
+		args: args,
+		help: help,
+		fs:   flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+	c.fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s %s [flags] %s\n\n%s\n", progName(), c.name, c.args, c.help)
+		c.fs.PrintDefaults()
+	}
+	commands[name] = c
+	return c
+}
+
+// newGroup registers a command whose first argument selects one of its
+// subcommands.
+func newGroup(name, help string) *command {
+	g := newCommand(name, "<command> [args]", help)
+	g.subs = map[string]*command{}
+	g.run = func(args []string) int {
+		if len(args) == 0 {
+			g.fs.Usage()
+			return exitUsage
+		}
+		c, ok := g.subs[args[0]]
+		if !ok {
+			log.Errorf("unknown %s command %q", g.name, args[0])
+			g.fs.Usage()
+			return exitUsage
+		}
+		return c.execute(args[1:])
+	}
+	g.fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s %s <command> [flags] [args]\n\n%s\n\ncommands:\n", progName(), g.name, g.help)
+		for _, c := range sortedSubs(g.subs) {
+			fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.help)
+		}
+	}
+	return g
+}
+
+// newSubcommand adds the command name to the group g.
+func newSubcommand(g *command, name, args, help string) *command {
+	c := &command{
+		name: name,
+		args: args,
+		help: help,
+		fs:   flag.NewFlagSet(g.name+" "+name, flag.ContinueOnError),
+	}
+	c.fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s %s %s [flags] %s\n\n%s\n", progName(), g.name, c.name, c.args, c.help)
+		c.fs.PrintDefaults()
+	}
+	g.subs[name] = c
+	g.choices = append(g.choices, name)
+	sort.Strings(g.choices)
+	return c
+}
+
+// sortedCommands returns the registered commands ordered by name.
+func sortedCommands() []*command {
+	return sortedSubs(commands)
+}
+
+func sortedSubs(m map[string]*command) []*command {
+	cmds := make([]*command, 0, len(m))
+	for _, c := range m {
+		cmds = append(cmds, c)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].name < cmds[j].name })
+	return cmds
+}
+
+// execute parses the flags of c from args and runs it. Flags may follow
+// the positional arguments, as in `check roce eth0 -prio 3`.
+func (c *command) execute(args []string) int {
+	if c.subs != nil {
+		// flags belong to the subcommand
+		return c.run(args)
+	}
+	pos, err := parseInterspersed(c.fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	return c.run(pos)
+}
+
+// parseInterspersed parses the flags in args and returns the positional
+// arguments. Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(pos, rest...), nil
+		}
+		if len(rest) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"text/template"
+)
+
+func init() {
+	c := newCommand("completion", "bash|zsh|fish", "print a shell completion script")
+	c.choices = []string{"bash", "zsh", "fish"}
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		tmpl, ok := completionTemplates[args[0]]
+		if !ok {
+			log.Errorf("unsupported shell %q", args[0])
+			return exitUsage
+		}
+		if err := tmpl.Execute(os.Stdout, completionData()); err != nil {
+			log.Errorf("write completion: %v", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+}
+
+type completionFlag struct {
+	Name  string
+	Usage string
+	// Value is set for flags that take an argument.
+	Value bool
+}
+
+type completionCommand struct {
+	Name      string
+	Help      string
+	IfaceArgs bool
+	Choices   []string
+	Flags     []completionFlag
+}
+
+type completionInfo struct {
+	Prog string
+	// Func is Prog made usable as a shell function name.
+	Func        string
+	GlobalFlags []completionFlag
+	Commands    []completionCommand
+}
+
+func completionData() completionInfo {
+	info := completionInfo{
+		Prog:        progName(),
+		Func:        strings.NewReplacer("-", "_", ".", "_").Replace(progName()),
+		GlobalFlags: completionFlags(flag.CommandLine),
+	}
+	for _, c := range sortedCommands() {
+		cc := completionCommand{
+			Name:      c.name,
+			Help:      c.help,
+			IfaceArgs: c.ifaceArgs,
+			Choices:   c.choices,
+			Flags:     completionFlags(c.fs),
+		}
+		// Groups complete their subcommands and the flags of all of them;
+		// the scripts do not track which subcommand was chosen.
+		seen := map[string]bool{}
+		for _, sub := range sortedSubs(c.subs) {
+			for _, f := range completionFlags(sub.fs) {
+				if !seen[f.Name] {
+					seen[f.Name] = true
+					cc.Flags = append(cc.Flags, f)
+				}
+			}
+		}
+		info.Commands = append(info.Commands, cc)
+	}
+	return info
+}
+
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		bf, isBool := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			Name:  f.Name,
+			Usage: f.Usage,
+			Value: !isBool || !bf.IsBoolFlag(),
+		})
+	})
+	return flags
+}
+
+// quote escapes s for use inside single quotes in the generated scripts.
+func quote(s string) string {
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
+
+// zshSpecEscaper escapes the characters _arguments parses in an option
+// spec, such as the brackets of "pfc_rx_rate[prio3] > 1000/s".
+var zshSpecEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`)
+
+// zshSpec escapes s for the description of a zsh _arguments option spec
+// inside single quotes.
+func zshSpec(s string) string {
+	return quote(zshSpecEscaper.Replace(s))
+}
+
+var completionTemplates = map[string]*template.Template{
+	"bash": newCompletionTemplate("bash", bashCompletion),
+	"zsh":  newCompletionTemplate("zsh", zshCompletion),
+	"fish": newCompletionTemplate("fish", fishCompletion),
+}
+
+func newCompletionTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"quote":   quote,
+		"zshSpec": zshSpec,
+		"join":    func(s []string) string { return strings.Join(s, " ") },
+	}).Parse(text))
+}
+
+// Interface names are read from /sys/class/net when completing, so the
+// scripts follow interfaces that come and go.
+
+const bashCompletion = `# bash completion for {{.Prog}}
+_{{.Func}}() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" cmd="" i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "${COMP_WORDS[i]}" in
+		{{- range .GlobalFlags}}{{if .Value}}
+		-{{.Name}} | --{{.Name}}) ((i++)) ;;{{end}}{{end}}
+		-*) ;;
+		*) cmd="${COMP_WORDS[i]}"; break ;;
+		esac
+	done
+
+	local flags="" ifaces=0 choices=""
+	case "$cmd" in
+	"")
+		flags="{{range .GlobalFlags}}-{{.Name}} {{end}}"
+		if [[ $cur != -* ]]; then
+			COMPREPLY=($(compgen -W "{{range .Commands}}{{.Name}} {{end}}" -- "$cur"))
+			COMPREPLY+=($(compgen -W "$(ls /sys/class/net 2>/dev/null)" -- "$cur"))
+			return
+		fi
+		;;
+	{{- range .Commands}}
+	{{.Name}}) flags="{{range .Flags}}-{{.Name}} {{end}}"; ifaces={{if .IfaceArgs}}1{{else}}0{{end}}; choices="{{join .Choices}}" ;;
+	{{- end}}
+	*) flags=""; ifaces=1 ;;
+	esac
+
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	elif ((ifaces)); then
+		COMPREPLY=($(compgen -W "$(ls /sys/class/net 2>/dev/null)" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -W "$choices" -- "$cur"))
+	fi
+}
+complete -F _{{.Func}} {{.Prog}}
+`
+
+const zshCompletion = `#compdef {{.Prog}}
+_{{.Func}}_ifaces() {
+	local -a ifaces
+	ifaces=(${(f)"$(ls /sys/class/net 2>/dev/null)"})
+	_describe 'interface' ifaces
+}
+
+_{{.Func}}() {
+	local -a cmds
+	cmds=({{range .Commands}}
+		'{{.Name}}:{{quote .Help}}'{{end}}
+	)
+
+	local i cmd=""
+	for ((i = 2; i < CURRENT; i++)); do
+		case "$words[i]" in
+		{{- range .GlobalFlags}}{{if .Value}}
+		-{{.Name}}) ((i++)) ;;{{end}}{{end}}
+		-*) ;;
+		*) cmd="$words[i]"; break ;;
+		esac
+	done
+
+	case "$cmd" in
+	"")
+		_arguments{{range .GlobalFlags}} \
+			'-{{.Name}}[{{zshSpec .Usage}}]{{if .Value}}:value:{{end}}'{{end}} \
+			'1: :->first'
+		if [[ $state == first ]]; then
+			_describe 'command' cmds
+			_{{.Func}}_ifaces
+		fi
+		;;
+	{{- range .Commands}}
+	{{.Name}})
+		((CURRENT -= i - 1))
+		words=("${(@)words[i,-1]}")
+		_arguments{{range .Flags}} \
+			'-{{.Name}}[{{zshSpec .Usage}}]{{if .Value}}:value:{{end}}'{{end}}{{if .IfaceArgs}} \
+			'*:interface:_{{$.Func}}_ifaces'{{else if .Choices}} \
+			'1:argument:({{join .Choices}})'{{end}}
+		;;
+	{{- end}}
+	*)
+		_{{.Func}}_ifaces
+		;;
+	esac
+}
+
+compdef _{{.Func}} {{.Prog}}
+`
+
+const fishCompletion = `# fish completion for {{.Prog}}
+function __{{.Func}}_ifaces
+	ls /sys/class/net 2>/dev/null
+end
+
+complete -c {{.Prog}} -f
+{{- range .GlobalFlags}}
+complete -c {{$.Prog}} -n '__fish_use_subcommand' -o '{{.Name}}' -d '{{quote .Usage}}'{{if .Value}} -r{{end}}
+{{- end}}
+complete -c {{.Prog}} -n '__fish_use_subcommand' -a '(__{{.Func}}_ifaces)' -d 'interface'
+{{- range .Commands}}
+complete -c {{$.Prog}} -n '__fish_use_subcommand' -a '{{.Name}}' -d '{{quote .Help}}'
+{{- $cmd := .Name}}
+{{- range .Flags}}
+complete -c {{$.Prog}} -n '__fish_seen_subcommand_from {{$cmd}}' -o '{{.Name}}' -d '{{quote .Usage}}'{{if .Value}} -r{{end}}
+{{- end}}
+{{- if .IfaceArgs}}
+complete -c {{$.Prog}} -n '__fish_seen_subcommand_from {{$cmd}}' -a '(__{{$.Func}}_ifaces)'
+{{- else if .Choices}}
+complete -c {{$.Prog}} -n '__fish_seen_subcommand_from {{$cmd}}' -a '{{join .Choices}}'
+{{- end}}
+{{- end}}
+`
@@ -0,0 +1,29 @@
+package dcb
+
+// The rtnetlink ABI of Linux the package speaks, from
+// include/uapi/linux/rtnetlink.h, if_link.h and if.h. They are declared
+// here rather than taken from x/sys/unix, which only has them on Linux, so
+// the decoders build on every platform.
+const (
+	afUnspec = 0
+
+	netlinkRoute = 0
+
+	rtmNewLink = 16
+	rtmDelLink = 17
+	rtmGetLink = 18
+	rtmGetDCB  = 78
+	rtmSetDCB  = 79
+
+	rtmgrpLink = 0x1
+	rtnlgrpDCB = 23
+
+	iffUp      = 0x1
+	iffLowerUp = 0x10000
+
+	iflaIfname    = 3
+	iflaMTU       = 4
+	iflaOperstate = 16
+
+	sizeofIfInfomsg = 16
+)
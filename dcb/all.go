@@ -0,0 +1,68 @@
+package dcb
+
+import (
+	"errors"
+	"syscall"
+)
+
+// DCBConfig is the whole DCB state of an interface as returned by GetAll.
+// Objects the driver does not report are nil.
+type DCBConfig struct {
+	Ifname   string        `json:"ifname"`
+	Caps     *Capabilities `json:"caps"`
+	DCBX     *uint8        `json:"dcbx,omitempty"`
+	PFC      *IEEEPFC      `json:"pfc,omitempty"`
+	ETS      *IEEEETS      `json:"ets,omitempty"`
+	Maxrate  *IEEEMaxrate  `json:"maxrate,omitempty"`
+	Apps     []App         `json:"apps,omitempty"`
+	Buffer   *Buffer       `json:"buffer,omitempty"`
+	Trust    []Selector    `json:"trust,omitempty"`
+	QCNStats *IEEEQCNStats `json:"qcn_stats,omitempty"`
+	Peer     Peer          `json:"peer"`
+	// CEE is only queried when the driver runs CEE DCBX or does not speak
+	// IEEE at all.
+	CEE *CEE `json:"cee,omitempty"`
+}
+
+// GetAll returns the DCB state of ifname with a single DCB_CMD_IEEE_GET,
+// plus the DCBX mode, DCB_CMD_GCAP and, for CEE drivers, DCB_CMD_CEE_GET,
+// all over one socket. Caps is filled from the same replies Probe uses.
+func (cl *Client) GetAll(ifname string) (*DCBConfig, error) {
+	all := &DCBConfig{Ifname: ifname, Caps: &Capabilities{Objects: map[Object]bool{}}}
+	err := cl.do(func(c *conn) error {
+		cfg, ieeeErr := getIEEE(c, ifname)
+		switch {
+		case ieeeErr == nil:
+			all.Caps.setIEEE(cfg)
+			all.PFC, all.ETS, all.Maxrate = cfg.PFC, cfg.ETS, cfg.Maxrate
+			all.Apps, all.Buffer, all.Trust = cfg.Apps, cfg.Buffer, cfg.Trust
+			all.QCNStats, all.Peer = cfg.QCNStats, cfg.Peer
+		case !errors.Is(ieeeErr, syscall.EOPNOTSUPP):
+			return ieeeErr
+		}
+
+		if mode, err := getDCBX(c, ifname); err == nil {
+			all.Caps.Objects[ObjectDCBX] = true
+			all.DCBX = &mode
+		}
+		if err := getCap(c, ifname, all.Caps); err == nil {
+			all.Caps.GCAP = true
+			all.Caps.Objects[ObjectCEE] = true
+		}
+
+		if ieeeErr == nil && (all.DCBX == nil || *all.DCBX&DCB_CAP_DCBX_VER_CEE == 0) {
+			return nil
+		}
+		cee, err := getCEE(c, ifname)
+		if err != nil && ieeeErr != nil {
+			// neither IEEE nor CEE, report the former
+			return ieeeErr
+		}
+		all.CEE = cee
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,346 @@
+package dcb
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/mdlayher/netlink"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L244
+type App struct { // struct dcb_app
+	Selector Selector
+	Priority uint8
+	Protocol uint16
+}
+
+const appLen = sizeofDcbApp
+
+func parseApp(b []byte) (App, error) {
+	if len(b) < appLen {
+		return App{}, fmt.Errorf("invalid struct dcb_app length %d", len(b))
+	}
+	return App{
+		Selector: Selector(b[0]),
+		Priority: b[1],
+		Protocol: binary.NativeEndian.Uint16(b[2:4]),
+	}, nil
+}
+
+func (a App) marshal() []byte {
+	b := make([]byte, appLen)
+	b[0] = uint8(a.Selector)
+	b[1] = a.Priority
+	binary.NativeEndian.PutUint16(b[2:4], a.Protocol)
+	return b
+}
+
+// Well-known protocols of APP entries.
+const (
+	EtherTypeFCoE = 0x8906
+	EtherTypeFIP  = 0x8914
+	ISCSIPort     = 3260
+)
+
+// dscpNames names the DSCP values of the standard per-hop behaviours,
+// RFC 2474, 2597, 3246 and 5865.
+var dscpNames = map[uint16]string{
+	0: "CS0", 8: "CS1", 16: "CS2", 24: "CS3", 32: "CS4", 40: "CS5", 48: "CS6", 56: "CS7",
+	10: "AF11", 12: "AF12", 14: "AF13",
+	18: "AF21", 20: "AF22", 22: "AF23",
+	26: "AF31", 28: "AF32", 30: "AF33",
+	34: "AF41", 36: "AF42", 38: "AF43",
+	44: "VA", 46: "EF",
+}
+
+// Known names the well-known protocol a matches, such as FCoE or RoCEv2,
+// or the per-hop behaviour of a DSCP entry. It returns "" for other
+// entries.
+func (a App) Known() string {
+	switch a.Selector {
+	case IEEE_8021QAZ_APP_SEL_ETHERTYPE:
+		switch a.Protocol {
+		case EtherTypeFCoE:
+			return "FCoE"
+		case EtherTypeFIP:
+			return "FIP"
+		}
+	case IEEE_8021QAZ_APP_SEL_STREAM, IEEE_8021QAZ_APP_SEL_ANY:
+		if a.Protocol == ISCSIPort {
+			return "iSCSI"
+		}
+		if a.Selector == IEEE_8021QAZ_APP_SEL_ANY && a.Protocol == RoCEv2Port {
+			return "RoCEv2"
+		}
+	case IEEE_8021QAZ_APP_SEL_DGRAM:
+		if a.Protocol == RoCEv2Port {
+			return "RoCEv2"
+		}
+	case IEEE_8021QAZ_APP_SEL_DSCP:
+		return dscpNames[a.Protocol]
+	}
+	return ""
+}
+
+// appAttrType returns the attribute type carrying a within an APP table:
+// the IEEE selectors use DCB_ATTR_IEEE_APP, the others DCB_ATTR_DCB_APP.
+func appAttrType(a App) uint16 {
+	if a.Selector >= IEEE_8021QAZ_APP_SEL_ETHERTYPE && a.Selector <= IEEE_8021QAZ_APP_SEL_DSCP {
+		return DCB_ATTR_IEEE_APP
+	}
+	return DCB_ATTR_DCB_APP
+}
+
+// parseAppTable decodes the entries nested in DCB_ATTR_IEEE_APP_TABLE.
+func parseAppTable(nad *netlink.AttributeDecoder) ([]App, error) {
+	var apps []App
+	for nad.Next() {
+		switch nad.Type() {
+		case DCB_ATTR_IEEE_APP, DCB_ATTR_DCB_APP:
+			a, err := parseApp(nad.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			apps = append(apps, a)
+		}
+	}
+	// the kernel keeps the entries in the order they were added, which
+	// differs between hosts configured alike
+	slices.SortFunc(apps, CompareApps)
+	return apps, nil
+}
+
+// CompareApps orders APP entries by selector, then protocol, then
+// priority, the order in which APP tables are returned.
+func CompareApps(a, b App) int {
+	if c := cmp.Compare(a.Selector, b.Selector); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Protocol, b.Protocol); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Priority, b.Priority)
+}
+
+func encodeAppTable(nae *netlink.AttributeEncoder, apps []App) {
+	nae.Nested(DCB_ATTR_IEEE_APP_TABLE, func(tae *netlink.AttributeEncoder) error {
+		for _, a := range apps {
+			tae.Bytes(appAttrType(a), a.marshal())
+		}
+		return nil
+	})
+}
+
+// parseTrustTable decodes the selectors nested in
+// DCB_ATTR_DCB_APP_TRUST_TABLE, most trusted first.
+func parseTrustTable(nad *netlink.AttributeDecoder) []Selector {
+	sels := []Selector{}
+	for nad.Next() {
+		switch nad.Type() {
+		case DCB_ATTR_IEEE_APP, DCB_ATTR_DCB_APP:
+			sels = append(sels, Selector(nad.Uint8()))
+		}
+	}
+	return sels
+}
+
+// GetApp returns the APP table of ifname, ordered by CompareApps.
+func (cl *Client) GetApp(ifname string) ([]App, error) {
+	var apps []App
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		apps = cfg.Apps
+		return nil
+	})
+	return apps, err
+}
+
+// AddApp adds entries to the APP table of ifname.
+func (cl *Client) AddApp(ifname string, apps ...App) error {
+	if err := checkAppSelectors(apps); err != nil {
+		return fmt.Errorf("ifname: %v, add app: %w", ifname, err)
+	}
+	return cl.do(func(c *conn) error {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			encodeAppTable(nae, apps)
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyApps(c, ifname, apps, false)
+	})
+}
+
+// DelApp removes entries from the APP table of ifname.
+func (cl *Client) DelApp(ifname string, apps ...App) error {
+	return cl.do(func(c *conn) error {
+		return delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			encodeAppTable(nae, apps)
+			return nil
+		})
+	})
+}
+
+// ClearApp removes every APP entry of ifname through DCB_CMD_IEEE_DEL and
+// returns the removed entries. The kernel only implements deletion for the
+// APP table; the other IEEE objects have no default to return to and are
+// reset by setting them.
+func (cl *Client) ClearApp(ifname string) ([]App, error) {
+	var apps []App
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if len(cfg.Apps) == 0 {
+			return nil
+		}
+		if err := delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			encodeAppTable(nae, cfg.Apps)
+			return nil
+		}); err != nil {
+			return err
+		}
+		apps = cfg.Apps
+		return nil
+	})
+	return apps, err
+}
+
+// SetApps makes the APP table of ifname equal to apps, adding the missing
+// entries before deleting the others so classification never falls back in
+// between.
+func (cl *Client) SetApps(ifname string, apps []App) error {
+	if err := checkAppSelectors(apps); err != nil {
+		return fmt.Errorf("ifname: %v, set apps: %w", ifname, err)
+	}
+	return cl.do(func(c *conn) error {
+		if err := setApps(c, ifname, apps); err != nil {
+			return err
+		}
+		return cl.verifyApps(c, ifname, apps, true)
+	})
+}
+
+func setApps(c *conn, ifname string, apps []App) error {
+	cfg, err := getIEEE(c, ifname)
+	if err != nil {
+		return err
+	}
+	add, stale := appChanges(cfg.Apps, apps)
+	if len(add) > 0 {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			encodeAppTable(nae, add)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		if err := delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			encodeAppTable(nae, stale)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appChanges returns the entries of want missing from the table have, and
+// the entries of have not in want.
+func appChanges(have, want []App) (add, stale []App) {
+	inHave := map[App]bool{}
+	for _, a := range have {
+		inHave[a] = true
+	}
+	inWant := map[App]bool{}
+	for _, a := range want {
+		inWant[a] = true
+		if !inHave[a] {
+			add = append(add, a)
+		}
+	}
+	for _, a := range have {
+		if !inWant[a] {
+			stale = append(stale, a)
+		}
+	}
+	return add, stale
+}
+
+// GetTrust returns the APP selectors ifname trusts, most trusted first. It
+// returns ErrNoAttribute if the kernel (before 6.3) or driver has no trust
+// table.
+func (cl *Client) GetTrust(ifname string) ([]Selector, error) {
+	var sels []Selector
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.Trust == nil {
+			if err := featureTrust.check(); err != nil {
+				return fmt.Errorf("ifname: %v, get app trust: %w", ifname, err)
+			}
+			return fmt.Errorf("ifname: %v, get app trust: %w", ifname, ErrNoAttribute)
+		}
+		sels = cfg.Trust
+		return nil
+	})
+	return sels, err
+}
+
+// SetTrust replaces the trust table of ifname with sels, most trusted first.
+// It needs Linux 6.3 and a driver implementing dcbnl_setapptrust.
+func (cl *Client) SetTrust(ifname string, sels []Selector) error {
+	if err := featureTrust.check(); err != nil {
+		return fmt.Errorf("ifname: %v, set app trust: %w", ifname, err)
+	}
+	return cl.do(func(c *conn) error {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Nested(DCB_ATTR_DCB_APP_TRUST_TABLE, func(tae *netlink.AttributeEncoder) error {
+				for _, sel := range sels {
+					tae.Uint8(appAttrType(App{Selector: sel}), uint8(sel))
+				}
+				return nil
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, ObjectTrust, sels, func(cfg *ieeeConfig) any { return cfg.Trust })
+	})
+}
+
+// checkAppSelectors refuses the non-IEEE selectors the running kernel does
+// not know, which it would otherwise drop without an error.
+func checkAppSelectors(apps []App) error {
+	for _, a := range apps {
+		if appAttrType(a) == DCB_ATTR_DCB_APP {
+			return featurePCPApp.check()
+		}
+	}
+	return nil
+}
+
+// delIEEE sends DCB_CMD_IEEE_DEL with the attributes added by encode nested
+// in DCB_ATTR_IEEE.
+func delIEEE(c *conn, ifname string, encode func(nae *netlink.AttributeEncoder) error) error {
+	msgs, err := execute(c, rtmSetDCB, DCB_CMD_IEEE_DEL, ifname, func(ae *netlink.AttributeEncoder) error {
+		ae.Nested(DCB_ATTR_IEEE, encode)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ifname: %v, ieee del: %w", ifname, err)
+	}
+	if err := replyStatus(msgs, DCB_CMD_IEEE_DEL, DCB_ATTR_IEEE); err != nil {
+		return fmt.Errorf("ifname: %v, ieee del: %w", ifname, err)
+	}
+	return nil
+}
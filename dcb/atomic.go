@@ -0,0 +1,76 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A RestoreAllError is returned by RestoreAll when an interface refuses its
+// snapshot. The interfaces changed until then were set back to the state
+// they had before, unless Rollback reports otherwise.
+type RestoreAllError struct {
+	// Ifname is the interface whose restore failed, with Err.
+	Ifname string
+	Err    error
+	// RolledBack lists the interfaces set back, the failing one included.
+	RolledBack []string
+	// Rollback joins the errors of the interfaces that could not be set
+	// back, nil if all were.
+	Rollback error
+}
+
+func (e *RestoreAllError) Error() string {
+	if e.Rollback != nil {
+		return fmt.Sprintf("ifname: %v, restore: %v; rollback failed: %v", e.Ifname, e.Err, e.Rollback)
+	}
+	return fmt.Sprintf("ifname: %v, restore: %v; rolled back %d interfaces", e.Ifname, e.Err, len(e.RolledBack))
+}
+
+func (e *RestoreAllError) Unwrap() error { return e.Err }
+
+// RestoreAll restores snaps[i] onto ifnames[i], all or nothing, for the
+// ports of an adapter sharing buffers or DCBX state, where a config applied
+// to some of them only can be worse than none. The state of every
+// interface is read before any is changed; when one refuses its snapshot,
+// it and the ones restored before it are set back to that state, in
+// reverse order, and a *RestoreAllError is returned. It returns the objects
+// Restore skipped, per interface.
+//
+// Other writers of the interfaces are not locked out, so the rollback
+// undoes their changes made meanwhile too.
+func (cl *Client) RestoreAll(ifnames []string, snaps []*Snapshot) (map[string][]Object, error) {
+	if len(ifnames) != len(snaps) {
+		return nil, fmt.Errorf("restore all: %d interfaces, %d snapshots", len(ifnames), len(snaps))
+	}
+	saved := make([]*Snapshot, len(ifnames))
+	for i, ifname := range ifnames {
+		s, err := cl.Snapshot(ifname)
+		if err != nil {
+			return nil, fmt.Errorf("save state before restore: %w", err)
+		}
+		saved[i] = s
+	}
+
+	skipped := make(map[string][]Object)
+	for i, ifname := range ifnames {
+		sk, err := cl.Restore(ifname, snaps[i])
+		if len(sk) > 0 {
+			skipped[ifname] = sk
+		}
+		if err == nil {
+			continue
+		}
+		re := &RestoreAllError{Ifname: ifname, Err: err}
+		var errs []error
+		for j := i; j >= 0; j-- {
+			if _, err := cl.Restore(ifnames[j], saved[j]); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			re.RolledBack = append(re.RolledBack, ifnames[j])
+		}
+		re.Rollback = errors.Join(errs...)
+		return skipped, re
+	}
+	return skipped, nil
+}
@@ -0,0 +1,109 @@
+package dcb
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mdlayher/netlink"
+)
+
+// ieeeBatch lists the objects one DCB_CMD_IEEE_SET can carry, in the order
+// dcbnl_ieee_set hands them to the driver whatever their order in the
+// message. The trust table is left out: it came later and kernels differ in
+// where they apply it.
+var ieeeBatch = []Object{ObjectETS, ObjectMaxrate, ObjectPFC, ObjectBuffer, ObjectApp}
+
+// batchable returns the objects of order selected by want that can be set
+// in a single message, or nil if fewer than two are selected or order sets
+// them in another order than the kernel applies them in.
+func batchable(order []Object, want func(Object) bool) []Object {
+	var objs []Object
+	for _, obj := range order {
+		if slices.Contains(ieeeBatch, obj) && want(obj) {
+			objs = append(objs, obj)
+		}
+	}
+	byKernel := func(a, b Object) int {
+		return slices.Index(ieeeBatch, a) - slices.Index(ieeeBatch, b)
+	}
+	if len(objs) < 2 || !slices.IsSortedFunc(objs, byKernel) {
+		return nil
+	}
+	return objs
+}
+
+// setBatch sets the objects objs of s, with apps as the APP table, in one
+// DCB_CMD_IEEE_SET. Stale APP entries can only be deleted afterwards, by a
+// DCB_CMD_IEEE_DEL of their own.
+func (cl *Client) setBatch(ifname string, s *Snapshot, apps []App, objs []Object, q driverQuirks) error {
+	if slices.Contains(objs, ObjectApp) {
+		if err := checkAppSelectors(apps); err != nil {
+			return fmt.Errorf("ifname: %v, set apps: %w", ifname, err)
+		}
+	}
+	if slices.Contains(objs, ObjectETS) {
+		if err := cl.clearWilling(ifname, s.ETS, q); err != nil {
+			return err
+		}
+	}
+	return cl.do(func(c *conn) error {
+		var add, stale []App
+		if slices.Contains(objs, ObjectApp) {
+			cfg, err := getIEEE(c, ifname)
+			if err != nil {
+				return err
+			}
+			add, stale = appChanges(cfg.Apps, apps)
+		}
+		err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			for _, obj := range objs {
+				switch obj {
+				case ObjectETS:
+					nae.Bytes(DCB_ATTR_IEEE_ETS, s.ETS.marshal())
+				case ObjectMaxrate:
+					nae.Bytes(DCB_ATTR_IEEE_MAXRATE, s.Maxrate.marshal())
+				case ObjectPFC:
+					nae.Bytes(DCB_ATTR_IEEE_PFC, s.PFC.marshal())
+				case ObjectBuffer:
+					nae.Bytes(DCB_ATTR_DCB_BUFFER, s.Buffer.marshal())
+				case ObjectApp:
+					if len(add) > 0 {
+						encodeAppTable(nae, add)
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(stale) > 0 {
+			if err := delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				encodeAppTable(nae, stale)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, obj := range objs {
+			var err error
+			switch obj {
+			case ObjectETS:
+				err = cl.verifyIEEE(c, ifname, obj, s.ETS, func(cfg *ieeeConfig) any { return cfg.ETS })
+			case ObjectMaxrate:
+				err = cl.verifyIEEE(c, ifname, obj, s.Maxrate, func(cfg *ieeeConfig) any { return cfg.Maxrate })
+			case ObjectPFC:
+				err = cl.verifyIEEE(c, ifname, obj, s.PFC, func(cfg *ieeeConfig) any { return cfg.PFC })
+			case ObjectBuffer:
+				err = cl.verifyIEEE(c, ifname, obj, s.Buffer, func(cfg *ieeeConfig) any { return cfg.Buffer })
+			case ObjectApp:
+				err = cl.verifyApps(c, ifname, apps, true)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
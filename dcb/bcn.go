@@ -0,0 +1,149 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// BCN is the CEE backward congestion notification configuration, nested in
+// DCB_ATTR_BCN.
+type BCN struct {
+	RP    [CEE_DCBX_MAX_PRIO]uint8 // reaction point enable per priority
+	BCNA  [2]uint32
+	Alpha uint32
+	Beta  uint32
+	GD    uint32
+	GI    uint32
+	TMax  uint32
+	TD    uint32
+	RMin  uint32
+	W     uint32
+	RD    uint32
+	RU    uint32
+	WRTT  uint32
+	RI    uint32
+}
+
+// params returns pointers to the u32 parameters, indexed by attribute type
+// minus DCB_BCN_ATTR_BCNA_0. The kernel reports BCNA_0 through RI.
+func (b *BCN) params() []*uint32 {
+	return []*uint32{
+		&b.BCNA[0], &b.BCNA[1], &b.Alpha, &b.Beta, &b.GD, &b.GI, &b.TMax,
+		&b.TD, &b.RMin, &b.W, &b.RD, &b.RU, &b.WRTT, &b.RI,
+	}
+}
+
+// GetBCN returns the CEE BCN configuration of ifname. Few drivers implement
+// it; others fail with EOPNOTSUPP.
+func (cl *Client) GetBCN(ifname string) (*BCN, error) {
+	var bcn *BCN
+	err := cl.do(func(c *conn) error {
+		msgs, err := execute(c, rtmGetDCB, DCB_CMD_BCN_GCFG, ifname, func(ae *netlink.AttributeEncoder) error {
+			ae.Nested(DCB_ATTR_BCN, func(nae *netlink.AttributeEncoder) error {
+				nae.Flag(DCB_BCN_ATTR_ALL, true)
+				return nil
+			})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, get bcn: %w", ifname, err)
+		}
+
+		bcn, err = parseBCNReply(msgs)
+		if err != nil {
+			return fmt.Errorf("ifname: %v, decode bcn: %w", ifname, err)
+		}
+		if bcn == nil {
+			return fmt.Errorf("ifname: %v, get bcn: %w", ifname, ErrNoAttribute)
+		}
+		return nil
+	})
+	return bcn, err
+}
+
+// parseBCNReply decodes the replies to DCB_CMD_BCN_GCFG, returning nil if
+// they carry no DCB_ATTR_BCN.
+func parseBCNReply(msgs []netlink.Message) (*BCN, error) {
+	var bcn *BCN
+	err := replyAttrs(msgs, DCB_CMD_BCN_GCFG, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() != DCB_ATTR_BCN {
+				continue
+			}
+			bcn = &BCN{}
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				params := bcn.params()
+				for nad.Next() {
+					switch t := nad.Type(); {
+					case t >= DCB_BCN_ATTR_RP_0 && t <= DCB_BCN_ATTR_RP_7:
+						bcn.RP[t-DCB_BCN_ATTR_RP_0] = nad.Uint8()
+					case t >= DCB_BCN_ATTR_BCNA_0 && t <= DCB_BCN_ATTR_RI:
+						*params[t-DCB_BCN_ATTR_BCNA_0] = nad.Uint32()
+					}
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bcn, nil
+}
+
+// SetBCN stages the CEE BCN configuration of ifname. Like all CEE
+// settings, it takes effect once committed with CommitCEE.
+func (cl *Client) SetBCN(ifname string, bcn *BCN) error {
+	return cl.do(func(c *conn) error {
+		msgs, err := execute(c, rtmSetDCB, DCB_CMD_BCN_SCFG, ifname, func(ae *netlink.AttributeEncoder) error {
+			ae.Nested(DCB_ATTR_BCN, func(nae *netlink.AttributeEncoder) error {
+				for i, rp := range bcn.RP {
+					nae.Uint8(uint16(DCB_BCN_ATTR_RP_0+i), rp)
+				}
+				for i, p := range bcn.params() {
+					nae.Uint32(uint16(DCB_BCN_ATTR_BCNA_0+i), *p)
+				}
+				return nil
+			})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, set bcn: %w", ifname, err)
+		}
+		if err := replyStatus(msgs, DCB_CMD_BCN_SCFG, DCB_ATTR_BCN); err != nil {
+			return fmt.Errorf("ifname: %v, set bcn: %w", ifname, err)
+		}
+		return nil
+	})
+}
+
+// CommitCEE applies the staged CEE settings of ifname to the hardware
+// (DCB_CMD_SET_ALL) and returns the driver's status, whose meaning is
+// driver specific; ixgbe for instance returns 1 when nothing changed.
+func (cl *Client) CommitCEE(ifname string) (uint8, error) {
+	var status uint8
+	err := cl.do(func(c *conn) error {
+		msgs, err := execute(c, rtmSetDCB, DCB_CMD_SET_ALL, ifname, func(ae *netlink.AttributeEncoder) error {
+			ae.Uint8(DCB_ATTR_SET_ALL, 1)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, set all: %w", ifname, err)
+		}
+		err = replyAttrs(msgs, DCB_CMD_SET_ALL, func(ad *netlink.AttributeDecoder) error {
+			for ad.Next() {
+				if ad.Type() == DCB_ATTR_SET_ALL {
+					status = ad.Uint8()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, set all: %w", ifname, err)
+		}
+		return nil
+	})
+	return status, err
+}
@@ -0,0 +1,124 @@
+package dcb
+
+// The dcbnl structs implement encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler with the layout the kernel uses in attribute
+// payloads, in host byte order, so they can be captured, replayed and
+// built for raw requests. UnmarshalBinary accepts longer input, as newer
+// kernels may extend a struct, and ignores the excess.
+
+func (p *IEEEPFC) MarshalBinary() ([]byte, error) {
+	return p.marshal(), nil
+}
+
+func (p *IEEEPFC) UnmarshalBinary(b []byte) error {
+	v, err := parseIEEEPFC(b)
+	if err != nil {
+		return err
+	}
+	*p = *v
+	return nil
+}
+
+func (e *IEEEETS) MarshalBinary() ([]byte, error) {
+	return e.marshal(), nil
+}
+
+func (e *IEEEETS) UnmarshalBinary(b []byte) error {
+	v, err := parseIEEEETS(b)
+	if err != nil {
+		return err
+	}
+	*e = *v
+	return nil
+}
+
+func (m *IEEEMaxrate) MarshalBinary() ([]byte, error) {
+	return m.marshal(), nil
+}
+
+func (m *IEEEMaxrate) UnmarshalBinary(b []byte) error {
+	v, err := parseIEEEMaxrate(b)
+	if err != nil {
+		return err
+	}
+	*m = *v
+	return nil
+}
+
+func (buf *Buffer) MarshalBinary() ([]byte, error) {
+	return buf.marshal(), nil
+}
+
+func (buf *Buffer) UnmarshalBinary(b []byte) error {
+	v, err := parseBuffer(b)
+	if err != nil {
+		return err
+	}
+	*buf = *v
+	return nil
+}
+
+func (s *IEEEQCNStats) MarshalBinary() ([]byte, error) {
+	return s.marshal(), nil
+}
+
+func (s *IEEEQCNStats) UnmarshalBinary(b []byte) error {
+	v, err := parseIEEEQCNStats(b)
+	if err != nil {
+		return err
+	}
+	*s = *v
+	return nil
+}
+
+func (pg *CEEPG) MarshalBinary() ([]byte, error) {
+	return pg.marshal(), nil
+}
+
+func (pg *CEEPG) UnmarshalBinary(b []byte) error {
+	v, err := parseCEEPG(b)
+	if err != nil {
+		return err
+	}
+	*pg = *v
+	return nil
+}
+
+func (pfc *CEEPFC) MarshalBinary() ([]byte, error) {
+	return pfc.marshal(), nil
+}
+
+func (pfc *CEEPFC) UnmarshalBinary(b []byte) error {
+	v, err := parseCEEPFC(b)
+	if err != nil {
+		return err
+	}
+	*pfc = *v
+	return nil
+}
+
+func (info *PeerAppInfo) MarshalBinary() ([]byte, error) {
+	return info.marshal(), nil
+}
+
+func (info *PeerAppInfo) UnmarshalBinary(b []byte) error {
+	v, err := parsePeerAppInfo(b)
+	if err != nil {
+		return err
+	}
+	*info = *v
+	return nil
+}
+
+func (a App) MarshalBinary() ([]byte, error) {
+	return a.marshal(), nil
+}
+
+func (a *App) UnmarshalBinary(b []byte) error {
+	v, err := parseApp(b)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
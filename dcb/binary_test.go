@@ -0,0 +1,156 @@
+package dcb
+
+import (
+	"encoding"
+	"reflect"
+	"testing"
+)
+
+// binaryCase is a struct value and a zero one of the same type to decode
+// it into.
+type binaryCase struct {
+	name string
+	in   encoding.BinaryMarshaler
+	out  encoding.BinaryUnmarshaler
+	len  int
+}
+
+func binaryCases() []binaryCase {
+	return []binaryCase{
+		{
+			name: "ieee_pfc",
+			in: &IEEEPFC{
+				PFCCap:      8,
+				PFCEn:       0x18,
+				MBC:         1,
+				Delay:       0xbeef,
+				Requests:    [IEEE_8021QAZ_MAX_TCS]uint64{1, 2, 3, 4, 5, 6, 7, 1 << 40},
+				Indications: [IEEE_8021QAZ_MAX_TCS]uint64{8, 7, 6, 5, 4, 3, 2, 1 << 63},
+			},
+			out: &IEEEPFC{},
+			len: ieeePFCLen,
+		},
+		{
+			name: "ieee_ets",
+			in: &IEEEETS{
+				Willing:    1,
+				ETSCap:     8,
+				CBS:        1,
+				TCTxBW:     [IEEE_8021QAZ_MAX_TCS]uint8{10, 20, 30, 40},
+				TCRxBW:     [IEEE_8021QAZ_MAX_TCS]uint8{40, 30, 20, 10},
+				TCTSA:      [IEEE_8021QAZ_MAX_TCS]TSA{2, 2, 2, 2, 0, 0, 0, 1},
+				PrioTC:     [IEEE_8021QAZ_MAX_TCS]uint8{0, 1, 2, 3, 0, 1, 2, 7},
+				TCRecoBW:   [IEEE_8021QAZ_MAX_TCS]uint8{25, 25, 25, 25},
+				TCRecoTSA:  [IEEE_8021QAZ_MAX_TCS]TSA{2, 2, 2, 2, 1, 1, 1, 1},
+				RecoPrioTC: [IEEE_8021QAZ_MAX_TCS]uint8{7, 6, 5, 4, 3, 2, 1, 0},
+			},
+			out: &IEEEETS{},
+			len: ieeeETSLen,
+		},
+		{
+			name: "ieee_maxrate",
+			in:   &IEEEMaxrate{TCMaxrate: [IEEE_8021QAZ_MAX_TCS]uint64{0, 1000, 1 << 33, 0, 0, 0, 0, ^uint64(0)}},
+			out:  &IEEEMaxrate{},
+			len:  ieeeMaxrateLen,
+		},
+		{
+			name: "dcbnl_buffer",
+			in: &Buffer{
+				Prio2Buffer: [IEEE_8021Q_MAX_PRIORITIES]uint8{0, 0, 0, 1, 1, 2, 2, 7},
+				BufferSize:  [DCBX_MAX_BUFFERS]uint32{65536, 32768, 1, 0, 0, 0, 0, 0xffffffff},
+				TotalSize:   1 << 20,
+			},
+			out: &Buffer{},
+			len: bufferLen,
+		},
+		{
+			name: "ieee_qcn_stats",
+			in: &IEEEQCNStats{
+				RPPPRPCentiseconds: [IEEE_8021QAZ_MAX_TCS]uint64{1, 0, 1 << 40, 0, 0, 0, 0, ^uint64(0)},
+				RPPPCreatedRPs:     [IEEE_8021QAZ_MAX_TCS]uint32{7, 0, 0, 1 << 20, 0, 0, 0, 0xffffffff},
+			},
+			out: &IEEEQCNStats{},
+			len: ieeeQCNStatsLen,
+		},
+		{
+			name: "cee_pg",
+			in: &CEEPG{
+				Willing:      1,
+				Error:        1,
+				PGEn:         1,
+				TCsSupported: 8,
+				PGBW:         [CEE_DCBX_MAX_PGS]uint8{50, 25, 25},
+				PrioPG:       [CEE_DCBX_MAX_PGS]uint8{0, 1, 2, 2, 0, 0, 1, 7},
+			},
+			out: &CEEPG{},
+			len: ceePGLen,
+		},
+		{
+			name: "cee_pfc",
+			in:   &CEEPFC{Willing: 1, Error: 1, PFCEn: 0x18, TCsSupported: 4},
+			out:  &CEEPFC{},
+			len:  ceePFCLen,
+		},
+		{
+			name: "dcb_peer_app_info",
+			in:   &PeerAppInfo{Willing: 1, Error: 1},
+			out:  &PeerAppInfo{},
+			len:  sizeofDcbPeerAppInfo,
+		},
+		{
+			name: "dcb_app",
+			in:   App{Selector: 4, Priority: 3, Protocol: 4791},
+			out:  &App{},
+			len:  appLen,
+		},
+	}
+}
+
+// deref returns the struct v points to, or v itself if it is not a
+// pointer.
+func deref(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		return rv.Elem().Interface()
+	}
+	return v
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, tc := range binaryCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := tc.in.MarshalBinary()
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if len(b) != tc.len {
+				t.Fatalf("marshal: got %d bytes, want %d", len(b), tc.len)
+			}
+			// newer kernels may extend a struct, the excess is ignored
+			for _, in := range [][]byte{b, append(b, 0xff, 0xff, 0xff, 0xff)} {
+				if err := tc.out.UnmarshalBinary(in); err != nil {
+					t.Fatalf("unmarshal %d bytes: %v", len(in), err)
+				}
+				if got, want := deref(tc.out), deref(tc.in); !reflect.DeepEqual(got, want) {
+					t.Errorf("unmarshal %d bytes:\n got %+v\nwant %+v", len(in), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBinaryUnmarshalShort(t *testing.T) {
+	for _, tc := range binaryCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := tc.in.MarshalBinary()
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			for _, n := range []int{0, 1, tc.len - 1} {
+				if err := tc.out.UnmarshalBinary(b[:n]); err == nil {
+					t.Errorf("unmarshal %d bytes: got no error", n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L168
+type Buffer struct { // struct dcbnl_buffer
+	Prio2Buffer [IEEE_8021Q_MAX_PRIORITIES]uint8 // priority to buffer mapping
+	BufferSize  [DCBX_MAX_BUFFERS]uint32         // buffer size in bytes
+	TotalSize   uint32                           `diff:"ro"`
+}
+
+const bufferLen = sizeofDcbnlBuffer
+
+func parseBuffer(b []byte) (*Buffer, error) {
+	if len(b) < bufferLen {
+		return nil, fmt.Errorf("invalid struct dcbnl_buffer length %d", len(b))
+	}
+
+	buf := &Buffer{}
+	copy(buf.Prio2Buffer[:], b[:IEEE_8021Q_MAX_PRIORITIES])
+	off := IEEE_8021Q_MAX_PRIORITIES
+	for i := 0; i < DCBX_MAX_BUFFERS; i++ {
+		buf.BufferSize[i] = binary.NativeEndian.Uint32(b[off : off+4])
+		off += 4
+	}
+	buf.TotalSize = binary.NativeEndian.Uint32(b[off : off+4])
+
+	return buf, nil
+}
+
+func (buf *Buffer) marshal() []byte {
+	b := make([]byte, bufferLen)
+	copy(b, buf.Prio2Buffer[:])
+	off := IEEE_8021Q_MAX_PRIORITIES
+	for i := 0; i < DCBX_MAX_BUFFERS; i++ {
+		binary.NativeEndian.PutUint32(b[off:off+4], buf.BufferSize[i])
+		off += 4
+	}
+	binary.NativeEndian.PutUint32(b[off:off+4], buf.TotalSize)
+	return b
+}
+
+// Validate checks that every priority of buf maps to a valid buffer and,
+// if the driver reported the total size of the port, that the buffer sizes
+// do not exceed it.
+func (buf *Buffer) Validate() error {
+	for prio, b := range buf.Prio2Buffer {
+		if b >= DCBX_MAX_BUFFERS {
+			return fmt.Errorf("prio %d mapped to invalid buffer %d", prio, b)
+		}
+	}
+	var total uint64
+	for _, size := range buf.BufferSize {
+		total += uint64(size)
+	}
+	if buf.TotalSize > 0 && total > uint64(buf.TotalSize) {
+		return fmt.Errorf("buffer sizes add up to %d bytes, total_size allows %d", total, buf.TotalSize)
+	}
+	return nil
+}
+
+// GetBuffer returns the priority to buffer mapping and buffer sizes of
+// ifname.
+func (cl *Client) GetBuffer(ifname string) (*Buffer, error) {
+	var buf *Buffer
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.Buffer == nil {
+			return fmt.Errorf("ifname: %v, get dcbnl buffer: %w", ifname, ErrNoAttribute)
+		}
+		buf = cfg.Buffer
+		return nil
+	})
+	return buf, err
+}
+
+// SetBuffer configures the priority to buffer mapping and buffer sizes of
+// ifname. TotalSize is read-only and ignored by drivers.
+func (cl *Client) SetBuffer(ifname string, buf *Buffer) error {
+	return cl.do(func(c *conn) error {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_DCB_BUFFER, buf.marshal())
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, ObjectBuffer, buf, func(cfg *ieeeConfig) any { return cfg.Buffer })
+	})
+}
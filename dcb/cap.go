@@ -0,0 +1,162 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"syscall"
+
+	"github.com/mdlayher/netlink"
+)
+
+// An Object is a part of the DCB configuration a driver may or may not
+// implement.
+type Object string
+
+const (
+	ObjectPFC     Object = "pfc"
+	ObjectETS     Object = "ets"
+	ObjectMaxrate Object = "maxrate"
+	ObjectApp     Object = "app"
+	ObjectBuffer  Object = "buffer"
+	ObjectTrust   Object = "trust"
+	ObjectPCPApp  Object = "pcp-app" // APP entries with DCB_APP_SEL_PCP
+	ObjectDCBX    Object = "dcbx"
+	ObjectCEE     Object = "cee"
+)
+
+// Capabilities describes what the driver of an interface implements.
+type Capabilities struct {
+	// Objects holds the objects the driver reports. The APP table counts
+	// as reported whenever IEEE_GET succeeds since drivers always expose
+	// the kernel-managed table.
+	Objects map[Object]bool
+
+	// IEEE is set when the driver answers DCB_CMD_IEEE_GET; drivers
+	// without ieee_get* ops only speak CEE.
+	IEEE bool
+
+	// The fields below come from DCB_CMD_GCAP, which only drivers
+	// implementing the CEE getcap op answer. GCAP is set when it did.
+	GCAP   bool
+	PG     bool
+	PFC    bool
+	UP2TC  bool
+	PGTCs  uint8 // bit n-1 set when n traffic classes can be used for PG
+	PFCTCs uint8 // bit n-1 set when n traffic classes can be used for PFC
+	GSP    bool
+	BCN    bool
+	DCBX   uint8 // DCB_CAP_DCBX_* modes the device supports
+}
+
+// Supports reports whether the driver implements o.
+func (c *Capabilities) Supports(o Object) bool {
+	return c.Objects[o]
+}
+
+// List returns the supported objects in name order.
+func (c *Capabilities) List() []Object {
+	var objs []Object
+	for o, ok := range c.Objects {
+		if ok {
+			objs = append(objs, o)
+		}
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i] < objs[j] })
+	return objs
+}
+
+// Probe queries which parts of the DCB configuration the driver of ifname
+// implements, with DCB_CMD_IEEE_GET, DCB_CMD_GDCBX and DCB_CMD_GCAP. Missing
+// ops are recorded, not returned as errors; Probe only fails when the
+// interface does not exist or the socket does.
+func (cl *Client) Probe(ifname string) (*Capabilities, error) {
+	caps := &Capabilities{Objects: map[Object]bool{}}
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		switch {
+		case err == nil:
+			caps.setIEEE(cfg)
+		case !errors.Is(err, syscall.EOPNOTSUPP):
+			return err
+		}
+
+		if _, err := getDCBX(c, ifname); err == nil {
+			caps.Objects[ObjectDCBX] = true
+		}
+
+		if err := getCap(c, ifname, caps); err == nil {
+			caps.GCAP = true
+			caps.Objects[ObjectCEE] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return caps, nil
+}
+
+// setIEEE records the objects reported in the DCB_CMD_IEEE_GET reply cfg.
+func (caps *Capabilities) setIEEE(cfg *ieeeConfig) {
+	caps.IEEE = true
+	caps.Objects[ObjectApp] = true
+	caps.Objects[ObjectPFC] = cfg.PFC != nil
+	caps.Objects[ObjectETS] = cfg.ETS != nil
+	caps.Objects[ObjectMaxrate] = cfg.Maxrate != nil
+	caps.Objects[ObjectBuffer] = cfg.Buffer != nil
+	caps.Objects[ObjectTrust] = cfg.Trust != nil
+	caps.Objects[ObjectPCPApp] = featurePCPApp.check() == nil
+}
+
+func getCap(c *conn, ifname string, caps *Capabilities) error {
+	msgs, err := execute(c, rtmGetDCB, DCB_CMD_GCAP, ifname, func(ae *netlink.AttributeEncoder) error {
+		ae.Nested(DCB_ATTR_CAP, func(nae *netlink.AttributeEncoder) error {
+			nae.Flag(DCB_CAP_ATTR_ALL, true)
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ifname: %v, get cap: %w", ifname, err)
+	}
+	if err := parseCapReply(msgs, caps); err != nil {
+		return fmt.Errorf("ifname: %v, decode cap: %w", ifname, err)
+	}
+	return nil
+}
+
+// parseCapReply decodes the replies to DCB_CMD_GCAP into caps.
+func parseCapReply(msgs []netlink.Message, caps *Capabilities) error {
+	return replyAttrs(msgs, DCB_CMD_GCAP, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() != DCB_ATTR_CAP {
+				continue
+			}
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					switch nad.Type() {
+					case DCB_CAP_ATTR_PG:
+						caps.PG = nad.Uint8() != 0
+					case DCB_CAP_ATTR_PFC:
+						caps.PFC = nad.Uint8() != 0
+					case DCB_CAP_ATTR_UP2TC:
+						caps.UP2TC = nad.Uint8() != 0
+					case DCB_CAP_ATTR_PG_TCS:
+						caps.PGTCs = nad.Uint8()
+					case DCB_CAP_ATTR_PFC_TCS:
+						caps.PFCTCs = nad.Uint8()
+					case DCB_CAP_ATTR_GSP:
+						caps.GSP = nad.Uint8() != 0
+					case DCB_CAP_ATTR_BCN:
+						caps.BCN = nad.Uint8() != 0
+					case DCB_CAP_ATTR_DCBX:
+						caps.DCBX = nad.Uint8()
+					}
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
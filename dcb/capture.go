@@ -0,0 +1,274 @@
+package dcb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A Capture records the netlink messages a Client exchanges with the
+// kernel, so a decode bug can be reproduced from the file with ReadCapture
+// and DecodeExchange without the hardware.
+//
+// A capture file starts with the 8 bytes "DCBCAP" and a little-endian u16
+// version, 1. Each record then has a 28-byte header, all little-endian:
+//
+//	u8  kind: 1 sent, 2 received, 3 error
+//	u8  padding[3]
+//	i64 time, nanoseconds since the Unix epoch
+//	u16 nlmsg_type, u16 nlmsg_flags, u32 nlmsg_seq, u32 nlmsg_pid
+//	u32 length of the data that follows
+//
+// followed by the payload of the netlink message, without the nlmsghdr,
+// or for errors the error text. The sent message of an exchange is
+// followed by its replies or its error.
+type Capture struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// captureMagic starts capture files, followed by its version.
+const (
+	captureMagic   = "DCBCAP"
+	captureVersion = 1
+)
+
+// Kinds of capture records.
+const (
+	captureSent     = 1
+	captureReceived = 2
+	captureError    = 3
+)
+
+const captureRecordLen = 28
+
+// captureMaxData bounds the data of a record read back, well above the
+// largest netlink message of dcbnl, so a corrupt length field does not
+// make ReadCapture allocate gigabytes.
+const captureMaxData = 64 << 10
+
+// NewCapture writes the file header to w and returns a Capture recording to
+// it. Captures are safe for concurrent use by the sockets of a Client.
+func NewCapture(w io.Writer) (*Capture, error) {
+	hdr := binary.LittleEndian.AppendUint16([]byte(captureMagic), captureVersion)
+	if _, err := w.Write(hdr); err != nil {
+		return nil, fmt.Errorf("write capture header: %w", err)
+	}
+	return &Capture{w: w}, nil
+}
+
+// Err returns the first error writing the capture. Recording stops at it;
+// the queries themselves are not affected.
+func (cp *Capture) Err() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.err
+}
+
+func (cp *Capture) record(req netlink.Message, replies []netlink.Message, err error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cp.err != nil {
+		return
+	}
+	now := time.Now()
+	b := appendCaptureRecord(nil, captureSent, now, req.Header, req.Data)
+	for _, m := range replies {
+		b = appendCaptureRecord(b, captureReceived, now, m.Header, m.Data)
+	}
+	if err != nil {
+		b = appendCaptureRecord(b, captureError, now, netlink.Header{}, []byte(err.Error()))
+	}
+	if _, err := cp.w.Write(b); err != nil {
+		cp.err = fmt.Errorf("write capture: %w", err)
+	}
+}
+
+func appendCaptureRecord(b []byte, kind uint8, t time.Time, h netlink.Header, data []byte) []byte {
+	b = append(b, kind, 0, 0, 0)
+	b = binary.LittleEndian.AppendUint64(b, uint64(t.UnixNano()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(h.Type))
+	b = binary.LittleEndian.AppendUint16(b, uint16(h.Flags))
+	b = binary.LittleEndian.AppendUint32(b, h.Sequence)
+	b = binary.LittleEndian.AppendUint32(b, h.PID)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+// An Exchange is a request read from a capture file with its replies and
+// the error returned for it, if any.
+type Exchange struct {
+	Time    time.Time
+	Request netlink.Message
+	Replies []netlink.Message
+	Err     string
+}
+
+// ReadCapture reads the exchanges of the capture file r. A file truncated
+// within a record, as when the capturing process was killed, returns the
+// exchanges before it along with an error wrapping io.ErrUnexpectedEOF.
+func ReadCapture(r io.Reader) ([]Exchange, error) {
+	br := bufio.NewReader(r)
+	hdr := make([]byte, len(captureMagic)+2)
+	if _, err := io.ReadFull(br, hdr); err != nil || string(hdr[:len(captureMagic)]) != captureMagic {
+		return nil, errors.New("not a capture file")
+	}
+	if v := binary.LittleEndian.Uint16(hdr[len(captureMagic):]); v != captureVersion {
+		return nil, fmt.Errorf("capture version %d is not supported", v)
+	}
+
+	var exs []Exchange
+	rec := make([]byte, captureRecordLen)
+	for n := 0; ; n++ {
+		if _, err := io.ReadFull(br, rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return exs, nil
+			}
+			return exs, fmt.Errorf("read capture after %d exchanges: %w", len(exs), err)
+		}
+		size := binary.LittleEndian.Uint32(rec[24:28])
+		if size > captureMaxData {
+			return exs, fmt.Errorf("capture record %d: data length %d exceeds %d", n, size, captureMaxData)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return exs, fmt.Errorf("read capture after %d exchanges: %w", len(exs), io.ErrUnexpectedEOF)
+		}
+		m := netlink.Message{
+			Header: netlink.Header{
+				Length:   uint32(nlmsgHdrLen + len(data)),
+				Type:     netlink.HeaderType(binary.LittleEndian.Uint16(rec[12:14])),
+				Flags:    netlink.HeaderFlags(binary.LittleEndian.Uint16(rec[14:16])),
+				Sequence: binary.LittleEndian.Uint32(rec[16:20]),
+				PID:      binary.LittleEndian.Uint32(rec[20:24]),
+			},
+			Data: data,
+		}
+		kind := rec[0]
+		if kind == captureSent {
+			exs = append(exs, Exchange{Time: time.Unix(0, int64(binary.LittleEndian.Uint64(rec[4:12]))), Request: m})
+			continue
+		}
+		if len(exs) == 0 {
+			return nil, errors.New("capture: reply without a request")
+		}
+		ex := &exs[len(exs)-1]
+		switch kind {
+		case captureReceived:
+			ex.Replies = append(ex.Replies, m)
+		case captureError:
+			ex.Err = string(data)
+		default:
+			return exs, fmt.Errorf("capture after %d exchanges: unknown record kind %d", len(exs), kind)
+		}
+	}
+}
+
+// Cmd returns the dcbnl command of the request, 0 if it is not a dcbnl
+// message.
+func (ex *Exchange) Cmd() Command {
+	t := ex.Request.Header.Type
+	if (t != rtmGetDCB && t != rtmSetDCB) || len(ex.Request.Data) < dcbMsgLen {
+		return 0
+	}
+	return Command(ex.Request.Data[1])
+}
+
+// Ifname returns the interface the request is about, "" if unknown.
+func (ex *Exchange) Ifname() string {
+	if ex.Cmd() == 0 {
+		return ""
+	}
+	ifname, _ := dcbmsgIfname(ex.Request.Data)
+	return ifname
+}
+
+// String describes the request, such as "DCB_CMD_IEEE_GET eth0" or
+// "RTM_GETLINK".
+func (ex *Exchange) String() string {
+	if cmd := ex.Cmd(); cmd != 0 {
+		return fmt.Sprintf("%v %s", cmd, ex.Ifname())
+	}
+	return headerTypeName(ex.Request.Header.Type)
+}
+
+// ErrNoDecoder is returned by DecodeExchange for requests it has no decoder
+// for.
+var ErrNoDecoder = errors.New("no decoder for the request")
+
+// DecodeExchange runs the decoder the Client uses on the replies of ex and
+// returns what it decoded: a *DCBConfig with the IEEE objects for
+// DCB_CMD_IEEE_GET, a *Capabilities, *BCN or *CEE for DCB_CMD_GCAP,
+// DCB_CMD_BCN_GCFG and DCB_CMD_CEE_GET, the mode for DCB_CMD_GDCBX, the
+// driver status for DCB_CMD_SDCBX and DCB_CMD_SET_ALL, and nil for the
+// other set commands when their status reports success.
+func DecodeExchange(ex *Exchange) (any, error) {
+	if ex.Err != "" {
+		return nil, fmt.Errorf("request failed: %s", ex.Err)
+	}
+	switch cmd := uint8(ex.Cmd()); cmd {
+	case DCB_CMD_IEEE_GET:
+		cfg, err := parseIEEEReply(ex.Replies)
+		if err != nil {
+			return nil, err
+		}
+		return &DCBConfig{
+			Ifname: ex.Ifname(), PFC: cfg.PFC, ETS: cfg.ETS, Maxrate: cfg.Maxrate,
+			Apps: cfg.Apps, Buffer: cfg.Buffer, Trust: cfg.Trust, QCNStats: cfg.QCNStats, Peer: cfg.Peer,
+		}, nil
+	case DCB_CMD_GCAP:
+		caps := &Capabilities{Objects: map[Object]bool{}}
+		if err := parseCapReply(ex.Replies, caps); err != nil {
+			return nil, err
+		}
+		return caps, nil
+	case DCB_CMD_BCN_GCFG:
+		return parseBCNReply(ex.Replies)
+	case DCB_CMD_CEE_GET:
+		return parseCEEReply(ex.Replies)
+	case DCB_CMD_GDCBX:
+		mode, found, err := parseDCBXReply(ex.Replies)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, ErrNoAttribute
+		}
+		return mode, nil
+	case DCB_CMD_IEEE_SET, DCB_CMD_IEEE_DEL:
+		return nil, replyStatus(ex.Replies, cmd, DCB_ATTR_IEEE)
+	case DCB_CMD_BCN_SCFG:
+		return nil, replyStatus(ex.Replies, cmd, DCB_ATTR_BCN)
+	case DCB_CMD_SDCBX:
+		return driverStatus(ex.Replies, cmd, DCB_ATTR_DCBX)
+	case DCB_CMD_SET_ALL:
+		return driverStatus(ex.Replies, cmd, DCB_ATTR_SET_ALL)
+	}
+	return nil, ErrNoDecoder
+}
+
+// driverStatus returns the u8 attribute typ of the replies to cmd, which
+// setdcbx and setall report as a driver specific status rather than an
+// errno.
+func driverStatus(msgs []netlink.Message, cmd uint8, typ uint16) (any, error) {
+	var status uint8
+	err := replyAttrs(msgs, cmd, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() == typ {
+				status = ad.Uint8()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -0,0 +1,51 @@
+package dcb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+func TestReadCaptureRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	cp, err := NewCapture(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := netlink.Message{Header: netlink.Header{Type: rtmGetDCB, Flags: netlink.Request, Sequence: 7, PID: 42}, Data: []byte{1, 2, 3, 4}}
+	reply := netlink.Message{Header: netlink.Header{Type: rtmGetDCB, Sequence: 7, PID: 42}, Data: []byte{5, 6, 7, 8}}
+	cp.record(req, []netlink.Message{reply}, nil)
+
+	exs, err := ReadCapture(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exs) != 1 || len(exs[0].Replies) != 1 {
+		t.Fatalf("got %+v, want an exchange with a reply", exs)
+	}
+	if got := exs[0].Request; got.Header.Sequence != 7 || !bytes.Equal(got.Data, req.Data) {
+		t.Errorf("request: got %+v, want %+v", got, req)
+	}
+	if got := exs[0].Replies[0]; got.Header.Type != rtmGetDCB || !bytes.Equal(got.Data, reply.Data) {
+		t.Errorf("reply: got %+v, want %+v", got, reply)
+	}
+}
+
+func TestReadCaptureOversizedRecord(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewCapture(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := appendCaptureRecord(nil, captureSent, time.Now(), netlink.Header{Type: rtmGetDCB}, nil)
+	binary.LittleEndian.PutUint32(b[24:28], 0xffffffff)
+	buf.Write(b)
+
+	_, err := ReadCapture(&buf)
+	if err == nil || !strings.Contains(err.Error(), "record 0") {
+		t.Fatalf("got %v, want an error naming record 0", err)
+	}
+}
@@ -0,0 +1,206 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L190
+type CEEPG struct { // struct cee_pg
+	Willing      uint8
+	Error        uint8
+	PGEn         uint8
+	TCsSupported uint8
+	PGBW         [CEE_DCBX_MAX_PGS]uint8 // bandwidth percentage of each priority group
+	PrioPG       [CEE_DCBX_MAX_PGS]uint8 // priority group of each priority
+}
+
+const ceePGLen = sizeofCeePg
+
+func parseCEEPG(b []byte) (*CEEPG, error) {
+	if len(b) < ceePGLen {
+		return nil, fmt.Errorf("invalid struct cee_pg length %d", len(b))
+	}
+	pg := &CEEPG{
+		Willing:      b[offCeePgWilling],
+		Error:        b[offCeePgError],
+		PGEn:         b[offCeePgPgEn],
+		TCsSupported: b[offCeePgTcsSupported],
+	}
+	copy(pg.PGBW[:], b[offCeePgPgBw:])
+	copy(pg.PrioPG[:], b[offCeePgPrioPg:])
+	return pg, nil
+}
+
+func (pg *CEEPG) marshal() []byte {
+	b := make([]byte, ceePGLen)
+	b[offCeePgWilling], b[offCeePgError], b[offCeePgPgEn], b[offCeePgTcsSupported] = pg.Willing, pg.Error, pg.PGEn, pg.TCsSupported
+	copy(b[offCeePgPgBw:], pg.PGBW[:])
+	copy(b[offCeePgPrioPg:], pg.PrioPG[:])
+	return b
+}
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L207
+type CEEPFC struct { // struct cee_pfc
+	Willing      uint8
+	Error        uint8
+	PFCEn        uint8
+	TCsSupported uint8
+}
+
+const ceePFCLen = sizeofCeePfc
+
+func parseCEEPFC(b []byte) (*CEEPFC, error) {
+	if len(b) < ceePFCLen {
+		return nil, fmt.Errorf("invalid struct cee_pfc length %d", len(b))
+	}
+	return &CEEPFC{
+		Willing:      b[offCeePfcWilling],
+		Error:        b[offCeePfcError],
+		PFCEn:        b[offCeePfcPfcEn],
+		TCsSupported: b[offCeePfcTcsSupported],
+	}, nil
+}
+
+func (pfc *CEEPFC) marshal() []byte {
+	b := make([]byte, ceePFCLen)
+	b[offCeePfcWilling], b[offCeePfcError], b[offCeePfcPfcEn], b[offCeePfcTcsSupported] = pfc.Willing, pfc.Error, pfc.PFCEn, pfc.TCsSupported
+	return b
+}
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L259
+type PeerAppInfo struct { // struct dcb_peer_app_info
+	Willing uint8
+	Error   uint8
+}
+
+func parsePeerAppInfo(b []byte) (*PeerAppInfo, error) {
+	if len(b) < sizeofDcbPeerAppInfo {
+		return nil, fmt.Errorf("invalid struct dcb_peer_app_info length %d", len(b))
+	}
+	return &PeerAppInfo{Willing: b[offDcbPeerAppInfoWilling], Error: b[offDcbPeerAppInfoError]}, nil
+}
+
+func (info *PeerAppInfo) marshal() []byte {
+	b := make([]byte, sizeofDcbPeerAppInfo)
+	b[offDcbPeerAppInfoWilling], b[offDcbPeerAppInfoError] = info.Willing, info.Error
+	return b
+}
+
+// CEEPeer is the config the link partner advertised in its last CEE DCBX
+// exchange, the CEE counterpart of Peer. Objects not advertised are nil.
+type CEEPeer struct {
+	PG  *CEEPG  `json:"pg,omitempty"`
+	PFC *CEEPFC `json:"pfc,omitempty"`
+	// AppInfo holds the willing and error bits of the APP tlv of Apps.
+	AppInfo *PeerAppInfo `json:"app_info,omitempty"`
+	Apps    []App        `json:"apps,omitempty"`
+}
+
+// CEE is the CEE DCBX state of an interface: the local PFC setting, to
+// compare with what the peer advertised.
+type CEE struct {
+	// PFCEn is the local PFC enable bitmap, nil when the driver does not
+	// report it.
+	PFCEn *uint8  `json:"pfc_en,omitempty"`
+	Peer  CEEPeer `json:"peer"`
+}
+
+// GetCEE returns the CEE DCBX state of ifname. Drivers which only speak
+// IEEE DCBX fail it as not capable.
+func (cl *Client) GetCEE(ifname string) (*CEE, error) {
+	var cee *CEE
+	err := cl.do(func(c *conn) error {
+		var err error
+		cee, err = getCEE(c, ifname)
+		return err
+	})
+	return cee, err
+}
+
+func getCEE(c *conn, ifname string) (*CEE, error) {
+	msgs, err := execute(c, rtmGetDCB, DCB_CMD_CEE_GET, ifname, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ifname: %v, cee get: %w", ifname, err)
+	}
+	cee, err := parseCEEReply(msgs)
+	if err != nil {
+		return nil, fmt.Errorf("ifname: %v, decode cee attributes: %w", ifname, err)
+	}
+	return cee, nil
+}
+
+// parseCEEReply decodes the replies to DCB_CMD_CEE_GET.
+func parseCEEReply(msgs []netlink.Message) (*CEE, error) {
+	cee := &CEE{}
+	err := replyAttrs(msgs, DCB_CMD_CEE_GET, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() == DCB_ATTR_CEE {
+				ad.Nested(cee.decode)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cee, nil
+}
+
+func (cee *CEE) decode(nad *netlink.AttributeDecoder) error {
+	for nad.Next() {
+		switch nad.Type() {
+		case DCB_ATTR_CEE_PEER_PG:
+			pg, err := parseCEEPG(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse cee peer pg: %w", err)
+			}
+			cee.Peer.PG = pg
+		case DCB_ATTR_CEE_PEER_PFC:
+			pfc, err := parseCEEPFC(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse cee peer pfc: %w", err)
+			}
+			cee.Peer.PFC = pfc
+		case DCB_ATTR_CEE_PEER_APP_TABLE:
+			nad.Nested(cee.Peer.decodeApps)
+		case DCB_ATTR_CEE_PFC:
+			// a u8 enable per priority in DCB_PFC_UP_ATTR_0..7
+			var en uint8
+			nad.Nested(func(pad *netlink.AttributeDecoder) error {
+				for pad.Next() {
+					if t := pad.Type(); t >= DCB_PFC_UP_ATTR_0 && t <= DCB_PFC_UP_ATTR_7 && pad.Uint8() != 0 {
+						en |= 1 << (t - DCB_PFC_UP_ATTR_0)
+					}
+				}
+				return nil
+			})
+			cee.PFCEn = &en
+		}
+	}
+	return nil
+}
+
+// decodeApps decodes DCB_ATTR_CEE_PEER_APP_TABLE. Unlike the IEEE tables
+// its entry types collide with DCB_ATTR_IEEE_APP, so parseAppTable does
+// not apply.
+func (p *CEEPeer) decodeApps(tad *netlink.AttributeDecoder) error {
+	for tad.Next() {
+		switch tad.Type() {
+		case DCB_ATTR_CEE_PEER_APP_INFO:
+			info, err := parsePeerAppInfo(tad.Bytes())
+			if err != nil {
+				return err
+			}
+			p.AppInfo = info
+		case DCB_ATTR_CEE_PEER_APP:
+			a, err := parseApp(tad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse cee peer app: %w", err)
+			}
+			p.Apps = append(p.Apps, a)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// The CEE APP interface, DCB_CMD_GAPP and DCB_CMD_SAPP, addresses one
+// protocol at a time by an id type, an ethertype or a TCP/UDP port number,
+// and maps it to a bitmap of priorities rather than to a single one. Some
+// drivers offer it only. GetCEEApp and SetCEEApp translate to App entries:
+// the ethertype id type is the ethertype selector, the port number id
+// type the any selector, and each priority of the bitmap an entry.
+
+// ceeIDType returns the CEE id type of the APP selector sel.
+func ceeIDType(sel Selector) (uint8, error) {
+	switch sel {
+	case IEEE_8021QAZ_APP_SEL_ETHERTYPE:
+		return DCB_APP_IDTYPE_ETHTYPE, nil
+	case IEEE_8021QAZ_APP_SEL_STREAM, IEEE_8021QAZ_APP_SEL_DGRAM, IEEE_8021QAZ_APP_SEL_ANY:
+		// CEE does not tell TCP from UDP
+		return DCB_APP_IDTYPE_PORTNUM, nil
+	}
+	return 0, fmt.Errorf("selector %v has no cee id type, want ethertype, stream, dgram or any", sel)
+}
+
+// GetCEEApp returns the entries of the CEE APP priority map of ifname for
+// the protocol of the selector sel, one per priority, in ascending order.
+// The selector of the entries is ethertype or any.
+func (cl *Client) GetCEEApp(ifname string, sel Selector, protocol uint16) ([]App, error) {
+	idtype, err := ceeIDType(sel)
+	if err != nil {
+		return nil, fmt.Errorf("ifname: %v, get cee app: %w", ifname, err)
+	}
+	var apps []App
+	err = cl.do(func(c *conn) error {
+		msgs, err := execute(c, rtmGetDCB, DCB_CMD_GAPP, ifname, func(ae *netlink.AttributeEncoder) error {
+			encodeCEEApp(ae, idtype, protocol, nil)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, get cee app: %w", ifname, err)
+		}
+		up, found, err := parseCEEAppReply(msgs)
+		if err != nil {
+			return fmt.Errorf("ifname: %v, decode cee app: %w", ifname, err)
+		}
+		if !found {
+			return fmt.Errorf("ifname: %v, get cee app: %w", ifname, ErrNoAttribute)
+		}
+		apps = ceeApps(idtype, protocol, up)
+		return nil
+	})
+	return apps, err
+}
+
+// SetCEEApp maps the protocol of the selector sel to the priorities prios
+// in the CEE APP priority map of ifname, replacing the priorities it had.
+// No priorities removes the protocol from the map. Unlike the other CEE
+// settings, it takes effect without CommitCEE.
+func (cl *Client) SetCEEApp(ifname string, sel Selector, protocol uint16, prios ...uint8) error {
+	idtype, err := ceeIDType(sel)
+	if err != nil {
+		return fmt.Errorf("ifname: %v, set cee app: %w", ifname, err)
+	}
+	var up uint8
+	for _, prio := range prios {
+		if prio >= CEE_DCBX_MAX_PRIO {
+			return fmt.Errorf("ifname: %v, set cee app: invalid priority %d", ifname, prio)
+		}
+		up |= 1 << prio
+	}
+	return cl.do(func(c *conn) error {
+		msgs, err := execute(c, rtmSetDCB, DCB_CMD_SAPP, ifname, func(ae *netlink.AttributeEncoder) error {
+			encodeCEEApp(ae, idtype, protocol, &up)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, set cee app: %w", ifname, err)
+		}
+		if err := replyStatus(msgs, DCB_CMD_SAPP, DCB_ATTR_APP); err != nil {
+			return fmt.Errorf("ifname: %v, set cee app: %w", ifname, err)
+		}
+		if !cl.verify {
+			return nil
+		}
+		msgs, err = execute(c, rtmGetDCB, DCB_CMD_GAPP, ifname, func(ae *netlink.AttributeEncoder) error {
+			encodeCEEApp(ae, idtype, protocol, nil)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", ObjectApp, err)
+		}
+		have, _, err := parseCEEAppReply(msgs)
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", ObjectApp, err)
+		}
+		if have != up {
+			return &VerifyError{Ifname: ifname, Object: ObjectApp, Changes: []Change{{Path: "cee_app", Old: ceeApps(idtype, protocol, up), New: ceeApps(idtype, protocol, have)}}}
+		}
+		return nil
+	})
+}
+
+// encodeCEEApp adds DCB_ATTR_APP for the protocol id of idtype, with the
+// priority bitmap up if not nil.
+func encodeCEEApp(ae *netlink.AttributeEncoder, idtype uint8, id uint16, up *uint8) {
+	ae.Nested(DCB_ATTR_APP, func(nae *netlink.AttributeEncoder) error {
+		nae.Uint8(DCB_APP_ATTR_IDTYPE, idtype)
+		nae.Uint16(DCB_APP_ATTR_ID, id)
+		if up != nil {
+			nae.Uint8(DCB_APP_ATTR_PRIORITY, *up)
+		}
+		return nil
+	})
+}
+
+// parseCEEAppReply decodes the replies to DCB_CMD_GAPP. found is false if
+// they carry no priority.
+func parseCEEAppReply(msgs []netlink.Message) (up uint8, found bool, err error) {
+	err = replyAttrs(msgs, DCB_CMD_GAPP, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() != DCB_ATTR_APP {
+				continue
+			}
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					if nad.Type() == DCB_APP_ATTR_PRIORITY {
+						up, found = nad.Uint8(), true
+					}
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return up, found, err
+}
+
+// ceeApps returns the App entries of the priority bitmap up of a protocol.
+func ceeApps(idtype uint8, id uint16, up uint8) []App {
+	sel := Selector(IEEE_8021QAZ_APP_SEL_ETHERTYPE)
+	if idtype == DCB_APP_IDTYPE_PORTNUM {
+		sel = IEEE_8021QAZ_APP_SEL_ANY
+	}
+	apps := []App{}
+	for prio := uint8(0); prio < CEE_DCBX_MAX_PRIO; prio++ {
+		if up&(1<<prio) != 0 {
+			apps = append(apps, App{Selector: sel, Priority: prio, Protocol: id})
+		}
+	}
+	return apps
+}
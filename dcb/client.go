@@ -0,0 +1,247 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+
+	"github.com/mdlayher/netlink"
+)
+
+// Config contains options for a Client. The zero value, a single socket
+// without strict checking or verification, is valid; New takes the same
+// settings as Options.
+type Config struct {
+	// PoolSize is the number of netlink sockets the Client keeps open and
+	// thereby the number of queries that may be in flight at once. If set to
+	// 0, a single socket is used.
+	PoolSize int
+
+	// StrictCheck enables NETLINK_GET_STRICT_CHK on the sockets so the kernel
+	// rejects malformed requests instead of silently accepting them. Kernels
+	// before 4.20 lack the option; on those it is skipped.
+	StrictCheck bool
+
+	// Verify makes set operations read the object back and return a
+	// *VerifyError if the driver reports other values than requested, as
+	// drivers clamping to their limits or firmware owning the config do
+	// while still acknowledging the set.
+	Verify bool
+
+	// ReadBuffer and WriteBuffer, if positive, set the kernel buffer sizes
+	// of the sockets in bytes. Replies that overflow the read buffer are
+	// dropped by the kernel, which large dumps across many interfaces can
+	// hit with the default size. The sizes are forced beyond the
+	// net.core.rmem_max and wmem_max limits where permitted, with
+	// CAP_NET_ADMIN, and capped to them otherwise.
+	ReadBuffer  int
+	WriteBuffer int
+
+	// PID, if set, binds the sockets to the netlink port IDs PID, PID+1 and
+	// so on, one per socket, instead of kernel-assigned ones.
+	PID uint32
+
+	// NetNS, if set, is a file descriptor of the network namespace the
+	// sockets operate in, instead of that of the calling thread.
+	NetNS int
+
+	// Capture, if set, records the messages of all sockets of the Client.
+	Capture *Capture
+
+	// Trace, if set, is called with every message a socket of the Client
+	// sends, sent set, and receives, such as for logging them with
+	// DumpMessage. It is called from the goroutine running the query.
+	Trace func(m netlink.Message, sent bool)
+}
+
+// A Client is a long-lived dcbnl client. Unlike the package-level functions,
+// which dial a socket per query, a Client dials its sockets once and reuses
+// them across calls, which saves latency and fd churn when polling
+// continuously.
+//
+// A Client is safe for concurrent use by multiple goroutines. Each call
+// borrows a socket of the pool for its whole exchange, so the requests of a
+// call and their replies, matched by sequence number, never interleave with
+// those of another; calls beyond the pool size wait for a socket to become
+// idle. Close may be called while calls are in flight: it waits for them,
+// and calls made after it fail with ErrClosed.
+type Client struct {
+	conns chan *conn
+	// open is the number of sockets dialled into conns.
+	open   int
+	closed chan struct{}
+	// closeOnce guards closing the sockets, with closeErr the result.
+	closeOnce sync.Once
+	closeErr  error
+	verify    bool
+}
+
+// ErrClosed is returned by the calls of a Client made after Close.
+var ErrClosed = errors.New("client closed")
+
+// An Option sets a field of the Config of a Client created by New.
+type Option func(*Config)
+
+// WithPoolSize sets Config.PoolSize.
+func WithPoolSize(n int) Option {
+	return func(c *Config) { c.PoolSize = n }
+}
+
+// WithStrictCheck sets Config.StrictCheck.
+func WithStrictCheck(on bool) Option {
+	return func(c *Config) { c.StrictCheck = on }
+}
+
+// WithVerify sets Config.Verify.
+func WithVerify(on bool) Option {
+	return func(c *Config) { c.Verify = on }
+}
+
+// WithBuffers sets Config.ReadBuffer and Config.WriteBuffer.
+func WithBuffers(read, write int) Option {
+	return func(c *Config) { c.ReadBuffer, c.WriteBuffer = read, write }
+}
+
+// WithPID sets Config.PID.
+func WithPID(pid uint32) Option {
+	return func(c *Config) { c.PID = pid }
+}
+
+// WithNetNS sets Config.NetNS.
+func WithNetNS(fd int) Option {
+	return func(c *Config) { c.NetNS = fd }
+}
+
+// WithCapture sets Config.Capture.
+func WithCapture(cp *Capture) Option {
+	return func(c *Config) { c.Capture = cp }
+}
+
+// WithTrace sets Config.Trace.
+func WithTrace(fn func(m netlink.Message, sent bool)) Option {
+	return func(c *Config) { c.Trace = fn }
+}
+
+// New opens the sockets of a new Client configured by opts, starting from
+// the zero Config.
+func New(opts ...Option) (*Client, error) {
+	config := &Config{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return Dial(config)
+}
+
+// Dial opens the sockets of a new Client. A nil config uses the defaults.
+func Dial(config *Config) (*Client, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	size := config.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	cl := &Client{conns: make(chan *conn, size), closed: make(chan struct{}), verify: config.Verify}
+	for i := 0; i < size; i++ {
+		nlConfig := &netlink.Config{NetNS: config.NetNS}
+		if config.PID != 0 {
+			nlConfig.PID = config.PID + uint32(i)
+		}
+		nc, err := dialNetlink(netlinkRoute, nlConfig)
+		if err != nil {
+			cl.Close()
+			return nil, fmt.Errorf("netlink dial: %w", err)
+		}
+		if err := setBuffers(nc, config.ReadBuffer, config.WriteBuffer); err != nil {
+			nc.Close()
+			cl.Close()
+			return nil, fmt.Errorf("set socket buffers: %w", err)
+		}
+		// Best effort: kernels before 4.12 lack extended acknowledgements
+		// and errors then simply come without a message.
+		c := &conn{Conn: nc, capture: config.Capture, trace: config.Trace}
+		_ = c.SetOption(netlink.ExtendedAcknowledge, true)
+		if config.StrictCheck {
+			err := c.SetOption(netlink.GetStrictCheck, true)
+			if err != nil && !errors.Is(err, syscall.ENOPROTOOPT) {
+				c.Close()
+				cl.Close()
+				return nil, fmt.Errorf("enable strict check: %w", err)
+			}
+		}
+		cl.conns <- c
+		cl.open++
+	}
+	return cl, nil
+}
+
+// Close closes all sockets of the Client, once the calls in flight have
+// returned theirs. Later calls of Close return the same error.
+func (cl *Client) Close() error {
+	cl.closeOnce.Do(func() {
+		close(cl.closed)
+		var errs []error
+		for i := 0; i < cl.open; i++ {
+			errs = append(errs, (<-cl.conns).Close())
+		}
+		cl.closeErr = errors.Join(errs...)
+	})
+	return cl.closeErr
+}
+
+// do borrows an idle socket for the duration of fn, or fails with
+// ErrClosed once the Client is closed.
+func (cl *Client) do(fn func(c *conn) error) error {
+	select {
+	case <-cl.closed:
+		return ErrClosed
+	default:
+	}
+	select {
+	case <-cl.closed:
+		return ErrClosed
+	case c := <-cl.conns:
+		defer func() { cl.conns <- c }()
+		return fn(c)
+	}
+}
+
+// GetPFC returns the IEEE 802.1Qaz PFC managed object of ifname.
+func (cl *Client) GetPFC(ifname string) (*IEEEPFC, error) {
+	var pfc *IEEEPFC
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.PFC == nil {
+			return fmt.Errorf("ifname: %v, get ieee pfc: %w", ifname, ErrNoAttribute)
+		}
+		pfc = cfg.PFC
+		return nil
+	})
+	return pfc, err
+}
+
+// SetPFC configures the IEEE 802.1Qaz PFC managed object of ifname. PFCCap
+// and the counters are read-only and ignored by drivers.
+func (cl *Client) SetPFC(ifname string, pfc *IEEEPFC) error {
+	return cl.do(func(c *conn) error {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_PFC, pfc.marshal())
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, ObjectPFC, pfc, func(cfg *ieeeConfig) any { return cfg.PFC })
+	})
+}
+
+// A conn is a socket of a Client with the observers of its messages.
+type conn struct {
+	*netlink.Conn
+	capture *Capture
+	trace   func(m netlink.Message, sent bool)
+}
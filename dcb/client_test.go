@@ -0,0 +1,114 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+// newFakeClient returns a Client with a pool of size sockets faked by
+// nltest, which answer every request with a DCB_CMD_IEEE_GET reply carrying
+// pfc, and its acknowledgement. Like the kernel, they take a moment to
+// answer: callers handing sockets straight to each other would otherwise
+// keep a single-CPU scheduler from running anything else.
+func newFakeClient(t *testing.T, size int, pfc *IEEEPFC) *Client {
+	t.Helper()
+	data := replyData(t, DCB_CMD_IEEE_GET, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(DCB_ATTR_IEEE, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_PFC, pfc.marshal())
+			return nil
+		})
+	})
+	fake := func(reqs []netlink.Message) ([]netlink.Message, error) {
+		if len(reqs) == 0 {
+			return nil, nil
+		}
+		time.Sleep(10 * time.Microsecond)
+		hdr := netlink.Header{Sequence: reqs[0].Header.Sequence, PID: nltest.PID}
+		reply, ack := hdr, hdr
+		reply.Type, ack.Type = rtmGetDCB, netlink.Error
+		return []netlink.Message{{Header: reply, Data: data}, {Header: ack, Data: make([]byte, 4)}}, nil
+	}
+
+	cl := &Client{conns: make(chan *conn, size), closed: make(chan struct{})}
+	for i := 0; i < size; i++ {
+		cl.conns <- &conn{Conn: nltest.Dial(fake)}
+		cl.open++
+	}
+	return cl
+}
+
+func TestClientConcurrentClose(t *testing.T) {
+	want := &IEEEPFC{PFCCap: 8, PFCEn: 0x08}
+	cl := newFakeClient(t, 4, want)
+
+	const callers = 32
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	served := make(chan struct{}, 1)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				pfc, err := cl.GetPFC("eth0")
+				if err == nil && *pfc != *want {
+					err = fmt.Errorf("got %+v, want %+v", pfc, want)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+				select {
+				case served <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+	// close under the callers once the pool serves them; not every one
+	// needs to have been through, the scheduler owes no fairness to them
+	select {
+	case <-served:
+	case <-time.After(10 * time.Second):
+		cl.Close()
+		t.Fatal("no call served within 10s")
+	}
+	closeErr := make(chan error, 1)
+	go func() { closeErr <- cl.Close() }()
+	if err := cl.Close(); err != nil {
+		t.Errorf("close: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("calls still running 10s after Close")
+	}
+	close(errs)
+	for err := range errs {
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("call during Close: got %v, want ErrClosed", err)
+		}
+	}
+	if err := <-closeErr; err != nil {
+		t.Errorf("concurrent close: %v", err)
+	}
+
+	if _, err := cl.GetPFC("eth0"); !errors.Is(err, ErrClosed) {
+		t.Errorf("call after Close: got %v, want ErrClosed", err)
+	}
+	if err := cl.Close(); err != nil {
+		t.Errorf("close again: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+// Package dcb queries the Data Center Bridging (DCB) configuration of network
+// interfaces through the rtnetlink dcbnl interface.
+//
+// Queries are methods of a Client, created with New and Options or with Dial
+// and a Config. The package keeps no state shared between Clients and does
+// not log; what happened is reported in the returned values and errors,
+// which wrap typed errors such as *RequestError and *VerifyError for
+// errors.As. The exported API is kept compatible within a major version.
+//
+// dcbnl is a Linux interface. The package builds on other platforms too, so
+// cross-platform programs can import it, but there its queries return
+// ErrUnsupportedPlatform.
+package dcb
+
+import (
+	"fmt"
+	"slices"
+	"syscall"
+
+	"github.com/mdlayher/netlink"
+)
+
+// GetPFC dials a rtnetlink socket and returns the IEEE 802.1Qaz PFC managed
+// object of ifname. Callers querying repeatedly should use a Client instead.
+func GetPFC(ifname string) (*IEEEPFC, error) {
+	cl, err := Dial(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cl.Close()
+
+	return cl.GetPFC(ifname)
+}
+
+// ieeeConfig holds the attributes of a DCB_CMD_IEEE_GET reply. Attributes
+// the driver does not report are nil.
+type ieeeConfig struct {
+	ETS     *IEEEETS
+	PFC     *IEEEPFC
+	Maxrate *IEEEMaxrate
+	Apps    []App
+	Buffer  *Buffer
+	Trust   []Selector
+	Peer    Peer
+
+	QCNStats *IEEEQCNStats
+}
+
+func getIEEE(c *conn, ifname string) (*ieeeConfig, error) {
+	msgs, err := execute(c, rtmGetDCB, DCB_CMD_IEEE_GET, ifname, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ifname: %v, ieee get: %w", ifname, err)
+	}
+
+	cfg, err := parseIEEEReply(msgs)
+	if err != nil {
+		return nil, fmt.Errorf("ifname: %v, decode ieee attributes: %w", ifname, err)
+	}
+	return cfg, nil
+}
+
+// parseIEEEReply decodes the replies to DCB_CMD_IEEE_GET.
+func parseIEEEReply(msgs []netlink.Message) (*ieeeConfig, error) {
+	cfg := &ieeeConfig{}
+	err := replyAttrs(msgs, DCB_CMD_IEEE_GET, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() == DCB_ATTR_IEEE {
+				ad.Nested(cfg.decode)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *ieeeConfig) decode(nad *netlink.AttributeDecoder) error {
+	for nad.Next() {
+		switch nad.Type() {
+		case DCB_ATTR_IEEE_ETS:
+			e, err := parseIEEEETS(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse ieee ets: %w", err)
+			}
+			cfg.ETS = e
+		case DCB_ATTR_IEEE_PFC:
+			p, err := parseIEEEPFC(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse ieee pfc: %w", err)
+			}
+			cfg.PFC = p
+		case DCB_ATTR_IEEE_PEER_ETS:
+			e, err := parseIEEEETS(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse ieee peer ets: %w", err)
+			}
+			cfg.Peer.ETS = e
+		case DCB_ATTR_IEEE_PEER_PFC:
+			p, err := parseIEEEPFC(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse ieee peer pfc: %w", err)
+			}
+			cfg.Peer.PFC = p
+		case DCB_ATTR_IEEE_PEER_APP:
+			// the entries follow a struct dcb_peer_app_info in
+			// DCB_ATTR_IEEE_APP_UNSPEC, which parseAppTable skips
+			nad.Nested(func(tad *netlink.AttributeDecoder) error {
+				apps, err := parseAppTable(tad)
+				if err != nil {
+					return fmt.Errorf("parse ieee peer app table: %w", err)
+				}
+				cfg.Peer.Apps = mergeApps(cfg.Peer.Apps, apps)
+				return nil
+			})
+		case DCB_ATTR_IEEE_MAXRATE:
+			m, err := parseIEEEMaxrate(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse ieee maxrate: %w", err)
+			}
+			cfg.Maxrate = m
+		case DCB_ATTR_IEEE_QCN_STATS:
+			q, err := parseIEEEQCNStats(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse ieee qcn stats: %w", err)
+			}
+			cfg.QCNStats = q
+		case DCB_ATTR_IEEE_APP_TABLE:
+			nad.Nested(func(tad *netlink.AttributeDecoder) error {
+				apps, err := parseAppTable(tad)
+				if err != nil {
+					return fmt.Errorf("parse ieee app table: %w", err)
+				}
+				cfg.Apps = mergeApps(cfg.Apps, apps)
+				return nil
+			})
+		case DCB_ATTR_DCB_BUFFER:
+			b, err := parseBuffer(nad.Bytes())
+			if err != nil {
+				return fmt.Errorf("parse dcbnl buffer: %w", err)
+			}
+			cfg.Buffer = b
+		case DCB_ATTR_DCB_APP_TRUST_TABLE:
+			nad.Nested(func(tad *netlink.AttributeDecoder) error {
+				sels := parseTrustTable(tad)
+				if cfg.Trust != nil {
+					// a trust table split over several messages
+					// continues in order
+					sels = append(cfg.Trust, sels...)
+				}
+				cfg.Trust = sels
+				return nil
+			})
+		}
+	}
+	return nil
+}
+
+// mergeApps returns the entries of the APP tables a and b, as drivers
+// splitting DCB_ATTR_IEEE over several replies send them, ordered by
+// CompareApps and without duplicates.
+func mergeApps(a, b []App) []App {
+	if a == nil {
+		return b
+	}
+	apps := append(a, b...)
+	slices.SortFunc(apps, CompareApps)
+	return slices.Compact(apps)
+}
+
+// setIEEE sends DCB_CMD_IEEE_SET with the attributes added by encode nested
+// in DCB_ATTR_IEEE.
+func setIEEE(c *conn, ifname string, encode func(nae *netlink.AttributeEncoder) error) error {
+	msgs, err := execute(c, rtmSetDCB, DCB_CMD_IEEE_SET, ifname, func(ae *netlink.AttributeEncoder) error {
+		ae.Nested(DCB_ATTR_IEEE, encode)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ifname: %v, ieee set: %w", ifname, err)
+	}
+	if err := replyStatus(msgs, DCB_CMD_IEEE_SET, DCB_ATTR_IEEE); err != nil {
+		return fmt.Errorf("ifname: %v, ieee set: %w", ifname, err)
+	}
+	return nil
+}
+
+// replyStatus returns the driver error of the dcbnl set command cmd. The
+// kernel acknowledges set commands successfully and reports the driver's
+// return value, truncated to a u8, in the attribute typ of the reply.
+func replyStatus(msgs []netlink.Message, cmd uint8, typ uint16) error {
+	return replyAttrs(msgs, cmd, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if b := ad.Bytes(); ad.Type() == typ && len(b) == 1 && b[0] != 0 {
+				// -errno as u8
+				return syscall.Errno(256 - int(b[0]))
+			}
+		}
+		return nil
+	})
+}
+
+// replyAttrs calls fn with the top-level attributes of each reply to the
+// dcbnl command cmd, in order, so objects a driver splits over several
+// messages are decoded into one. Messages that are not a dcbnl reply to
+// cmd, shorter than a dcbmsg, of another family or command, are skipped;
+// if no message is one, the replies are rejected rather than decoded.
+// Malformed attributes are reported as errors, so decoders only need to
+// check the lengths of the values they read.
+func replyAttrs(msgs []netlink.Message, cmd uint8, fn func(ad *netlink.AttributeDecoder) error) error {
+	var matched bool
+	var unrelated error
+	for _, m := range msgs {
+		var hdr dcbMsg
+		switch err := hdr.UnmarshalBinary(m.Data); {
+		case m.Header.Type != rtmGetDCB && m.Header.Type != rtmSetDCB:
+			unrelated = fmt.Errorf("got %v message, want a dcbnl reply", headerTypeName(m.Header.Type))
+			continue
+		case err != nil:
+			unrelated = err
+			continue
+		case hdr.family != afUnspec:
+			unrelated = fmt.Errorf("got dcbmsg family %d, want %d", hdr.family, afUnspec)
+			continue
+		case hdr.cmd != cmd:
+			unrelated = fmt.Errorf("got %v, want %v", Command(hdr.cmd), Command(cmd))
+			continue
+		}
+		matched = true
+		if len(m.Data) == dcbMsgLen {
+			// no attributes, nothing to decode
+			continue
+		}
+		ad, err := netlink.NewAttributeDecoder(m.Data[dcbMsgLen:])
+		if err != nil {
+			return fmt.Errorf("decode top-level attributes: %w", err)
+		}
+		if err := fn(ad); err != nil {
+			return err
+		}
+		if err := ad.Err(); err != nil {
+			return fmt.Errorf("decode reply: %w", err)
+		}
+	}
+	if !matched && unrelated != nil {
+		// none of the messages answered cmd
+		return fmt.Errorf("decode reply: %w", unrelated)
+	}
+	return nil
+}
+
+// execute sends the dcbnl command cmd for ifname, with any further
+// attributes added by encode, and returns the replies.
+func execute(c *conn, typ netlink.HeaderType, cmd uint8, ifname string, encode func(ae *netlink.AttributeEncoder) error) ([]netlink.Message, error) {
+	dcbmsg := &dcbMsg{
+		family: afUnspec,
+		cmd:    cmd,
+	}
+	dcbmsgb, err := dcbmsg.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("marshal dcbmsg: %w", err)
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	ae.String(DCB_ATTR_IFNAME, ifname)
+	if encode != nil {
+		if err := encode(ae); err != nil {
+			return nil, fmt.Errorf("encode attributes: %w", err)
+		}
+	}
+	attrs, err := ae.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("encode attributes: %w", err)
+	}
+
+	req := netlink.Message{
+		Header: netlink.Header{
+			Type:  typ,
+			Flags: netlink.Request | netlink.Acknowledge,
+		},
+		Data: append(dcbmsgb, attrs...),
+	}
+
+	msgs, err := c.roundTrip(req)
+	if err != nil {
+		return nil, newRequestError(cmd, req, err)
+	}
+	return msgs, nil
+}
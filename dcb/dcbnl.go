@@ -0,0 +1,38 @@
+package dcb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// The header is the UAPI dcbnl.h of Linux v6.3, vendored so the constants
+// do not depend on the headers installed on the host.
+//go:generate go run ./internal/dcbnlgen -o zdcbnl.go -string dcbnl_commands=Command:uint8,dcbnl_attrs=Attr:uint16,ieee_attrs=IEEEAttr:uint16 internal/dcbnlgen/testdata/dcbnl.h
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L264
+type dcbMsg struct { // struct dcbmsg
+	family uint8
+	cmd    uint8
+	_pad   uint16
+}
+
+// dcbMsgLen is the length of the struct dcbmsg header preceding the
+// attributes of every DCB netlink message.
+const dcbMsgLen = sizeofDcbmsg
+
+func (m *dcbMsg) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *dcbMsg) UnmarshalBinary(b []byte) error {
+	if len(b) < dcbMsgLen {
+		return fmt.Errorf("invalid struct dcbmsg length %d", len(b))
+	}
+	m.family, m.cmd = b[0], b[1]
+	return nil
+}
@@ -0,0 +1,90 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// GetDCBX returns the DCBX mode of ifname, a mask of the DCB_CAP_DCBX_*
+// flags.
+func (cl *Client) GetDCBX(ifname string) (uint8, error) {
+	var mode uint8
+	err := cl.do(func(c *conn) error {
+		var err error
+		mode, err = getDCBX(c, ifname)
+		return err
+	})
+	return mode, err
+}
+
+func getDCBX(c *conn, ifname string) (uint8, error) {
+	msgs, err := execute(c, rtmGetDCB, DCB_CMD_GDCBX, ifname, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ifname: %v, get dcbx: %w", ifname, err)
+	}
+	mode, found, err := parseDCBXReply(msgs)
+	if err != nil {
+		return 0, fmt.Errorf("ifname: %v, decode dcbx: %w", ifname, err)
+	}
+	if found {
+		return mode, nil
+	}
+	return 0, fmt.Errorf("ifname: %v, get dcbx: %w", ifname, ErrNoAttribute)
+}
+
+// parseDCBXReply decodes the replies to DCB_CMD_GDCBX. found is false if
+// they carry no DCB_ATTR_DCBX.
+func parseDCBXReply(msgs []netlink.Message) (mode uint8, found bool, err error) {
+	err = replyAttrs(msgs, DCB_CMD_GDCBX, func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			if ad.Type() == DCB_ATTR_DCBX {
+				mode, found = ad.Uint8(), true
+			}
+		}
+		return nil
+	})
+	return mode, found, err
+}
+
+// SetDCBX sets the DCBX mode of ifname to mode, a mask of the
+// DCB_CAP_DCBX_* flags.
+func (cl *Client) SetDCBX(ifname string, mode uint8) error {
+	return cl.do(func(c *conn) error {
+		msgs, err := execute(c, rtmSetDCB, DCB_CMD_SDCBX, ifname, func(ae *netlink.AttributeEncoder) error {
+			ae.Uint8(DCB_ATTR_DCBX, mode)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, set dcbx: %w", ifname, err)
+		}
+		// setdcbx returns a driver status rather than an errno, non-zero
+		// means the mode was refused.
+		var status uint8
+		err = replyAttrs(msgs, DCB_CMD_SDCBX, func(ad *netlink.AttributeDecoder) error {
+			for ad.Next() {
+				if ad.Type() == DCB_ATTR_DCBX {
+					status = ad.Uint8()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, set dcbx: %w", ifname, err)
+		}
+		if status != 0 {
+			return fmt.Errorf("ifname: %v, set dcbx: mode %#x refused", ifname, mode)
+		}
+		if !cl.verify {
+			return nil
+		}
+		have, err := getDCBX(c, ifname)
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", ObjectDCBX, err)
+		}
+		if have != mode {
+			return &VerifyError{Ifname: ifname, Object: ObjectDCBX, Changes: []Change{{Path: string(ObjectDCBX), Old: mode, New: have}}}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package dcb
+
+import (
+	"math"
+	"time"
+)
+
+// BitTime returns how long transmitting bits takes on a link of speedMbps
+// Mb/s. It returns 0 for an unknown (zero) speed.
+func BitTime(bits uint64, speedMbps uint64) time.Duration {
+	if speedMbps == 0 {
+		return 0
+	}
+	// bits / (speedMbps * 1e6 bit/s) in nanoseconds
+	return time.Duration(float64(bits) * 1e3 / float64(speedMbps))
+}
+
+// DelayTime returns the PFC delay allowance as time at speedMbps Mb/s.
+func (p *IEEEPFC) DelayTime(speedMbps uint64) time.Duration {
+	return BitTime(uint64(p.Delay), speedMbps)
+}
+
+// DelayBytes returns the PFC delay allowance as the number of bytes that
+// arrive during it, independent of the link speed.
+func (p *IEEEPFC) DelayBytes() uint64 {
+	return (uint64(p.Delay) + 7) / 8
+}
+
+// Propagation speed of a signal in fiber or copper, about 2/3 of c.
+const cableMetersPerSecond = 2e8
+
+// pfcFrameBits is the size of a PFC frame: a minimum-size Ethernet frame.
+const pfcFrameBits = 64 * 8
+
+// DefaultResponseBits is the MAC/PHY allowance for reacting to a PFC frame,
+// in bit times. It is the 10GBASE-T delay constraint of IEEE 802.3 Annex 31B;
+// faster PHYs usually need less, so it errs on the safe side.
+const DefaultResponseBits = 25600
+
+// DelayParams describe a link for estimating its PFC delay.
+type DelayParams struct {
+	SpeedMbps   uint64
+	CableMeters float64
+	MTU         int
+	// ResponseBits is the MAC/PHY response allowance in bit times. If 0,
+	// DefaultResponseBits is used.
+	ResponseBits uint64
+}
+
+// DelayEstimate is the PFC delay needed on a link, following the delay model
+// of IEEE 802.1Qbb Annex N.
+type DelayEstimate struct {
+	// CableBits is the round-trip propagation delay of the cable.
+	CableBits uint64
+	// FrameBits covers one maximum frame in flight in each direction plus
+	// the PFC frame itself.
+	FrameBits    uint64
+	ResponseBits uint64
+	// TotalBits is the sum of the above, the value for IEEEPFC.Delay.
+	TotalBits uint64
+	Time      time.Duration
+	// HeadroomBytes is the buffer needed per lossless priority to absorb the
+	// data arriving during TotalBits.
+	HeadroomBytes uint64
+}
+
+// Fits reports whether the estimate can be stored in IEEEPFC.Delay.
+func (e DelayEstimate) Fits() bool {
+	return e.TotalBits <= math.MaxUint16
+}
+
+// EstimateDelay computes the PFC delay and headroom a link needs so no frame
+// is dropped between sending a PFC frame and the peer stopping.
+func EstimateDelay(p DelayParams) DelayEstimate {
+	response := p.ResponseBits
+	if response == 0 {
+		response = DefaultResponseBits
+	}
+
+	var e DelayEstimate
+	e.CableBits = uint64(math.Ceil(2 * p.CableMeters / cableMetersPerSecond * float64(p.SpeedMbps) * 1e6))
+	e.FrameBits = 2*uint64(p.MTU)*8 + pfcFrameBits
+	e.ResponseBits = response
+	e.TotalBits = e.CableBits + e.FrameBits + e.ResponseBits
+	e.Time = BitTime(e.TotalBits, p.SpeedMbps)
+	e.HeadroomBytes = (e.TotalBits + 7) / 8
+	return e
+}
@@ -0,0 +1,71 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// A BlockReason explains why a driver refused or reverted a change.
+type BlockReason string
+
+const (
+	// BlockedByFirmware means the NIC runs DCBX in firmware.
+	BlockedByFirmware BlockReason = "firmware"
+	// BlockedByLLDPAD means lldpad negotiates the config.
+	BlockedByLLDPAD BlockReason = "lldpad"
+	// BlockedByWilling means ETS is willing, so the driver follows the
+	// config the peer advertises rather than the local one.
+	BlockedByWilling BlockReason = "willing"
+)
+
+// A BlockedError wraps the error of a set that was refused or silently
+// reverted when Diagnose found the likely cause.
+type BlockedError struct {
+	Ifname string
+	Reason BlockReason
+	Err    error
+}
+
+func (e *BlockedError) Error() string {
+	var why string
+	switch e.Reason {
+	case BlockedByFirmware:
+		why = "firmware dcbx owns this port"
+	case BlockedByLLDPAD:
+		why = "lldpad owns this port"
+	case BlockedByWilling:
+		why = "ets is willing and follows the peer"
+	}
+	return fmt.Sprintf("%v: %s", e.Err, why)
+}
+
+func (e *BlockedError) Unwrap() error { return e.Err }
+
+// Diagnose cross-references the error of a set on ifname with the DCBX
+// mode and the willing bit, and returns a *BlockedError naming the cause
+// if the error is one drivers return for refused changes (EPERM, EBUSY,
+// EOPNOTSUPP) or a *VerifyError, as for changes reverted without error.
+// Other errors, and errors without a likely cause, are returned as they
+// are.
+func (cl *Client) Diagnose(ifname string, err error) error {
+	if err == nil || errors.As(err, new(*BlockedError)) || errors.As(err, new(*OwnerError)) {
+		return err
+	}
+	if !errors.Is(err, syscall.EPERM) && !errors.Is(err, syscall.EBUSY) && !errors.Is(err, syscall.EOPNOTSUPP) &&
+		!errors.As(err, new(*VerifyError)) {
+		return err
+	}
+	if owner, oerr := cl.Owner(ifname); oerr == nil {
+		switch owner {
+		case OwnerFirmware:
+			return &BlockedError{Ifname: ifname, Reason: BlockedByFirmware, Err: err}
+		case OwnerLLDPAD:
+			return &BlockedError{Ifname: ifname, Reason: BlockedByLLDPAD, Err: err}
+		}
+	}
+	if ets, eerr := cl.GetETS(ifname); eerr == nil && ets.Willing != 0 {
+		return &BlockedError{Ifname: ifname, Reason: BlockedByWilling, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package dcb
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// A Change is a field that differs between two snapshots. Old or New is nil
+// when the object exists on one side only.
+type Change struct {
+	Path string `json:"path"` // such as "ets.TCTxBW[2]" or "apps"
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %+v -> %+v", c.Path, display(c.Old), display(c.New))
+}
+
+func display(v any) any {
+	if v == nil {
+		return "(none)"
+	}
+	return v
+}
+
+// Diff compares the DCB state of two snapshots field by field. The schema
+// version, capture time, interface name and counters are not compared, and the APP table is
+// compared as a set, reporting added and removed entries.
+func Diff(a, b *Snapshot) []Change {
+	var changes []Change
+	av, bv := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	t := av.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "schema", "ifname", "time":
+			continue
+		case "apps":
+			changes = append(changes, diffApps(a.Apps, b.Apps)...)
+			continue
+		}
+		diffValue(name, av.Field(i), bv.Field(i), false, &changes)
+	}
+	return changes
+}
+
+// Pending returns the changes a Restore of want would make to have: the
+// differences in the objects want holds, the others being left untouched
+// by a restore, without the read-only fields drivers ignore.
+func Pending(have, want *Snapshot) []Change {
+	var changes []Change
+	hv, wv := reflect.ValueOf(have).Elem(), reflect.ValueOf(want).Elem()
+	t := hv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		w := wv.Field(i)
+		switch {
+		case name == "schema", name == "ifname", name == "time":
+			continue
+		case (w.Kind() == reflect.Pointer || w.Kind() == reflect.Slice) && w.IsNil():
+			continue
+		case name == "apps":
+			changes = append(changes, diffApps(have.Apps, want.Apps)...)
+			continue
+		}
+		diffValue(name, hv.Field(i), w, true, &changes)
+	}
+	return changes
+}
+
+// diffValue appends the differences of a and b to changes. Fields tagged
+// diff:"ro" are read-only in the kernel and skipped when skipRO is set.
+func diffValue(path string, a, b reflect.Value, skipRO bool, changes *[]Change) {
+	switch a.Kind() {
+	case reflect.Pointer:
+		switch {
+		case a.IsNil() && b.IsNil():
+		case a.IsNil():
+			*changes = append(*changes, Change{Path: path, New: b.Elem().Interface()})
+		case b.IsNil():
+			*changes = append(*changes, Change{Path: path, Old: a.Elem().Interface()})
+		default:
+			diffValue(path, a.Elem(), b.Elem(), skipRO, changes)
+		}
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			// counters tagged diff:"-" change all the time
+			f := t.Field(i)
+			tag := f.Tag.Get("diff")
+			if !f.IsExported() || tag == "-" || skipRO && tag == "ro" {
+				continue
+			}
+			diffValue(path+"."+f.Name, a.Field(i), b.Field(i), skipRO, changes)
+		}
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			diffValue(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), skipRO, changes)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// diffApps returns the entries removed from a and those added in b, each
+// ordered by CompareApps whatever the order of the tables.
+func diffApps(a, b []App) []Change {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.SortFunc(a, CompareApps)
+	slices.SortFunc(b, CompareApps)
+	in := func(apps []App) map[App]bool {
+		m := map[App]bool{}
+		for _, app := range apps {
+			m[app] = true
+		}
+		return m
+	}
+	inA, inB := in(a), in(b)
+
+	var changes []Change
+	for _, app := range a {
+		if !inB[app] {
+			changes = append(changes, Change{Path: "apps", Old: app})
+		}
+	}
+	for _, app := range b {
+		if !inA[app] {
+			changes = append(changes, Change{Path: "apps", New: app})
+		}
+	}
+	return changes
+}
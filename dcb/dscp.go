@@ -0,0 +1,227 @@
+package dcb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mdlayher/netlink"
+)
+
+// DSCPMax is the largest DSCP value.
+const DSCPMax = 63
+
+// DSCPMap maps DSCP values to the priorities of their selector-5 APP
+// entries. A DSCP may have several entries; unmapped values are absent.
+type DSCPMap map[uint8][]uint8
+
+// DSCPApps returns the DSCP map of the APP entries apps.
+func DSCPApps(apps []App) DSCPMap {
+	m := DSCPMap{}
+	for _, a := range apps {
+		if a.Selector != IEEE_8021QAZ_APP_SEL_DSCP || a.Protocol > DSCPMax {
+			continue
+		}
+		m[uint8(a.Protocol)] = append(m[uint8(a.Protocol)], a.Priority)
+	}
+	for dscp := range m {
+		sort.Slice(m[dscp], func(i, j int) bool { return m[dscp][i] < m[dscp][j] })
+	}
+	return m
+}
+
+// String summarizes m as ranges of DSCP values mapped to the same
+// priorities, e.g. "0-23:0 24-31:3 46:5".
+func (m DSCPMap) String() string {
+	var parts []string
+	start, prev := -1, ""
+	flush := func(end int) {
+		if start < 0 || prev == "" {
+			return
+		}
+		if start == end {
+			parts = append(parts, fmt.Sprintf("%d:%s", start, prev))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d:%s", start, end, prev))
+		}
+	}
+	for dscp := 0; dscp <= DSCPMax; dscp++ {
+		cur := ""
+		if prios, ok := m[uint8(dscp)]; ok {
+			s := make([]string, len(prios))
+			for i, p := range prios {
+				s[i] = fmt.Sprint(p)
+			}
+			cur = strings.Join(s, ",")
+		}
+		if cur != prev {
+			flush(dscp - 1)
+			start, prev = dscp, cur
+		}
+	}
+	flush(DSCPMax)
+	return strings.Join(parts, " ")
+}
+
+// DSCPRange returns the APP entries mapping the DSCP values from to to,
+// inclusive, to prio.
+func DSCPRange(from, to, prio uint8) ([]App, error) {
+	if from > to || to > DSCPMax {
+		return nil, fmt.Errorf("invalid dscp range %d-%d", from, to)
+	}
+	if prio >= IEEE_8021Q_MAX_PRIORITIES {
+		return nil, fmt.Errorf("invalid priority %d", prio)
+	}
+	var apps []App
+	for dscp := int(from); dscp <= int(to); dscp++ {
+		apps = append(apps, App{Selector: IEEE_8021QAZ_APP_SEL_DSCP, Priority: prio, Protocol: uint16(dscp)})
+	}
+	return apps, nil
+}
+
+// SetDSCP maps every DSCP in dscps to prio on ifname, replacing the entries
+// mapping them to other priorities.
+func (cl *Client) SetDSCP(ifname string, prio uint8, dscps ...uint8) error {
+	if prio >= IEEE_8021Q_MAX_PRIORITIES {
+		return fmt.Errorf("invalid priority %d", prio)
+	}
+	want := map[uint8]bool{}
+	for _, d := range dscps {
+		if d > DSCPMax {
+			return fmt.Errorf("invalid dscp %d", d)
+		}
+		want[d] = true
+	}
+
+	return cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+
+		var stale []App
+		have := map[uint8]bool{}
+		for _, a := range cfg.Apps {
+			if a.Selector != IEEE_8021QAZ_APP_SEL_DSCP || !want[uint8(a.Protocol)] {
+				continue
+			}
+			if a.Priority == prio {
+				have[uint8(a.Protocol)] = true
+			} else {
+				stale = append(stale, a)
+			}
+		}
+		var add []App
+		for _, d := range dscps {
+			if !have[d] {
+				add = append(add, App{Selector: IEEE_8021QAZ_APP_SEL_DSCP, Priority: prio, Protocol: uint16(d)})
+				have[d] = true
+			}
+		}
+
+		// Add before deleting so a DSCP is never left unmapped, which
+		// would drop a driver tracking DSCP trust back to PCP.
+		if len(add) > 0 {
+			if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				encodeAppTable(nae, add)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if err := cl.verifyApps(c, ifname, add, false); err != nil {
+				return err
+			}
+		}
+		if len(stale) > 0 {
+			return delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				encodeAppTable(nae, stale)
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// ClearDSCP removes all APP entries of ifname for the DSCP values in dscps.
+func (cl *Client) ClearDSCP(ifname string, dscps ...uint8) error {
+	drop := map[uint8]bool{}
+	for _, d := range dscps {
+		drop[d] = true
+	}
+
+	return cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		var del []App
+		for _, a := range cfg.Apps {
+			if a.Selector == IEEE_8021QAZ_APP_SEL_DSCP && a.Protocol <= DSCPMax && drop[uint8(a.Protocol)] {
+				del = append(del, a)
+			}
+		}
+		if len(del) == 0 {
+			return nil
+		}
+		return delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			encodeAppTable(nae, del)
+			return nil
+		})
+	})
+}
+
+// Trust describes which packet field an interface uses to pick the priority
+// of incoming traffic.
+type Trust struct {
+	// Selectors is the trust table, most trusted first. It is nil when the
+	// kernel or driver has no trust table.
+	Selectors []Selector
+	// Mode is "dscp" or "pcp", the field that effectively decides, or
+	// "none" if the trust table trusts neither.
+	Mode string
+	// Inferred is set when Mode was derived from the APP table because no
+	// trust table is available.
+	Inferred bool
+}
+
+// EffectiveTrust derives the trust state from the trust table (nil if the
+// kernel has none) and the APP table. Without a trust table, drivers such as
+// mlx5 trust DSCP as long as any DSCP APP entry exists and PCP otherwise.
+func EffectiveTrust(trust []Selector, apps []App) Trust {
+	t := Trust{Selectors: trust}
+	if trust != nil {
+		t.Mode = "none"
+		for _, sel := range trust {
+			if sel == IEEE_8021QAZ_APP_SEL_DSCP {
+				t.Mode = "dscp"
+				break
+			}
+			if sel == DCB_APP_SEL_PCP {
+				t.Mode = "pcp"
+				break
+			}
+		}
+		return t
+	}
+
+	t.Inferred = true
+	t.Mode = "pcp"
+	if len(DSCPApps(apps)) > 0 {
+		t.Mode = "dscp"
+	}
+	return t
+}
+
+// GetEffectiveTrust returns the trust state of ifname, see EffectiveTrust.
+func (cl *Client) GetEffectiveTrust(ifname string) (Trust, error) {
+	var t Trust
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		t = EffectiveTrust(cfg.Trust, cfg.Apps)
+		return nil
+	})
+	return t, err
+}
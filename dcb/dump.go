@@ -0,0 +1,132 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mdlayher/netlink"
+)
+
+// DumpMessage renders the dcbnl message m as annotated hex: the nlmsghdr,
+// the dcbmsg and the attribute TLVs with their type names, nested ones
+// indented below their container. Messages of other families are dumped
+// as plain hex after the header.
+func DumpMessage(m netlink.Message) string {
+	var sb strings.Builder
+	h := m.Header
+	fmt.Fprintf(&sb, "nlmsghdr: len %d type %s flags %#x seq %d pid %d\n",
+		nlmsgHdrLen+len(m.Data), headerTypeName(h.Type), uint16(h.Flags), h.Sequence, h.PID)
+	if (h.Type != rtmGetDCB && h.Type != rtmSetDCB) || len(m.Data) < dcbMsgLen {
+		dumpHex(&sb, 1, m.Data)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "dcbmsg: family %d cmd %v\n", m.Data[0], Command(m.Data[1]))
+	dumpAttrs(&sb, nil, m.Data[dcbMsgLen:])
+	return sb.String()
+}
+
+func headerTypeName(t netlink.HeaderType) string {
+	switch t {
+	case rtmGetDCB:
+		return "RTM_GETDCB"
+	case rtmSetDCB:
+		return "RTM_SETDCB"
+	case rtmGetLink:
+		return "RTM_GETLINK"
+	case rtmNewLink:
+		return "RTM_NEWLINK"
+	case netlink.Error:
+		return "NLMSG_ERROR"
+	case netlink.Done:
+		return "NLMSG_DONE"
+	}
+	return fmt.Sprint(uint16(t))
+}
+
+// dumpAttrs writes the attributes in b, nested in parents. Bytes that do
+// not form a whole attribute are dumped as trailing garbage, as a driver
+// bug in the layout is what the dump is for.
+func dumpAttrs(sb *strings.Builder, parents []uint16, b []byte) {
+	depth := len(parents) + 1
+	pos := 0
+	for pos < len(b) {
+		if len(b)-pos < nlaHdrLen {
+			break
+		}
+		l := int(binary.NativeEndian.Uint16(b[pos:]))
+		if l < nlaHdrLen || pos+l > len(b) {
+			break
+		}
+		raw := binary.NativeEndian.Uint16(b[pos+2:])
+		typ := raw &^ (netlink.Nested | netlink.NetByteOrder)
+		data := b[pos+nlaHdrLen : pos+l]
+		path := append(parents[:len(parents):len(parents)], typ)
+		nested := raw&netlink.Nested != 0 || dumpNested(parents, typ, data)
+		kind := ""
+		if nested {
+			kind = ", nested"
+		}
+		fmt.Fprintf(sb, "%s%s (%d%s) len %d\n", indent(depth), attrName(parents, typ), typ, kind, l)
+		if nested {
+			dumpAttrs(sb, path, data)
+		} else {
+			if depth == 1 && typ == DCB_ATTR_IFNAME {
+				fmt.Fprintf(sb, "%s%q\n", indent(depth+1), strings.TrimRight(string(data), "\x00"))
+			}
+			dumpHex(sb, depth+1, data)
+		}
+		pos += nlaAlign(l)
+	}
+	if pos < len(b) {
+		fmt.Fprintf(sb, "%strailing %d bytes not forming an attribute\n", indent(depth), len(b)-pos)
+		dumpHex(sb, depth+1, b[pos:])
+	}
+}
+
+// dumpNested reports whether the attribute typ within parents holds
+// attributes. dcbnl does not flag its containers, so the known ones are
+// listed and deeper levels, such as the traffic classes of a CEE PG
+// config, go by their layout.
+func dumpNested(parents []uint16, typ uint16, data []byte) bool {
+	switch {
+	case len(parents) == 0:
+		switch typ {
+		case DCB_ATTR_PFC_CFG, DCB_ATTR_PG_CFG, DCB_ATTR_CAP, DCB_ATTR_NUMTCS, DCB_ATTR_BCN,
+			DCB_ATTR_APP, DCB_ATTR_IEEE, DCB_ATTR_FEATCFG, DCB_ATTR_CEE:
+			return true
+		}
+		return false
+	case len(parents) == 1 && parents[0] == DCB_ATTR_IEEE:
+		switch typ {
+		case DCB_ATTR_IEEE_APP_TABLE, DCB_ATTR_IEEE_PEER_APP, DCB_ATTR_DCB_APP_TRUST_TABLE:
+			return true
+		}
+		return false
+	case len(parents) == 1 && parents[0] == DCB_ATTR_CEE:
+		switch typ {
+		case DCB_ATTR_CEE_PEER_APP_TABLE, DCB_ATTR_CEE_TX_PG, DCB_ATTR_CEE_RX_PG,
+			DCB_ATTR_CEE_PFC, DCB_ATTR_CEE_APP_TABLE, DCB_ATTR_CEE_FEAT:
+			return true
+		}
+		return false
+	}
+	// more than a lone attribute header, which a u8 value can pass for
+	return looksNested(data) && len(data) > nlaHdrLen+1
+}
+
+func dumpHex(sb *strings.Builder, depth int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(hex.Dump(b), "\n"), "\n") {
+		sb.WriteString(indent(depth))
+		sb.WriteString(strings.TrimSuffix(line, "\n"))
+		sb.WriteByte('\n')
+	}
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
@@ -0,0 +1,160 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/mdlayher/netlink"
+)
+
+// ErrNoAttribute is returned when the kernel reply does not carry the
+// requested attribute, e.g. the driver does not implement it.
+var ErrNoAttribute = errors.New("attribute not present in reply")
+
+// ErrUnsupportedPlatform is returned by the queries of the package on
+// platforms other than Linux, where dcbnl does not exist.
+var ErrUnsupportedPlatform = fmt.Errorf("dcb: dcbnl is only available on linux, not %s", runtime.GOOS)
+
+// IfaceError records the failure of a query against one interface.
+type IfaceError struct {
+	Ifname string
+	Err    error
+}
+
+func (e *IfaceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Ifname, e.Err)
+}
+
+func (e *IfaceError) Unwrap() error { return e.Err }
+
+// A RequestError is returned when the kernel rejects a dcbnl request. If the
+// kernel sent an extended acknowledgement, it carries the kernel's message
+// and the attribute of the request the reported offset points into.
+type RequestError struct {
+	Cmd uint8
+	// Err is the *netlink.OpError returned by the socket.
+	Err error
+	// Message is the extended acknowledgement message, if any.
+	Message string
+	// Offset is the byte offset into the request message reported by the
+	// kernel, 0 if none was reported.
+	Offset int
+	// AttrPath holds the attribute types leading to Offset, outermost first,
+	// and AttrOffset the offset of Offset into the payload of the innermost
+	// one. AttrPath is empty when Offset does not point into an attribute.
+	AttrPath   []uint16
+	AttrOffset int
+}
+
+func (e *RequestError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v: %v", Command(e.Cmd), e.Err)
+	if len(e.AttrPath) > 0 {
+		types := make([]string, len(e.AttrPath))
+		for i, t := range e.AttrPath {
+			types[i] = attrName(e.AttrPath[:i], t)
+		}
+		fmt.Fprintf(&sb, " (attribute %s, payload offset %d)", strings.Join(types, "/"), e.AttrOffset)
+	}
+	return sb.String()
+}
+
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// attrName names the attribute typ nested in the attributes parents. Only
+// the top level and DCB_ATTR_IEEE have named types, deeper levels are shown
+// as numbers.
+func attrName(parents []uint16, typ uint16) string {
+	switch {
+	case len(parents) == 0:
+		return Attr(typ).String()
+	case len(parents) == 1 && parents[0] == DCB_ATTR_IEEE:
+		if typ == DCB_ATTR_DCB_APP_TRUST_TABLE {
+			return "DCB_ATTR_DCB_APP_TRUST_TABLE"
+		}
+		return IEEEAttr(typ).String()
+	}
+	return strconv.Itoa(int(typ))
+}
+
+// nlmsgHdrLen is the length of struct nlmsghdr.
+const nlmsgHdrLen = 16
+
+// newRequestError wraps an error returned for the request req, resolving the
+// extended acknowledgement offset against the attributes of req.
+func newRequestError(cmd uint8, req netlink.Message, err error) error {
+	re := &RequestError{Cmd: cmd, Err: err}
+
+	var opErr *netlink.OpError
+	if !errors.As(err, &opErr) {
+		return re
+	}
+	re.Message = opErr.Message
+	re.Offset = opErr.Offset
+
+	off := re.Offset - nlmsgHdrLen - dcbMsgLen
+	if re.Offset == 0 || off < 0 || len(req.Data) < dcbMsgLen {
+		return re
+	}
+	re.AttrPath, re.AttrOffset = attrPath(req.Data[dcbMsgLen:], off)
+	return re
+}
+
+// attrPath walks the attributes in b and returns the types of the (nested)
+// attributes containing the byte offset off, and the offset of off into the
+// payload of the innermost one.
+func attrPath(b []byte, off int) ([]uint16, int) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		return nil, 0
+	}
+
+	pos := 0
+	for _, a := range attrs {
+		l := nlaAlign(int(a.Length))
+		if off < pos || off >= pos+l {
+			pos += l
+			continue
+		}
+
+		typ := a.Type &^ (netlink.Nested | netlink.NetByteOrder)
+		payloadOff := off - pos - nlaHdrLen
+		if payloadOff < 0 {
+			return []uint16{typ}, 0
+		}
+		if a.Type&netlink.Nested != 0 || looksNested(a.Data) {
+			if sub, subOff := attrPath(a.Data, payloadOff); len(sub) > 0 {
+				return append([]uint16{typ}, sub...), subOff
+			}
+		}
+		return []uint16{typ}, payloadOff
+	}
+	return nil, 0
+}
+
+// nlaHdrLen is the length of struct nlattr.
+const nlaHdrLen = 4
+
+func nlaAlign(l int) int { return (l + 3) &^ 3 }
+
+// looksNested reports whether b parses cleanly as a stream of attributes.
+// dcbnl does not set NLA_F_NESTED on its containers, so structs and nested
+// attributes can only be told apart by their layout.
+func looksNested(b []byte) bool {
+	pos := 0
+	for pos < len(b) {
+		if len(b)-pos < nlaHdrLen {
+			return false
+		}
+		l := int(binary.NativeEndian.Uint16(b[pos:]))
+		if l < nlaHdrLen || pos+l > len(b) {
+			return false
+		}
+		pos += nlaAlign(l)
+	}
+	return len(b) > 0
+}
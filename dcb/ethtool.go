@@ -0,0 +1,55 @@
+package dcb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// PauseStats are per-priority PFC frame counters read from the ethtool
+// statistics of a driver, as shown by ethtool -S.
+type PauseStats struct {
+	Requests    [IEEE_8021QAZ_MAX_TCS]uint64 // count of the sent pfc frames
+	Indications [IEEE_8021QAZ_MAX_TCS]uint64 // count of the received pfc frames
+}
+
+// pauseStatNames match the per-priority pause counters of the drivers
+// which name them, capturing the direction and the priority.
+var pauseStatNames = []*regexp.Regexp{
+	regexp.MustCompile(`^(rx|tx)_prio([0-7])_pause$`),                                  // mlx5, mlx4
+	regexp.MustCompile(`^(?:port\.)?(rx|tx)_priority_([0-7])_xoff(?:_rx|_tx|\.nic)?$`), // ice, i40e
+	regexp.MustCompile(`^(rx|tx)_pb_([0-7])_pxoff$`),                                   // ixgbe
+	regexp.MustCompile(`^(rx|tx)_pfc_ena_frames_pri([0-7])$`),                          // bnxt_en
+}
+
+// EthtoolPauseStats returns the per-priority pause counters of ifname from
+// its ethtool statistics. Some drivers leave the dcbnl PFC counters at zero
+// and only count there. It returns ErrNoAttribute if the driver has no
+// counters of a known name.
+func EthtoolPauseStats(ifname string) (*PauseStats, error) {
+	names, values, err := ethtoolStats(ifname)
+	if err != nil {
+		return nil, fmt.Errorf("ifname: %v, ethtool stats: %w", ifname, err)
+	}
+	ps := &PauseStats{}
+	found := false
+	for i, name := range names {
+		for _, re := range pauseStatNames {
+			m := re.FindStringSubmatch(name)
+			if m == nil {
+				continue
+			}
+			prio, _ := strconv.Atoi(m[2])
+			if m[1] == "tx" {
+				ps.Requests[prio] = values[i]
+			} else {
+				ps.Indications[prio] = values[i]
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("ifname: %v, ethtool pause stats: %w", ifname, ErrNoAttribute)
+	}
+	return ps, nil
+}
@@ -0,0 +1,87 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// From include/uapi/linux/ethtool.h, which x/sys/unix lacks.
+const (
+	ethGStringLen = 32
+	ethSSStats    = 1
+)
+
+// ifreqData is a struct ifreq carrying a pointer, as SIOCETHTOOL takes.
+type ifreqData struct {
+	name [unix.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [24 - unsafe.Sizeof(uintptr(0))]byte
+}
+
+// ethtoolStats returns the names and values of the ETH_SS_STATS counters
+// of ifname, with ETHTOOL_GSTRINGS and ETHTOOL_GSTATS. Like ethtool, it
+// sizes both from the count the driver info reports.
+func ethtoolStats(ifname string) ([]string, []uint64, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unix.Close(fd)
+	info, err := unix.IoctlGetEthtoolDrvinfo(fd, ifname)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := int(info.N_stats)
+	if n == 0 {
+		return nil, nil, nil
+	}
+
+	// struct ethtool_gstrings: cmd, string_set, len, then the strings
+	strs := make([]byte, 12+n*ethGStringLen)
+	binary.NativeEndian.PutUint32(strs[0:], unix.ETHTOOL_GSTRINGS)
+	binary.NativeEndian.PutUint32(strs[4:], ethSSStats)
+	binary.NativeEndian.PutUint32(strs[8:], uint32(n))
+	if err := ethtoolIoctl(fd, ifname, strs); err != nil {
+		return nil, nil, fmt.Errorf("get stat names: %w", err)
+	}
+	if got := int(binary.NativeEndian.Uint32(strs[8:])); got != n {
+		return nil, nil, fmt.Errorf("get stat names: %d names for %d stats", got, n)
+	}
+
+	// struct ethtool_stats: cmd, n_stats, then the u64 values
+	stats := make([]byte, 8+n*8)
+	binary.NativeEndian.PutUint32(stats[0:], unix.ETHTOOL_GSTATS)
+	binary.NativeEndian.PutUint32(stats[4:], uint32(n))
+	if err := ethtoolIoctl(fd, ifname, stats); err != nil {
+		return nil, nil, fmt.Errorf("get stats: %w", err)
+	}
+	if got := int(binary.NativeEndian.Uint32(stats[4:])); got != n {
+		return nil, nil, fmt.Errorf("get stats: %d values for %d stats", got, n)
+	}
+
+	names := make([]string, n)
+	values := make([]uint64, n)
+	for i := range n {
+		off := 12 + i*ethGStringLen
+		names[i] = unix.ByteSliceToString(strs[off : off+ethGStringLen])
+		values[i] = binary.NativeEndian.Uint64(stats[8+i*8:])
+	}
+	return names, values, nil
+}
+
+func ethtoolIoctl(fd int, ifname string, buf []byte) error {
+	var ifr ifreqData
+	if len(ifname) >= len(ifr.name) {
+		return unix.EINVAL
+	}
+	copy(ifr.name[:], ifname)
+	ifr.data = unsafe.Pointer(&buf[0])
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L58
+type IEEEETS struct { // struct ieee_ets
+	Willing    uint8
+	ETSCap     uint8 `diff:"ro"`
+	CBS        uint8
+	TCTxBW     [IEEE_8021QAZ_MAX_TCS]uint8 // tc tx bandwidth in percent
+	TCRxBW     [IEEE_8021QAZ_MAX_TCS]uint8 // tc rx bandwidth in percent
+	TCTSA      [IEEE_8021QAZ_MAX_TCS]TSA   // transmission selection algorithm per tc
+	PrioTC     [IEEE_8021QAZ_MAX_TCS]uint8 // priority to tc mapping
+	TCRecoBW   [IEEE_8021QAZ_MAX_TCS]uint8
+	TCRecoTSA  [IEEE_8021QAZ_MAX_TCS]TSA
+	RecoPrioTC [IEEE_8021QAZ_MAX_TCS]uint8
+}
+
+const ieeeETSLen = sizeofIeeeEts
+
+func parseIEEEETS(b []byte) (*IEEEETS, error) {
+	if len(b) < ieeeETSLen {
+		return nil, fmt.Errorf("invalid struct ieee_ets length %d", len(b))
+	}
+
+	e := &IEEEETS{
+		Willing: b[0],
+		ETSCap:  b[1],
+		CBS:     b[2],
+	}
+	const n = IEEE_8021QAZ_MAX_TCS
+	for i := 0; i < n; i++ {
+		e.TCTxBW[i] = b[3+i]
+		e.TCRxBW[i] = b[3+n+i]
+		e.TCTSA[i] = TSA(b[3+2*n+i])
+		e.PrioTC[i] = b[3+3*n+i]
+		e.TCRecoBW[i] = b[3+4*n+i]
+		e.TCRecoTSA[i] = TSA(b[3+5*n+i])
+		e.RecoPrioTC[i] = b[3+6*n+i]
+	}
+	return e, nil
+}
+
+func (e *IEEEETS) marshal() []byte {
+	b := make([]byte, ieeeETSLen)
+	b[0], b[1], b[2] = e.Willing, e.ETSCap, e.CBS
+	const n = IEEE_8021QAZ_MAX_TCS
+	for i := 0; i < n; i++ {
+		b[3+i] = e.TCTxBW[i]
+		b[3+n+i] = e.TCRxBW[i]
+		b[3+2*n+i] = uint8(e.TCTSA[i])
+		b[3+3*n+i] = e.PrioTC[i]
+		b[3+4*n+i] = e.TCRecoBW[i]
+		b[3+5*n+i] = uint8(e.TCRecoTSA[i])
+		b[3+6*n+i] = e.RecoPrioTC[i]
+	}
+	return b
+}
+
+// Validate checks the tc tables of e for consistency: the bandwidth of the
+// ETS traffic classes adds up to 100 percent and every priority maps to a
+// valid tc. The recommendation tables are checked alike when set.
+func (e *IEEEETS) Validate() error {
+	if err := validateTCTables("", e.TCTSA, e.TCTxBW, e.PrioTC); err != nil {
+		return err
+	}
+	if e.HasReco() {
+		return validateTCTables("reco ", e.TCRecoTSA, e.TCRecoBW, e.RecoPrioTC)
+	}
+	return nil
+}
+
+// HasReco reports whether e holds an ETS recommendation, the reco_* tables
+// a willing peer adopts from the ETS Recommendation TLV of the host. All
+// zero, the host advertises none.
+func (e *IEEEETS) HasReco() bool {
+	var none [IEEE_8021QAZ_MAX_TCS]uint8
+	var noTSA [IEEE_8021QAZ_MAX_TCS]TSA
+	return e.TCRecoBW != none || e.TCRecoTSA != noTSA || e.RecoPrioTC != none
+}
+
+func validateTCTables(prefix string, tsa [IEEE_8021QAZ_MAX_TCS]TSA, bw, prioTC [IEEE_8021QAZ_MAX_TCS]uint8) error {
+	sum, ets := 0, false
+	for tc := 0; tc < IEEE_8021QAZ_MAX_TCS; tc++ {
+		if tsa[tc] == TSAETS {
+			ets = true
+			sum += int(bw[tc])
+		}
+	}
+	if ets && sum != 100 {
+		return fmt.Errorf("%sets bandwidth adds up to %d%%, want 100%%", prefix, sum)
+	}
+	for prio, tc := range prioTC {
+		if tc >= IEEE_8021QAZ_MAX_TCS {
+			return fmt.Errorf("%sprio %d mapped to invalid tc %d", prefix, prio, tc)
+		}
+	}
+	return nil
+}
+
+// GetETS returns the IEEE 802.1Qaz ETS managed object of ifname.
+func (cl *Client) GetETS(ifname string) (*IEEEETS, error) {
+	var ets *IEEEETS
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.ETS == nil {
+			return fmt.Errorf("ifname: %v, get ieee ets: %w", ifname, ErrNoAttribute)
+		}
+		ets = cfg.ETS
+		return nil
+	})
+	return ets, err
+}
+
+// SetETS configures the IEEE 802.1Qaz ETS managed object of ifname.
+func (cl *Client) SetETS(ifname string, ets *IEEEETS) error {
+	return cl.do(func(c *conn) error {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_ETS, ets.marshal())
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, ObjectETS, ets, func(cfg *ieeeConfig) any { return cfg.ETS })
+	})
+}
+
+// SetPrioTC remaps priorities to traffic classes on ifname, keeping the
+// rest of the ETS configuration. m maps priorities to their new tc.
+func (cl *Client) SetPrioTC(ifname string, m map[uint8]uint8) error {
+	return cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.ETS == nil {
+			return fmt.Errorf("ifname: %v, get ieee ets: %w", ifname, ErrNoAttribute)
+		}
+
+		ets := *cfg.ETS
+		for prio, tc := range m {
+			if prio >= IEEE_8021Q_MAX_PRIORITIES {
+				return fmt.Errorf("invalid priority %d", prio)
+			}
+			if tc >= IEEE_8021QAZ_MAX_TCS || (ets.ETSCap > 0 && tc >= ets.ETSCap) {
+				return fmt.Errorf("invalid tc %d, ifname %v supports %d", tc, ifname, ets.ETSCap)
+			}
+			ets.PrioTC[prio] = tc
+		}
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_ETS, ets.marshal())
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, ObjectETS, &ets, func(cfg *ieeeConfig) any { return cfg.ETS })
+	})
+}
@@ -0,0 +1,84 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A ConfigWatcher receives the dcbnl notifications the kernel sends when
+// the DCB config of an interface is changed over dcbnl, by any process,
+// and by the drivers which report firmware DCBX changes the same way.
+// Drivers applying changes silently are only caught by polling.
+type ConfigWatcher struct {
+	c *netlink.Conn
+}
+
+// WatchConfig subscribes to the RTNLGRP_DCB notifications of the host.
+func WatchConfig() (*ConfigWatcher, error) {
+	c, err := dialNetlink(netlinkRoute, &netlink.Config{Groups: 1 << (rtnlgrpDCB - 1)})
+	if err != nil {
+		return nil, fmt.Errorf("netlink dial: %w", err)
+	}
+	return &ConfigWatcher{c: c}, nil
+}
+
+// Next blocks until the next notifications arrive and returns the names of
+// the interfaces they are about. As with LinkWatcher.Next, an error
+// wrapping ENOBUFS means notifications were lost.
+func (w *ConfigWatcher) Next() ([]string, error) {
+	msgs, err := w.c.Receive()
+	if err != nil {
+		return nil, fmt.Errorf("receive dcb events: %w", err)
+	}
+	var ifnames []string
+	for _, m := range msgs {
+		t := m.Header.Type
+		if t != rtmGetDCB && t != rtmSetDCB {
+			continue
+		}
+		ifname, err := parseConfigEvent(m.Data)
+		if err != nil {
+			return nil, err
+		}
+		ifnames = append(ifnames, ifname)
+	}
+	return ifnames, nil
+}
+
+// Close unsubscribes, unblocking a pending Next.
+func (w *ConfigWatcher) Close() error {
+	return w.c.Close()
+}
+
+func parseConfigEvent(b []byte) (string, error) {
+	ifname, err := dcbmsgIfname(b)
+	if err != nil {
+		return "", fmt.Errorf("dcb event: %w", err)
+	}
+	return ifname, nil
+}
+
+// dcbmsgIfname returns the DCB_ATTR_IFNAME of the dcbnl message b.
+func dcbmsgIfname(b []byte) (string, error) {
+	if len(b) < dcbMsgLen {
+		return "", fmt.Errorf("short dcbmsg (%d bytes)", len(b))
+	}
+	ad, err := netlink.NewAttributeDecoder(b[dcbMsgLen:])
+	if err != nil {
+		return "", err
+	}
+	var ifname string
+	for ad.Next() {
+		if ad.Type() == DCB_ATTR_IFNAME {
+			ifname = ad.String()
+		}
+	}
+	if err := ad.Err(); err != nil {
+		return "", err
+	}
+	if ifname == "" {
+		return "", ErrNoAttribute
+	}
+	return ifname, nil
+}
@@ -0,0 +1,192 @@
+package dcb
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mdlayher/netlink"
+)
+
+// The fuzz targets feed arbitrary payloads to the decoders, which must
+// report malformed input as errors and never panic. They are seeded with
+// replies as the kernel sends them.
+
+// replyData returns the payload of a reply to the dcbnl command cmd, its
+// dcbmsg followed by the attributes added by encode.
+func replyData(tb testing.TB, cmd uint8, encode func(ae *netlink.AttributeEncoder)) []byte {
+	tb.Helper()
+	hdr, err := (&dcbMsg{family: afUnspec, cmd: cmd}).MarshalBinary()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	ae := netlink.NewAttributeEncoder()
+	ae.String(DCB_ATTR_IFNAME, "eth0")
+	encode(ae)
+	attrs, err := ae.Encode()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return append(hdr, attrs...)
+}
+
+// replyMsgs wraps b as the payload of a single dcbnl reply.
+func replyMsgs(b []byte) []netlink.Message {
+	return []netlink.Message{{Header: netlink.Header{Type: rtmGetDCB}, Data: b}}
+}
+
+func FuzzParseIEEEReply(f *testing.F) {
+	pfc := &IEEEPFC{PFCCap: 8, PFCEn: 0x08, Delay: 32, Requests: [IEEE_8021QAZ_MAX_TCS]uint64{3: 12}}
+	ets := &IEEEETS{ETSCap: 8, TCTxBW: [IEEE_8021QAZ_MAX_TCS]uint8{50, 50}, PrioTC: [IEEE_8021QAZ_MAX_TCS]uint8{3: 1}}
+	apps := []App{
+		{Selector: IEEE_8021QAZ_APP_SEL_ETHERTYPE, Priority: 3, Protocol: 0x8915},
+		{Selector: IEEE_8021QAZ_APP_SEL_DSCP, Priority: 3, Protocol: 26},
+	}
+	f.Add(replyData(f, DCB_CMD_IEEE_GET, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(DCB_ATTR_IEEE, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_PFC, pfc.marshal())
+			nae.Bytes(DCB_ATTR_IEEE_ETS, ets.marshal())
+			nae.Bytes(DCB_ATTR_IEEE_MAXRATE, (&IEEEMaxrate{}).marshal())
+			nae.Bytes(DCB_ATTR_DCB_BUFFER, (&Buffer{TotalSize: 1 << 20}).marshal())
+			encodeAppTable(nae, apps)
+			nae.Nested(DCB_ATTR_DCB_APP_TRUST_TABLE, func(tae *netlink.AttributeEncoder) error {
+				tae.Uint8(DCB_ATTR_IEEE_APP, IEEE_8021QAZ_APP_SEL_DSCP)
+				tae.Uint8(DCB_ATTR_DCB_APP, DCB_APP_SEL_PCP)
+				return nil
+			})
+			return nil
+		})
+	}))
+	// a driver with pfc alone, truncated
+	f.Add(replyData(f, DCB_CMD_IEEE_GET, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(DCB_ATTR_IEEE, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_PFC, pfc.marshal()[:ieeePFCLen-1])
+			return nil
+		})
+	}))
+	f.Add(replyData(f, DCB_CMD_IEEE_GET, func(*netlink.AttributeEncoder) {}))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parseIEEEReply(replyMsgs(b))
+	})
+}
+
+func FuzzParseCapReply(f *testing.F) {
+	f.Add(replyData(f, DCB_CMD_GCAP, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(DCB_ATTR_CAP, func(nae *netlink.AttributeEncoder) error {
+			nae.Uint8(DCB_CAP_ATTR_PG, 1)
+			nae.Uint8(DCB_CAP_ATTR_PFC, 1)
+			nae.Uint8(DCB_CAP_ATTR_PG_TCS, 0x80)
+			nae.Uint8(DCB_CAP_ATTR_PFC_TCS, 0x80)
+			nae.Uint8(DCB_CAP_ATTR_DCBX, DCB_CAP_DCBX_HOST|DCB_CAP_DCBX_VER_IEEE)
+			return nil
+		})
+	}))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parseCapReply(replyMsgs(b), &Capabilities{})
+	})
+}
+
+func FuzzParseCEEReply(f *testing.F) {
+	pg := &CEEPG{Willing: 1, PGEn: 1, PGBW: [CEE_DCBX_MAX_PGS]uint8{100}}
+	pfc := &CEEPFC{PFCEn: 0x08}
+	f.Add(replyData(f, DCB_CMD_CEE_GET, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(DCB_ATTR_CEE, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_CEE_PEER_PG, pg.marshal())
+			nae.Bytes(DCB_ATTR_CEE_PEER_PFC, pfc.marshal())
+			nae.Nested(DCB_ATTR_CEE_PEER_APP_TABLE, func(tae *netlink.AttributeEncoder) error {
+				tae.Bytes(DCB_ATTR_CEE_PEER_APP_INFO, (&PeerAppInfo{Willing: 1}).marshal())
+				tae.Bytes(DCB_ATTR_CEE_PEER_APP, App{Selector: 1, Priority: 3, Protocol: 0x8906}.marshal())
+				return nil
+			})
+			nae.Nested(DCB_ATTR_CEE_PFC, func(pae *netlink.AttributeEncoder) error {
+				pae.Uint8(DCB_PFC_UP_ATTR_3, 1)
+				return nil
+			})
+			return nil
+		})
+	}))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parseCEEReply(replyMsgs(b))
+	})
+}
+
+func FuzzParseBCNReply(f *testing.F) {
+	f.Add(replyData(f, DCB_CMD_BCN_GCFG, func(ae *netlink.AttributeEncoder) {
+		ae.Nested(DCB_ATTR_BCN, func(nae *netlink.AttributeEncoder) error {
+			nae.Uint8(DCB_BCN_ATTR_RP_3, 1)
+			nae.Uint32(DCB_BCN_ATTR_BCNA_0, 0xdead)
+			nae.Uint32(DCB_BCN_ATTR_RI, 100)
+			return nil
+		})
+	}))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parseBCNReply(replyMsgs(b))
+	})
+}
+
+func FuzzParseCEEAppReply(f *testing.F) {
+	f.Add(replyData(f, DCB_CMD_GAPP, func(ae *netlink.AttributeEncoder) {
+		up := uint8(0x08)
+		encodeCEEApp(ae, DCB_APP_IDTYPE_ETHTYPE, 0x8906, &up)
+	}))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parseCEEAppReply(replyMsgs(b))
+	})
+}
+
+// FuzzParseTables feeds the same attributes to the decoders of the nested
+// APP and trust tables.
+func FuzzParseTables(f *testing.F) {
+	ae := netlink.NewAttributeEncoder()
+	ae.Bytes(DCB_ATTR_IEEE_APP, App{Selector: IEEE_8021QAZ_APP_SEL_DSCP, Priority: 3, Protocol: 26}.marshal())
+	ae.Bytes(DCB_ATTR_DCB_APP, App{Selector: DCB_APP_SEL_PCP, Priority: 5, Protocol: 8}.marshal())
+	ae.Uint8(DCB_ATTR_IEEE_APP, IEEE_8021QAZ_APP_SEL_DSCP)
+	b, err := ae.Encode()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if ad, err := netlink.NewAttributeDecoder(b); err == nil {
+			parseAppTable(ad)
+		}
+		if ad, err := netlink.NewAttributeDecoder(b); err == nil {
+			parseTrustTable(ad)
+		}
+	})
+}
+
+func FuzzReadCapture(f *testing.F) {
+	var buf bytes.Buffer
+	cp, err := NewCapture(&buf)
+	if err != nil {
+		f.Fatal(err)
+	}
+	req := netlink.Message{Header: netlink.Header{Type: rtmGetDCB, Flags: netlink.Request | netlink.Acknowledge, Sequence: 1}, Data: replyData(f, DCB_CMD_IEEE_GET, func(*netlink.AttributeEncoder) {})}
+	reply := netlink.Message{Header: netlink.Header{Type: rtmGetDCB, Sequence: 1}, Data: req.Data}
+	cp.record(req, []netlink.Message{reply}, nil)
+	cp.record(req, nil, errors.New("no such device"))
+	f.Add(buf.Bytes())
+	f.Fuzz(func(t *testing.T, b []byte) {
+		ReadCapture(bytes.NewReader(b))
+	})
+}
+
+// FuzzParseStructs feeds the same bytes to the decoders of the structs
+// carried in attribute values.
+func FuzzParseStructs(f *testing.F) {
+	f.Add(App{Selector: IEEE_8021QAZ_APP_SEL_DGRAM, Priority: 5, Protocol: 4791}.marshal())
+	f.Add((&IEEEPFC{PFCCap: 8, PFCEn: 0xff}).marshal())
+	f.Add(make([]byte, ceePGLen))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parseApp(b)
+		parseCEEPG(b)
+		parseCEEPFC(b)
+		parseIEEEPFC(b)
+		parseIEEEETS(b)
+		parseIEEEMaxrate(b)
+		parseBuffer(b)
+		parseIEEEQCNStats(b)
+		parsePeerAppInfo(b)
+	})
+}
@@ -0,0 +1,48 @@
+package dcb
+
+import "fmt"
+
+// BufferPlanParams describe the lossless setup PlanBuffer sizes buffers for.
+type BufferPlanParams struct {
+	DelayParams
+	// LosslessPrios are the PFC-enabled priorities. Each gets a buffer of its
+	// own, starting at buffer 1; all other priorities share buffer 0.
+	LosslessPrios []uint8
+	// TotalSize is the buffer memory of the port. If set, buffer 0 gets what
+	// is left after the lossless buffers; if 0, buffer 0 is left at 0.
+	TotalSize uint32
+}
+
+// PlanBuffer returns a dcbnl_buffer configuration whose lossless buffers each
+// hold the headroom EstimateDelay computes for the link plus one MTU, so the
+// pause threshold is crossed before the headroom is eaten into.
+func PlanBuffer(p BufferPlanParams) (*Buffer, error) {
+	if len(p.LosslessPrios) >= DCBX_MAX_BUFFERS {
+		return nil, fmt.Errorf("%d lossless priorities leave no buffer for lossy traffic", len(p.LosslessPrios))
+	}
+
+	size := EstimateDelay(p.DelayParams).HeadroomBytes + uint64(p.MTU)
+	buf := &Buffer{TotalSize: p.TotalSize}
+	var used uint64
+	seen := map[uint8]bool{}
+	for i, prio := range p.LosslessPrios {
+		if prio >= IEEE_8021Q_MAX_PRIORITIES {
+			return nil, fmt.Errorf("invalid priority %d", prio)
+		}
+		if seen[prio] {
+			return nil, fmt.Errorf("duplicate priority %d", prio)
+		}
+		seen[prio] = true
+		buf.Prio2Buffer[prio] = uint8(i + 1)
+		buf.BufferSize[i+1] = uint32(size)
+		used += size
+	}
+
+	if p.TotalSize > 0 {
+		if used >= uint64(p.TotalSize) {
+			return nil, fmt.Errorf("lossless buffers need %d bytes, port has %d", used, p.TotalSize)
+		}
+		buf.BufferSize[0] = p.TotalSize - uint32(used)
+	}
+	return buf, nil
+}
@@ -0,0 +1,287 @@
+// Command dcbnlgen generates the dcbnl constants and struct layouts of
+// package dcb from the kernel UAPI header include/uapi/linux/dcbnl.h.
+//
+// It emits every #define with an integer value and every enum of the header
+// as untyped constants named as in C, and for each struct its size and the
+// offset of each field, so the parsers of package dcb index the kernel's
+// layout instead of hand-computed numbers:
+//
+//	go run ./internal/dcbnlgen -o zdcbnl.go internal/dcbnlgen/testdata/dcbnl.h
+//
+// With -string, the listed enums also get a named Go type whose String
+// method returns the C name of a value, for logs and errors:
+//
+//	-string dcbnl_commands=Command:uint8,dcbnl_attrs=Attr:uint16
+//
+// The input is the header of a pinned kernel release kept in testdata, not
+// the one installed on the host, so the output does not change with the
+// machine it is generated on. Moving to a newer release is replacing that
+// file and regenerating.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	comments = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+	decls    = regexp.MustCompile(`(?m)^#define[ \t]+(\w+)[ \t]+([^\n]+)$|(?s)\benum[ \t]+(\w+)\s*\{(.*?)\}|(?s)\bstruct[ \t]+(\w+)\s*\{(.*?)\};`)
+	field    = regexp.MustCompile(`^__(u8|u16|u32|u64)\s+(\w+)\s*(?:\[(\w+)\])?$`)
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dcbnlgen: ")
+	out := flag.String("o", "zdcbnl.go", "output file")
+	pkg := flag.String("p", "dcb", "package name")
+	stringers := flag.String("string", "", "comma-separated enum=Type:underlying to generate String methods for")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: dcbnlgen [-o file] [-p package] [-string enum=Type:underlying,...] <dcbnl.h>")
+	}
+
+	src, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{values: map[string]int64{}, types: map[string]goType{}}
+	if *stringers != "" {
+		for _, spec := range strings.Split(*stringers, ",") {
+			enum, typ, ok1 := strings.Cut(spec, "=")
+			name, underlying, ok2 := strings.Cut(typ, ":")
+			if !ok1 || !ok2 {
+				log.Fatalf("invalid -string %q, want enum=Type:underlying", spec)
+			}
+			g.types[enum] = goType{name, underlying}
+		}
+	}
+	if err := g.parse(string(src)); err != nil {
+		log.Fatalf("%s: %v", flag.Arg(0), err)
+	}
+	for enum := range g.types {
+		log.Fatalf("%s: no enum %s", flag.Arg(0), enum)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by dcbnlgen from %s; DO NOT EDIT.\n\n", filepath.Base(flag.Arg(0)))
+	fmt.Fprintf(&buf, "package %s\n", *pkg)
+	if g.imports {
+		buf.WriteString("\nimport \"strconv\"\n")
+	}
+	buf.Write(g.buf.Bytes())
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format output: %v", err)
+	}
+	if err := os.WriteFile(*out, b, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	// values holds every constant seen so far, including the __*_MAX
+	// sentinels, which are used in expressions but not emitted.
+	values map[string]int64
+	buf    bytes.Buffer
+	// inDefines is set while emitting a run of consecutive #defines into
+	// one const block.
+	inDefines bool
+	// types maps the enums given with -string to their Go type; entries
+	// are removed once emitted.
+	types map[string]goType
+	// imports is set when the output uses strconv.
+	imports bool
+}
+
+type goType struct {
+	name, underlying string
+}
+
+func (g *generator) parse(src string) error {
+	src = comments.ReplaceAllString(src, "")
+	for _, m := range decls.FindAllStringSubmatch(src, -1) {
+		var err error
+		switch {
+		case m[1] != "":
+			err = g.define(m[1], strings.TrimSpace(m[2]))
+		case m[3] != "":
+			g.endDefines()
+			err = g.enum(m[3], m[4])
+		case m[5] != "":
+			g.endDefines()
+			err = g.layout(m[5], m[6])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	g.endDefines()
+	return nil
+}
+
+func (g *generator) define(name, expr string) error {
+	v, err := g.eval(expr)
+	if err != nil {
+		// include guards and non-integer macros
+		return nil
+	}
+	g.values[name] = v
+	if strings.HasPrefix(name, "_") {
+		return nil
+	}
+	if !g.inDefines {
+		g.buf.WriteString("\nconst (\n")
+		g.inDefines = true
+	}
+	if _, err := strconv.ParseInt(expr, 0, 64); err != nil {
+		// expressions may use the unexported sentinels
+		expr = strconv.FormatInt(v, 10)
+	}
+	fmt.Fprintf(&g.buf, "\t%s = %s\n", name, expr)
+	return nil
+}
+
+func (g *generator) endDefines() {
+	if g.inDefines {
+		g.buf.WriteString(")\n")
+		g.inDefines = false
+	}
+}
+
+func (g *generator) enum(name, body string) error {
+	fmt.Fprintf(&g.buf, "\n// enum %s\nconst (\n", name)
+	var next int64
+	type value struct {
+		name string
+		v    int64
+	}
+	var values []value
+	for _, item := range strings.Split(body, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		ident, expr, ok := strings.Cut(item, "=")
+		ident = strings.TrimSpace(ident)
+		if ok {
+			v, err := g.eval(strings.TrimSpace(expr))
+			if err != nil {
+				return fmt.Errorf("enum %s: %s: %v", name, ident, err)
+			}
+			next = v
+		}
+		g.values[ident] = next
+		if !strings.HasPrefix(ident, "_") {
+			fmt.Fprintf(&g.buf, "\t%s = %d\n", ident, next)
+			values = append(values, value{ident, next})
+		}
+		next++
+	}
+	g.buf.WriteString(")\n")
+
+	typ, ok := g.types[name]
+	if !ok {
+		return nil
+	}
+	delete(g.types, name)
+	fmt.Fprintf(&g.buf, "\n// %s is a value of enum %s.\ntype %s %s\n", typ.name, name, typ.name, typ.underlying)
+	fmt.Fprintf(&g.buf, "\nfunc (v %s) String() string {\n\tswitch v {\n", typ.name)
+	seen := map[int64]bool{}
+	for _, val := range values {
+		// the first name wins over aliases such as DCB_CMD_MAX
+		if seen[val.v] {
+			continue
+		}
+		seen[val.v] = true
+		fmt.Fprintf(&g.buf, "\tcase %s:\n\t\treturn %q\n", val.name, val.name)
+	}
+	fmt.Fprintf(&g.buf, "\t}\n\treturn \"%s(\" + strconv.FormatInt(int64(v), 10) + \")\"\n}\n", typ.name)
+	g.imports = true
+	return nil
+}
+
+var sizes = map[string]int64{"u8": 1, "u16": 2, "u32": 4, "u64": 8}
+
+// layout emits the size of struct name and the offset of its fields, laid
+// out with the natural alignment of the C ABI.
+func (g *generator) layout(name, body string) error {
+	goName := camel(name)
+	var lines []string
+	var off, align int64 = 0, 1
+	for _, decl := range strings.Split(body, ";") {
+		decl = strings.Join(strings.Fields(decl), " ")
+		if decl == "" {
+			continue
+		}
+		m := field.FindStringSubmatch(decl)
+		if m == nil {
+			return fmt.Errorf("struct %s: unsupported field %q", name, decl)
+		}
+		size := sizes[m[1]]
+		n := int64(1)
+		if m[3] != "" {
+			v, err := g.eval(m[3])
+			if err != nil {
+				return fmt.Errorf("struct %s: %s: %v", name, m[2], err)
+			}
+			n = v
+		}
+		off = (off + size - 1) / size * size
+		align = max(align, size)
+		lines = append(lines, fmt.Sprintf("\toff%s%s = %d", goName, camel(m[2]), off))
+		off += size * n
+	}
+	off = (off + align - 1) / align * align
+
+	fmt.Fprintf(&g.buf, "\n// struct %s\nconst (\n\tsizeof%s = %d\n", name, goName, off)
+	g.buf.WriteString(strings.Join(lines, "\n"))
+	g.buf.WriteString("\n)\n")
+	return nil
+}
+
+// eval evaluates the constant expressions of the header: literals, names
+// and sums such as (__DCB_ATTR_IEEE_MAX - 1).
+func (g *generator) eval(expr string) (int64, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		return g.eval(expr[1 : len(expr)-1])
+	}
+	if i := strings.LastIndexAny(expr, "+-"); i > 0 {
+		a, err := g.eval(expr[:i])
+		if err != nil {
+			return 0, err
+		}
+		b, err := g.eval(expr[i+1:])
+		if err != nil {
+			return 0, err
+		}
+		if expr[i] == '-' {
+			return a - b, nil
+		}
+		return a + b, nil
+	}
+	if v, ok := g.values[expr]; ok {
+		return v, nil
+	}
+	return strconv.ParseInt(expr, 0, 64)
+}
+
+// camel turns ieee_pfc into IeeePfc.
+func camel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package dcb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A KernelVersion is the major and minor version of the running kernel.
+type KernelVersion struct {
+	Major, Minor int
+}
+
+func (v KernelVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// AtLeast reports whether v is major.minor or later.
+func (v KernelVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || v.Major == major && v.Minor >= minor
+}
+
+// RunningKernel returns the version of the running kernel, from uname. It
+// returns the zero version if the release string cannot be parsed, which
+// makes every feature check below fail open.
+var RunningKernel = sync.OnceValue(func() KernelVersion {
+	release, err := kernelRelease()
+	if err != nil {
+		return KernelVersion{}
+	}
+	return parseKernelRelease(release)
+})
+
+// parseKernelRelease parses a release such as "6.1.0-18-amd64".
+func parseKernelRelease(release string) KernelVersion {
+	major, rest, _ := strings.Cut(release, ".")
+	minor, _, _ := strings.Cut(rest, ".")
+	minor = strings.TrimRightFunc(minor, func(r rune) bool { return r < '0' || r > '9' })
+	ma, err1 := strconv.Atoi(major)
+	mi, err2 := strconv.Atoi(minor)
+	if err1 != nil || err2 != nil {
+		return KernelVersion{}
+	}
+	return KernelVersion{ma, mi}
+}
+
+// A kernelFeature is a dcbnl attribute added after the IEEE interface.
+// Older kernels parse IEEE attributes liberally and silently drop the ones
+// they do not know, so a set must be refused up front rather than appear to
+// succeed.
+type kernelFeature struct {
+	name         string
+	major, minor int
+}
+
+var (
+	featureTrust  = kernelFeature{"app trust table", 6, 3}
+	featurePCPApp = kernelFeature{"pcp app selector", 6, 3}
+)
+
+// check returns a *KernelError if the running kernel predates f.
+func (f kernelFeature) check() error {
+	v := RunningKernel()
+	if v == (KernelVersion{}) || v.AtLeast(f.major, f.minor) {
+		return nil
+	}
+	return &KernelError{Feature: f.name, Need: KernelVersion{f.major, f.minor}, Have: v}
+}
+
+// A KernelError is returned for attributes the running kernel predates. It
+// matches ErrNoAttribute with errors.Is, so callers treating missing driver
+// support as non-fatal handle old kernels alike.
+type KernelError struct {
+	Feature    string
+	Need, Have KernelVersion
+}
+
+func (e *KernelError) Error() string {
+	return fmt.Sprintf("%s: not available on this kernel (%v, needs %v)", e.Feature, e.Have, e.Need)
+}
+
+func (e *KernelError) Is(target error) bool { return target == ErrNoAttribute }
+
+// TrustTableAvailable reports whether the running kernel has the APP trust
+// table. When it does not, EffectiveTrust can only infer the trust state.
+func TrustTableAvailable() bool {
+	return featureTrust.check() == nil
+}
@@ -0,0 +1,12 @@
+package dcb
+
+import "golang.org/x/sys/unix"
+
+// kernelRelease returns the release of the running kernel from uname.
+func kernelRelease() (string, error) {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return "", err
+	}
+	return unix.ByteSliceToString(uts.Release[:]), nil
+}
@@ -0,0 +1,89 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A LinkEvent is an rtnetlink link notification.
+type LinkEvent struct {
+	Ifname string
+	Index  int
+	// Up is set when the interface is administratively up and has a
+	// carrier.
+	Up bool
+	// Deleted is set when the interface was removed.
+	Deleted bool
+}
+
+// A LinkWatcher receives the link notifications of the host. Drivers
+// commonly drop their DCB config when a link is reset, when the driver is
+// reloaded or when a VF is rebound, which these notifications reveal.
+type LinkWatcher struct {
+	c *netlink.Conn
+}
+
+// WatchLinks subscribes to the rtnetlink link notifications of the host.
+func WatchLinks() (*LinkWatcher, error) {
+	c, err := dialNetlink(netlinkRoute, &netlink.Config{Groups: rtmgrpLink})
+	if err != nil {
+		return nil, fmt.Errorf("netlink dial: %w", err)
+	}
+	return &LinkWatcher{c: c}, nil
+}
+
+// Next blocks until the next notifications arrive and returns them.
+// Notifications the kernel dropped because the socket buffer overflowed are
+// lost, and Next then returns an error wrapping ENOBUFS; callers should
+// assume any link changed.
+func (w *LinkWatcher) Next() ([]LinkEvent, error) {
+	msgs, err := w.c.Receive()
+	if err != nil {
+		return nil, fmt.Errorf("receive link events: %w", err)
+	}
+	var events []LinkEvent
+	for _, m := range msgs {
+		t := m.Header.Type
+		if t != rtmNewLink && t != rtmDelLink {
+			continue
+		}
+		ev, err := parseLinkEvent(m.Data)
+		if err != nil {
+			return nil, err
+		}
+		ev.Deleted = t == rtmDelLink
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// Close unsubscribes, unblocking a pending Next.
+func (w *LinkWatcher) Close() error {
+	return w.c.Close()
+}
+
+func parseLinkEvent(b []byte) (LinkEvent, error) {
+	if len(b) < sizeofIfInfomsg {
+		return LinkEvent{}, fmt.Errorf("link event: short ifinfomsg (%d bytes)", len(b))
+	}
+	flags := binary.NativeEndian.Uint32(b[8:12])
+	ev := LinkEvent{
+		Index: int(int32(binary.NativeEndian.Uint32(b[4:8]))),
+		Up:    flags&iffUp != 0 && flags&iffLowerUp != 0,
+	}
+	ad, err := netlink.NewAttributeDecoder(b[sizeofIfInfomsg:])
+	if err != nil {
+		return LinkEvent{}, fmt.Errorf("link event: %w", err)
+	}
+	for ad.Next() {
+		if ad.Type() == iflaIfname {
+			ev.Ifname = ad.String()
+		}
+	}
+	if err := ad.Err(); err != nil {
+		return LinkEvent{}, fmt.Errorf("link event: %w", err)
+	}
+	return ev, nil
+}
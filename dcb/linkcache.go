@@ -0,0 +1,183 @@
+package dcb
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// A Link is an interface of the host as a LinkCache knows it.
+type Link struct {
+	Name  string
+	Index int
+	// Up is set when the interface is administratively up and has a
+	// carrier.
+	Up bool
+	// PCI and Driver are the PCIAddress and Driver of the interface, read
+	// once when it appears.
+	PCI    string
+	Driver string
+}
+
+// A LinkCache keeps the interfaces of the host, by name and by index,
+// up to date from rtnetlink link notifications, for long-running programs
+// that resolve interfaces on every tick without dumping the links each
+// time. Renamed, re-created and removed interfaces are picked up as they
+// happen; when notifications are lost, the links are dumped again.
+//
+// A LinkCache is safe for concurrent use.
+type LinkCache struct {
+	cl *Client
+	w  *LinkWatcher
+
+	mu      sync.RWMutex
+	byName  map[string]*Link
+	byIndex map[int]*Link
+	err     error
+}
+
+// NewLinkCache subscribes to the link notifications and fills the cache
+// from a link dump with a socket of cl, which must stay open until the
+// cache is closed.
+func (cl *Client) NewLinkCache() (*LinkCache, error) {
+	w, err := WatchLinks()
+	if err != nil {
+		return nil, err
+	}
+	lc := &LinkCache{cl: cl, w: w}
+	// subscribed before the dump, so no change falls in between
+	if err := lc.reload(); err != nil {
+		w.Close()
+		return nil, err
+	}
+	go lc.run()
+	return lc, nil
+}
+
+// Close stops following the notifications. The cache keeps the links it
+// had.
+func (lc *LinkCache) Close() error {
+	return lc.w.Close()
+}
+
+// Err returns the error that stopped the cache following the
+// notifications, nil while it does or after Close.
+func (lc *LinkCache) Err() error {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.err
+}
+
+// Names returns the names of the interfaces, in index order, as Interfaces
+// does.
+func (lc *LinkCache) Names() []string {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	links := make([]*Link, 0, len(lc.byIndex))
+	for _, l := range lc.byIndex {
+		links = append(links, l)
+	}
+	slices.SortFunc(links, func(a, b *Link) int { return a.Index - b.Index })
+	names := make([]string, len(links))
+	for i, l := range links {
+		names[i] = l.Name
+	}
+	return names
+}
+
+// Lookup returns the interface named ifname.
+func (lc *LinkCache) Lookup(ifname string) (Link, bool) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	if l, ok := lc.byName[ifname]; ok {
+		return *l, true
+	}
+	return Link{}, false
+}
+
+// ByIndex returns the interface of index.
+func (lc *LinkCache) ByIndex(index int) (Link, bool) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	if l, ok := lc.byIndex[index]; ok {
+		return *l, true
+	}
+	return Link{}, false
+}
+
+func (lc *LinkCache) run() {
+	for {
+		events, err := lc.w.Next()
+		if errors.Is(err, syscall.ENOBUFS) {
+			err = lc.reload()
+		}
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				lc.mu.Lock()
+				lc.err = err
+				lc.mu.Unlock()
+			}
+			return
+		}
+		lc.mu.Lock()
+		for _, ev := range events {
+			lc.update(ev)
+		}
+		lc.mu.Unlock()
+	}
+}
+
+// reload replaces the links of the cache by those of a link dump.
+func (lc *LinkCache) reload() error {
+	var events []LinkEvent
+	err := lc.cl.do(func(c *conn) error {
+		var err error
+		events, err = dumpLinks(c)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	old := lc.byIndex
+	lc.byName, lc.byIndex = map[string]*Link{}, map[int]*Link{}
+	for _, ev := range events {
+		if l := old[ev.Index]; l != nil && l.Name == ev.Ifname {
+			// known, keep the sysfs values read before
+			l.Up = ev.Up
+			lc.byName[l.Name], lc.byIndex[l.Index] = l, l
+			continue
+		}
+		lc.update(ev)
+	}
+	return nil
+}
+
+// update applies the notification ev. The lock must be held.
+func (lc *LinkCache) update(ev LinkEvent) {
+	old := lc.byIndex[ev.Index]
+	if ev.Deleted {
+		if old != nil {
+			delete(lc.byName, old.Name)
+			delete(lc.byIndex, ev.Index)
+		}
+		return
+	}
+	if old != nil && old.Name == ev.Ifname {
+		old.Up = ev.Up
+		return
+	}
+	if old != nil {
+		// renamed
+		delete(lc.byName, old.Name)
+	}
+	if stale := lc.byName[ev.Ifname]; stale != nil {
+		// re-created with another index
+		delete(lc.byIndex, stale.Index)
+	}
+	l := &Link{Name: ev.Ifname, Index: ev.Index, Up: ev.Up, PCI: PCIAddress(ev.Ifname), Driver: Driver(ev.Ifname)}
+	lc.byName[l.Name], lc.byIndex[l.Index] = l, l
+}
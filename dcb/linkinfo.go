@@ -0,0 +1,193 @@
+package dcb
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mdlayher/netlink"
+)
+
+// LinkInfo is the link state of an interface, which PFC and ETS values
+// are read against: headroom and delays scale with the speed, and what a
+// driver accepts depends on the driver and its firmware.
+type LinkInfo struct {
+	Ifname    string `json:"ifname"`
+	Index     int    `json:"index"`
+	OperState string `json:"operstate"`
+	MTU       uint32 `json:"mtu"`
+	// Speed is in Mb/s, 0 when the link is down or the driver does not
+	// report one.
+	Speed    uint64 `json:"speed"`
+	Driver   string `json:"driver,omitempty"`
+	Firmware string `json:"firmware,omitempty"`
+}
+
+// operStates names the IF_OPER_* values of IFLA_OPERSTATE, RFC 2863.
+var operStates = []string{"unknown", "notpresent", "down", "lowerlayerdown", "testing", "dormant", "up"}
+
+// LinkInfo returns the link state of ifname, from RTM_GETLINK, the ethtool
+// genetlink family, or sysfs on kernels before 5.6 which lack it, and the
+// driver info ioctl. Parts the interface does not report are left zero.
+func (cl *Client) LinkInfo(ifname string) (*LinkInfo, error) {
+	li := &LinkInfo{Ifname: ifname}
+	err := cl.do(func(c *conn) error {
+		return getLink(c, li)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if speed, err := ethtoolSpeed(ifname); err == nil {
+		li.Speed = speed
+	} else {
+		li.Speed = sysfsSpeed(ifname)
+	}
+	li.Driver, li.Firmware = drvinfo(ifname)
+	return li, nil
+}
+
+func getLink(c *conn, li *LinkInfo) error {
+	ae := netlink.NewAttributeEncoder()
+	ae.String(iflaIfname, li.Ifname)
+	attrs, err := ae.Encode()
+	if err != nil {
+		return fmt.Errorf("encode attributes: %w", err)
+	}
+	req := netlink.Message{
+		Header: netlink.Header{Type: rtmGetLink, Flags: netlink.Request},
+		Data:   append(make([]byte, sizeofIfInfomsg), attrs...),
+	}
+	msgs, err := c.roundTrip(req)
+	if err != nil {
+		return fmt.Errorf("ifname: %v, get link: %w", li.Ifname, err)
+	}
+	for _, m := range msgs {
+		if len(m.Data) < sizeofIfInfomsg {
+			continue
+		}
+		li.Index = int(int32(binary.NativeEndian.Uint32(m.Data[4:8])))
+		ad, err := netlink.NewAttributeDecoder(m.Data[sizeofIfInfomsg:])
+		if err != nil {
+			return fmt.Errorf("ifname: %v, decode link: %w", li.Ifname, err)
+		}
+		for ad.Next() {
+			switch ad.Type() {
+			case iflaOperstate:
+				if s := int(ad.Uint8()); s < len(operStates) {
+					li.OperState = operStates[s]
+				} else {
+					li.OperState = strconv.Itoa(s)
+				}
+			case iflaMTU:
+				li.MTU = ad.Uint32()
+			}
+		}
+		if err := ad.Err(); err != nil {
+			return fmt.Errorf("ifname: %v, decode link: %w", li.Ifname, err)
+		}
+	}
+	return nil
+}
+
+// Interfaces returns the names of the interfaces of the host, in index
+// order, from a RTM_GETLINK dump; see CompareIfnames for an order that does
+// not depend on the host. A dump interrupted by links coming and
+// going is read again, see ErrDumpInterrupted.
+func (cl *Client) Interfaces() ([]string, error) {
+	var ifnames []string
+	err := cl.do(func(c *conn) error {
+		links, err := dumpLinks(c)
+		for _, l := range links {
+			ifnames = append(ifnames, l.Ifname)
+		}
+		return err
+	})
+	return ifnames, err
+}
+
+// dumpLinks returns the links of the host from a RTM_GETLINK dump, as the
+// notifications of a LinkWatcher describe them.
+func dumpLinks(c *conn) ([]LinkEvent, error) {
+	req := netlink.Message{
+		Header: netlink.Header{Type: rtmGetLink, Flags: netlink.Request | netlink.Dump},
+		Data:   make([]byte, sizeofIfInfomsg),
+	}
+	msgs, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("dump links: %w", err)
+	}
+	var links []LinkEvent
+	for _, m := range msgs {
+		if m.Header.Type != rtmNewLink || len(m.Data) < sizeofIfInfomsg {
+			continue
+		}
+		l, err := parseLinkEvent(m.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decode link: %w", err)
+		}
+		if l.Ifname != "" {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+// CompareIfnames orders interface names alphabetically, except that runs
+// of digits compare by their value, so eth2 sorts before eth10. Unlike the
+// index order, the result is the same on hosts naming their interfaces
+// alike.
+func CompareIfnames(a, b string) int {
+	if c := compareNatural(a, b); c != 0 {
+		return c
+	}
+	// eth01 and eth1
+	return strings.Compare(a, b)
+}
+
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		na, nb := digitPrefix(a), digitPrefix(b)
+		if na == 0 || nb == 0 {
+			if c := cmp.Compare(a[0], b[0]); c != 0 {
+				return c
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		da, db := strings.TrimLeft(a[:na], "0"), strings.TrimLeft(b[:nb], "0")
+		if c := cmp.Compare(len(da), len(db)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(da, db); c != 0 {
+			return c
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// digitPrefix returns the number of leading ASCII digits of s.
+func digitPrefix(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// sysfsSpeed returns the speed of ifname in Mb/s as reported by sysfs, or
+// 0 if the link is down or the driver does not report one.
+func sysfsSpeed(ifname string) uint64 {
+	b, err := os.ReadFile(sysClassNet + "/" + ifname + "/speed")
+	if err != nil {
+		return 0
+	}
+	speed, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil || speed <= 0 {
+		return 0
+	}
+	return uint64(speed)
+}
@@ -0,0 +1,115 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// ethtoolSpeed queries the link speed of ifname with ETHTOOL_MSG_LINKMODES_GET.
+func ethtoolSpeed(ifname string) (uint64, error) {
+	c, err := netlink.Dial(unix.NETLINK_GENERIC, nil)
+	if err != nil {
+		return 0, fmt.Errorf("netlink dial: %w", err)
+	}
+	defer c.Close()
+	family, err := genlFamily(c, unix.ETHTOOL_GENL_NAME)
+	if err != nil {
+		return 0, err
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	ae.Nested(unix.ETHTOOL_A_LINKMODES_HEADER, func(nae *netlink.AttributeEncoder) error {
+		nae.String(unix.ETHTOOL_A_HEADER_DEV_NAME, ifname)
+		return nil
+	})
+	msgs, err := genlExecute(c, family, unix.ETHTOOL_MSG_LINKMODES_GET, unix.ETHTOOL_GENL_VERSION, ae)
+	if err != nil {
+		return 0, fmt.Errorf("ifname: %v, ethtool linkmodes: %w", ifname, err)
+	}
+	for _, m := range msgs {
+		ad, err := netlink.NewAttributeDecoder(m.Data[unix.GENL_HDRLEN:])
+		if err != nil {
+			return 0, fmt.Errorf("ifname: %v, decode linkmodes: %w", ifname, err)
+		}
+		for ad.Next() {
+			if ad.Type() == unix.ETHTOOL_A_LINKMODES_SPEED {
+				speed := ad.Uint32()
+				if int32(speed) == unix.SPEED_UNKNOWN {
+					return 0, nil
+				}
+				return uint64(speed), nil
+			}
+		}
+		if err := ad.Err(); err != nil {
+			return 0, fmt.Errorf("ifname: %v, decode linkmodes: %w", ifname, err)
+		}
+	}
+	return 0, fmt.Errorf("ifname: %v, ethtool linkmodes: %w", ifname, ErrNoAttribute)
+}
+
+// genlFamily resolves the id of the generic netlink family name.
+func genlFamily(c *netlink.Conn, name string) (uint16, error) {
+	ae := netlink.NewAttributeEncoder()
+	ae.String(unix.CTRL_ATTR_FAMILY_NAME, name)
+	msgs, err := genlExecute(c, unix.GENL_ID_CTRL, unix.CTRL_CMD_GETFAMILY, 1, ae)
+	if err != nil {
+		return 0, fmt.Errorf("resolve genetlink family %s: %w", name, err)
+	}
+	for _, m := range msgs {
+		ad, err := netlink.NewAttributeDecoder(m.Data[unix.GENL_HDRLEN:])
+		if err != nil {
+			return 0, fmt.Errorf("decode genetlink family: %w", err)
+		}
+		for ad.Next() {
+			if ad.Type() == unix.CTRL_ATTR_FAMILY_ID {
+				return ad.Uint16(), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("resolve genetlink family %s: %w", name, ErrNoAttribute)
+}
+
+// genlExecute sends a generic netlink request and returns the replies,
+// which all carry at least the genetlink header.
+func genlExecute(c *netlink.Conn, family uint16, cmd, version uint8, ae *netlink.AttributeEncoder) ([]netlink.Message, error) {
+	attrs, err := ae.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("encode attributes: %w", err)
+	}
+	req := netlink.Message{
+		Header: netlink.Header{Type: netlink.HeaderType(family), Flags: netlink.Request},
+		Data:   append([]byte{cmd, version, 0, 0}, attrs...),
+	}
+	msgs, err := c.Execute(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range msgs {
+		if len(m.Data) < unix.GENL_HDRLEN {
+			return nil, errors.New("short genetlink message")
+		}
+	}
+	return msgs, nil
+}
+
+// drvinfo returns the driver and firmware version of ifname from the
+// ETHTOOL_GDRVINFO ioctl, falling back to the sysfs driver link.
+func drvinfo(ifname string) (driver, firmware string) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return Driver(ifname), ""
+	}
+	defer unix.Close(fd)
+	info, err := unix.IoctlGetEthtoolDrvinfo(fd, ifname)
+	if err != nil {
+		return Driver(ifname), ""
+	}
+	firmware = unix.ByteSliceToString(info.Fw_version[:])
+	if firmware == "N/A" {
+		firmware = ""
+	}
+	return unix.ByteSliceToString(info.Driver[:]), firmware
+}
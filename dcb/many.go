@@ -0,0 +1,77 @@
+package dcb
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultConcurrency is the number of queries GetMany keeps in flight when
+// no positive limit is given.
+const DefaultConcurrency = 8
+
+// Result is the outcome of querying a single interface in GetMany.
+type Result struct {
+	Ifname string
+	PFC    *IEEEPFC
+	Err    error
+}
+
+// GetMany dials a Client with one socket per worker and queries the PFC
+// managed object of every interface in ifnames with it. See Client.GetMany.
+func GetMany(ifnames []string, concurrency int) ([]Result, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > len(ifnames) {
+		concurrency = len(ifnames)
+	}
+
+	cl, err := Dial(&Config{PoolSize: concurrency})
+	if err != nil {
+		return nil, err
+	}
+	defer cl.Close()
+
+	return cl.GetMany(ifnames, concurrency)
+}
+
+// GetMany queries the PFC managed object of every interface in ifnames using
+// a pool of at most concurrency workers sharing the sockets of the Client.
+//
+// The results are returned in the order of ifnames. A failing interface does
+// not stop the others; instead its Result carries the error and the returned
+// error joins one *IfaceError per failed interface.
+func (cl *Client) GetMany(ifnames []string, concurrency int) ([]Result, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]Result, len(ifnames))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(ifnames); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Ifname = ifnames[i]
+				results[i].PFC, results[i].Err = cl.GetPFC(ifnames[i])
+			}
+		}()
+	}
+
+	for i := range ifnames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, &IfaceError{Ifname: r.Ifname, Err: r.Err})
+		}
+	}
+	return results, errors.Join(errs...)
+}
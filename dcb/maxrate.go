@@ -0,0 +1,64 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L78
+type IEEEMaxrate struct { // struct ieee_maxrate
+	TCMaxrate [IEEE_8021QAZ_MAX_TCS]uint64 // tc tx rate limit in kbps, 0 is unlimited
+}
+
+const ieeeMaxrateLen = sizeofIeeeMaxrate
+
+func parseIEEEMaxrate(b []byte) (*IEEEMaxrate, error) {
+	if len(b) < ieeeMaxrateLen {
+		return nil, fmt.Errorf("invalid struct ieee_maxrate length %d", len(b))
+	}
+	m := &IEEEMaxrate{}
+	for i := range m.TCMaxrate {
+		m.TCMaxrate[i] = binary.NativeEndian.Uint64(b[i*8 : i*8+8])
+	}
+	return m, nil
+}
+
+func (m *IEEEMaxrate) marshal() []byte {
+	b := make([]byte, ieeeMaxrateLen)
+	for i, rate := range m.TCMaxrate {
+		binary.NativeEndian.PutUint64(b[i*8:i*8+8], rate)
+	}
+	return b
+}
+
+// GetMaxrate returns the per traffic class rate limits of ifname.
+func (cl *Client) GetMaxrate(ifname string) (*IEEEMaxrate, error) {
+	var m *IEEEMaxrate
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.Maxrate == nil {
+			return fmt.Errorf("ifname: %v, get ieee maxrate: %w", ifname, ErrNoAttribute)
+		}
+		m = cfg.Maxrate
+		return nil
+	})
+	return m, err
+}
+
+// SetMaxrate configures the per traffic class rate limits of ifname.
+func (cl *Client) SetMaxrate(ifname string, m *IEEEMaxrate) error {
+	return cl.do(func(c *conn) error {
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(DCB_ATTR_IEEE_MAXRATE, m.marshal())
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, ObjectMaxrate, m, func(cfg *ieeeConfig) any { return cfg.Maxrate })
+	})
+}
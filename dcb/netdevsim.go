@@ -0,0 +1,128 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// netdevsimBus is the sysfs directory of the netdevsim bus, present once
+// the netdevsim module is loaded.
+const netdevsimBus = "/sys/bus/netdevsim"
+
+// netdevsimSettle bounds how long NewNetdevSim waits for the interfaces of
+// a new device to appear.
+const netdevsimSettle = 5 * time.Second
+
+// A NetdevSim is a device of netdevsim, the simulated network device of
+// the kernel, for exercising the package and programs built on it without
+// DCB hardware, such as in the tests of a program or to validate a new
+// kernel. Kernels whose netdevsim has no dcbnl ops report its interfaces as
+// not supporting DCB, as dummy ones.
+type NetdevSim struct {
+	// ID is the device ID on the netdevsim bus.
+	ID int
+	// Ifnames are the interfaces of its ports.
+	Ifnames []string
+}
+
+// NewNetdevSim creates the netdevsim device id with ports ports and waits
+// for their interfaces. It needs the netdevsim module loaded and
+// CAP_SYS_ADMIN; the error wraps os.ErrNotExist if the module is not.
+// The device must be deleted with Close.
+func NewNetdevSim(id, ports int) (*NetdevSim, error) {
+	if ports <= 0 {
+		return nil, fmt.Errorf("netdevsim: invalid port count %d", ports)
+	}
+	if _, err := os.Stat(netdevsimBus); err != nil {
+		return nil, fmt.Errorf("netdevsim not loaded, modprobe netdevsim: %w", err)
+	}
+	spec := fmt.Sprintf("%d %d", id, ports)
+	if err := os.WriteFile(filepath.Join(netdevsimBus, "new_device"), []byte(spec), 0); err != nil {
+		return nil, fmt.Errorf("create netdevsim device %d: %w", id, err)
+	}
+	d := &NetdevSim{ID: id}
+	netDir := filepath.Join(netdevsimBus, "devices", "netdevsim"+strconv.Itoa(id), "net")
+	for deadline := time.Now().Add(netdevsimSettle); ; {
+		entries, _ := os.ReadDir(netDir)
+		if len(entries) >= ports {
+			for _, e := range entries {
+				d.Ifnames = append(d.Ifnames, e.Name())
+			}
+			slices.Sort(d.Ifnames)
+			return d, nil
+		}
+		if time.Now().After(deadline) {
+			d.Close()
+			return nil, fmt.Errorf("netdevsim device %d: %d of %d interfaces after %v", id, len(entries), ports, netdevsimSettle)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Close deletes the device along with its interfaces.
+func (d *NetdevSim) Close() error {
+	err := os.WriteFile(filepath.Join(netdevsimBus, "del_device"), []byte(strconv.Itoa(d.ID)), 0)
+	if err != nil {
+		return fmt.Errorf("delete netdevsim device %d: %w", d.ID, err)
+	}
+	return nil
+}
+
+// SelfTestEvents checks the set, get and event pipeline on ifname: with
+// Watch running, it changes the PFC config, expects a PFCChanged event for
+// it within timeout and restores the config. Like SelfTest, it is meant
+// for test devices such as those of a NetdevSim.
+func (cl *Client) SelfTestEvents(ifname string, timeout time.Duration) (c Check) {
+	c = Check{Name: "pfc event", Status: CheckPass}
+	orig, err := cl.GetPFC(ifname)
+	if err != nil {
+		c.Status, c.Detail = CheckFail, err.Error()
+		if errors.Is(err, ErrNoAttribute) || errors.Is(err, syscall.EOPNOTSUPP) {
+			c.Status, c.Detail = CheckSkip, "pfc not implemented by the driver"
+		}
+		return c
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	events, err := cl.Watch(ctx)
+	if err != nil {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("watch: %v", err)
+		return c
+	}
+	// drained so Watch, which must be done before the Client is closed,
+	// returns
+	defer func() {
+		cancel()
+		for range events {
+		}
+	}()
+
+	want := *orig
+	want.PFCEn ^= 1 << 7
+	start := time.Now()
+	if err := cl.SetPFC(ifname, &want); err != nil {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("set: %v", err)
+		return c
+	}
+	defer func() {
+		if err := cl.SetPFC(ifname, orig); err != nil {
+			c.Status, c.Detail = CheckFail, fmt.Sprintf("restore: %v", err)
+		}
+	}()
+	for ev := range events {
+		if ev, ok := ev.(*PFCChanged); ok && ev.Ifname == ifname && ev.New.PFCEn == want.PFCEn {
+			c.Detail = fmt.Sprintf("event after %v", time.Since(start).Round(time.Millisecond))
+			return c
+		}
+	}
+	c.Status, c.Detail = CheckFail, fmt.Sprintf("no event within %v", timeout)
+	return c
+}
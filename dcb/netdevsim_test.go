@@ -0,0 +1,102 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newTestNetdevSim creates a netdevsim device with ports ports for the
+// test and deletes it when the test ends. The test is skipped when the
+// netdevsim module is not loaded or the process may not create devices.
+func newTestNetdevSim(t *testing.T, ports int) *NetdevSim {
+	t.Helper()
+	if _, err := os.Stat(netdevsimBus); err != nil {
+		t.Skip("netdevsim not loaded")
+	}
+	// an ID per process, so packages tested in parallel do not collide
+	d, err := NewNetdevSim(4242+os.Getpid()%10000, ports)
+	if errors.Is(err, os.ErrPermission) {
+		t.Skip("no privileges to create netdevsim devices")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := d.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return d
+}
+
+// skipUnsupported skips the test when err means the process lacks
+// CAP_NET_ADMIN or the driver lacks dcbnl support for the object.
+func skipUnsupported(t *testing.T, err error) {
+	t.Helper()
+	switch {
+	case errors.Is(err, syscall.EPERM):
+		t.Skip("no CAP_NET_ADMIN")
+	case errors.Is(err, syscall.EOPNOTSUPP), errors.Is(err, ErrNoAttribute):
+		t.Skipf("netdevsim of this kernel has no dcbnl pfc: %v", err)
+	}
+}
+
+func TestNetdevSimPFCEvent(t *testing.T) {
+	ifname := newTestNetdevSim(t, 1).Ifnames[0]
+	cl, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	orig, err := cl.GetPFC(ifname)
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	events, err := cl.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// drained so Watch returns before the Client is closed
+	defer func() {
+		cancel()
+		for range events {
+		}
+	}()
+
+	want := *orig
+	want.PFCEn ^= 1 << 3
+	err = cl.SetPFC(ifname, &want)
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cl.SetPFC(ifname, orig); err != nil {
+			t.Errorf("restore: %v", err)
+		}
+	}()
+
+	got, err := cl.GetPFC(ifname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PFCEn != want.PFCEn {
+		t.Fatalf("get after set: pfc_en %#02x, want %#02x", got.PFCEn, want.PFCEn)
+	}
+
+	for ev := range events {
+		if ev, ok := ev.(*PFCChanged); ok && ev.Ifname == ifname && ev.New.PFCEn == want.PFCEn {
+			return
+		}
+	}
+	t.Errorf("no pfc event of %s within the timeout", ifname)
+}
@@ -0,0 +1,49 @@
+package dcb
+
+import (
+	"errors"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// dialNetlink dials a netlink socket of family.
+func dialNetlink(family int, config *netlink.Config) (*netlink.Conn, error) {
+	return netlink.Dial(family, config)
+}
+
+// setBuffers sets the kernel buffer sizes of c, those not positive are left
+// as is. SO_RCVBUFFORCE and SO_SNDBUFFORCE exceed the sysctl limits but
+// need CAP_NET_ADMIN; without it the sizes are capped to the limits.
+func setBuffers(c *netlink.Conn, read, write int) error {
+	if read <= 0 && write <= 0 {
+		return nil
+	}
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var opErr error
+	err = rc.Control(func(fd uintptr) {
+		for _, b := range []struct{ force, capped, size int }{
+			{unix.SO_RCVBUFFORCE, unix.SO_RCVBUF, read},
+			{unix.SO_SNDBUFFORCE, unix.SO_SNDBUF, write},
+		} {
+			if b.size <= 0 {
+				continue
+			}
+			err := unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, b.force, b.size)
+			if errors.Is(err, unix.EPERM) {
+				err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, b.capped, b.size)
+			}
+			if err != nil {
+				opErr = err
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return opErr
+}
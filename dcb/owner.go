@@ -0,0 +1,94 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// An Owner is the agent in charge of the DCB config of an interface.
+type Owner string
+
+const (
+	// OwnerHost means the config is left to the host, as by this package.
+	OwnerHost Owner = "host"
+	// OwnerLLDPAD means lldpad runs and negotiates the config, reverting
+	// local changes with the next DCBX exchange.
+	OwnerLLDPAD Owner = "lldpad"
+	// OwnerFirmware means the NIC runs DCBX in firmware, which reverts or
+	// refuses local changes.
+	OwnerFirmware Owner = "firmware"
+)
+
+// An OwnerError is returned when a change is refused because another
+// agent owns the config.
+type OwnerError struct {
+	Ifname string
+	Owner  Owner
+}
+
+func (e *OwnerError) Error() string {
+	switch e.Owner {
+	case OwnerLLDPAD:
+		return fmt.Sprintf("ifname: %v, lldpad is running and would revert the change, stop it or configure it with lldptool", e.Ifname)
+	case OwnerFirmware:
+		return fmt.Sprintf("ifname: %v, dcbx is managed by the nic firmware, which would revert the change, switch the dcbx mode to host first", e.Ifname)
+	}
+	return fmt.Sprintf("ifname: %v, config owned by %s", e.Ifname, e.Owner)
+}
+
+// Owner returns the agent in charge of the config of ifname. Drivers
+// without a DCBX mode are assumed host managed.
+func (cl *Client) Owner(ifname string) (Owner, error) {
+	running, err := lldpadRunning()
+	if err != nil {
+		return "", err
+	}
+	if running {
+		return OwnerLLDPAD, nil
+	}
+	mode, err := cl.GetDCBX(ifname)
+	if err != nil {
+		if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, ErrNoAttribute) {
+			return OwnerHost, nil
+		}
+		return "", err
+	}
+	if mode&DCB_CAP_DCBX_LLD_MANAGED != 0 && mode&DCB_CAP_DCBX_HOST == 0 {
+		return OwnerFirmware, nil
+	}
+	return OwnerHost, nil
+}
+
+// CheckOwner returns an *OwnerError if an agent other than the host owns
+// the config of ifname.
+func (cl *Client) CheckOwner(ifname string) error {
+	owner, err := cl.Owner(ifname)
+	if err != nil {
+		return err
+	}
+	if owner != OwnerHost {
+		return &OwnerError{Ifname: ifname, Owner: owner}
+	}
+	return nil
+}
+
+// lldpadRunning reports whether an lldpad process runs on the host, or in
+// the pid namespace of the caller.
+func lldpadRunning() (bool, error) {
+	comms, err := filepath.Glob("/proc/[0-9]*/comm")
+	if err != nil {
+		return false, fmt.Errorf("list processes: %w", err)
+	}
+	for _, p := range comms {
+		// processes may exit while being listed
+		b, err := os.ReadFile(p)
+		if err == nil && strings.TrimSpace(string(b)) == "lldpad" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
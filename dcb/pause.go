@@ -0,0 +1,44 @@
+package dcb
+
+import "time"
+
+// PauseQuantumBits is the unit of the pause time a PFC frame requests, in
+// bit times.
+const PauseQuantumBits = 512
+
+// DefaultPauseQuanta is the pause time PauseTime counts per PFC frame when
+// the sender's is unknown: half of the 0xffff quanta senders commonly
+// request, as they send the next XOFF before the previous one expires.
+const DefaultPauseQuanta = 0xffff / 2
+
+// PauseTime estimates how long a priority was paused over interval by
+// frames PFC frames, each holding it for quanta pause quanta on a link of
+// speedMbps Mb/s, which maps PFC counters to throughput lost. It is an
+// upper bound, as the XON frames ending pauses early are counted alike,
+// capped to interval; 0 for an unknown speed.
+func PauseTime(frames uint64, quanta uint16, speedMbps uint64, interval time.Duration) time.Duration {
+	if speedMbps == 0 {
+		return 0
+	}
+	// as BitTime, in floats since the bits may overflow
+	d := time.Duration(float64(frames) * float64(quanta) * PauseQuantumBits * 1e3 / float64(speedMbps))
+	return min(d, interval)
+}
+
+// PauseTimes returns PauseTime per priority for the frames sent, by which
+// the link partner was paused, and received, by which this end was, between
+// the counters of prev and cur read interval apart. Counters that went
+// backwards, as after a driver reload, count from zero.
+func PauseTimes(prev, cur *IEEEPFC, quanta uint16, speedMbps uint64, interval time.Duration) (sent, received [IEEE_8021QAZ_MAX_TCS]time.Duration) {
+	delta := func(old, now uint64) uint64 {
+		if now < old {
+			return now
+		}
+		return now - old
+	}
+	for prio := range cur.Requests {
+		sent[prio] = PauseTime(delta(prev.Requests[prio], cur.Requests[prio]), quanta, speedMbps, interval)
+		received[prio] = PauseTime(delta(prev.Indications[prio], cur.Indications[prio]), quanta, speedMbps, interval)
+	}
+	return sent, received
+}
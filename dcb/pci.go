@@ -0,0 +1,107 @@
+package dcb
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pciAddr matches a PCI address in domain:bus:device.function form.
+var pciAddr = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// PCIAddress returns the PCI address of the device behind ifname, such as
+// 0000:3b:00.1, or "" for virtual interfaces. Devices on a bus below PCI,
+// as virtio, report the PCI function they sit on.
+func PCIAddress(ifname string) string {
+	dev, err := filepath.EvalSymlinks(sysClassNet + "/" + ifname + "/device")
+	if err != nil {
+		return ""
+	}
+	for ; dev != "/" && dev != "."; dev = filepath.Dir(dev) {
+		if base := filepath.Base(dev); pciAddr.MatchString(base) {
+			return base
+		}
+	}
+	return ""
+}
+
+// PhysPortID returns the phys_port_id of ifname, which the netdevs of one
+// physical port share, or "" if the driver does not report one.
+func PhysPortID(ifname string) string {
+	b, err := os.ReadFile(sysClassNet + "/" + ifname + "/phys_port_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// PhysSwitchID returns the phys_switch_id of ifname, which the ports of one
+// switch ASIC or eswitch share, or "" if the driver does not report one.
+func PhysSwitchID(ifname string) string {
+	b, err := os.ReadFile(sysClassNet + "/" + ifname + "/phys_switch_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// Adapter returns an identifier of the physical adapter of ifname: its PCI
+// address without the function, which the ports of a multi-function NIC
+// share, or "" for virtual interfaces.
+func Adapter(ifname string) string {
+	addr := PCIAddress(ifname)
+	if addr == "" {
+		return ""
+	}
+	return addr[:strings.LastIndexByte(addr, '.')]
+}
+
+// InterfacesByPCI returns the interfaces of the host whose PCI address
+// matches the shell pattern pattern, such as 0000:3b:00.*, in the order of
+// their index.
+func InterfacesByPCI(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pci pattern %q: %w", pattern, err)
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("list interfaces: %w", err)
+	}
+	var ifnames []string
+	for _, iface := range ifaces {
+		addr := PCIAddress(iface.Name)
+		if ok, _ := filepath.Match(pattern, addr); ok && addr != "" {
+			ifnames = append(ifnames, iface.Name)
+		}
+	}
+	return ifnames, nil
+}
+
+// SiblingPorts returns the ports of the adapter of ifname, ifname included,
+// in the order of their index: the interfaces on the same PCI device or
+// with the same phys_switch_id, which many NICs share buffers and DCBX
+// state across. SR-IOV virtual functions are not ports and left out. A
+// virtual interface has no siblings.
+func SiblingPorts(ifname string) ([]string, error) {
+	adapter, switchID := Adapter(ifname), PhysSwitchID(ifname)
+	if adapter == "" && switchID == "" {
+		return []string{ifname}, nil
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("list interfaces: %w", err)
+	}
+	var ifnames []string
+	for _, iface := range ifaces {
+		if iface.Name != ifname && IsVF(iface.Name) {
+			continue
+		}
+		if (adapter != "" && Adapter(iface.Name) == adapter) || (switchID != "" && PhysSwitchID(iface.Name) == switchID) {
+			ifnames = append(ifnames, iface.Name)
+		}
+	}
+	return ifnames, nil
+}
@@ -0,0 +1,29 @@
+package dcb
+
+// Peer is the config the link partner advertised in its last DCBX
+// exchange. Drivers report it only when DCBX runs in the firmware or an
+// agent such as lldpad feeds it back; objects not advertised are nil.
+type Peer struct {
+	ETS *IEEEETS `json:"ets,omitempty"`
+	// PFC of the peer; the counters are not reported.
+	PFC *IEEEPFC `json:"pfc,omitempty"`
+	// Apps is the APP table of the peer, see App.Known to name them.
+	Apps []App `json:"apps,omitempty"`
+}
+
+// GetPeer returns the IEEE config advertised by the link partner of ifname.
+func (cl *Client) GetPeer(ifname string) (*Peer, error) {
+	var peer Peer
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		peer = cfg.Peer
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &peer, nil
+}
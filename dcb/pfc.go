@@ -0,0 +1,88 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L157
+type IEEEPFC struct { // struct ieee_pfc
+	PFCCap      uint8  `diff:"ro"` // number of traffic classes that may have pfc enabled at once
+	PFCEn       uint8  // bit n set when pfc is enabled on priority n
+	MBC         uint8  // macsec bypass capability, 1 when the station cannot bypass macsec processing while macsec is disabled
+	Delay       uint16 // allowance for the round-trip propagation delay of the link, in bit times
+	_pad        [3]uint8
+	Requests    [IEEE_8021QAZ_MAX_TCS]uint64 `diff:"-" json:",omitzero"` // count of the sent pfc frames
+	Indications [IEEE_8021QAZ_MAX_TCS]uint64 `diff:"-" json:",omitzero"` // count of the received pfc frames
+}
+
+// ieeePFCLen is sizeof(struct ieee_pfc): delay is 2-byte aligned after mbc
+// and the counters are 8-byte aligned after delay.
+const ieeePFCLen = sizeofIeeePfc
+
+// parseIEEEPFC decodes a struct ieee_pfc, which the kernel sends in host
+// byte order.
+func parseIEEEPFC(b []byte) (*IEEEPFC, error) {
+	if len(b) < ieeePFCLen {
+		return nil, fmt.Errorf("invalid struct ieee_pfc length %d", len(b))
+	}
+
+	p := &IEEEPFC{
+		PFCCap: b[0],
+		PFCEn:  b[1],
+		MBC:    b[2],
+		Delay:  binary.NativeEndian.Uint16(b[offIeeePfcDelay:]),
+	}
+
+	off := offIeeePfcRequests
+	for i := 0; i < IEEE_8021QAZ_MAX_TCS; i++ {
+		p.Requests[i] = binary.NativeEndian.Uint64(b[off : off+8])
+		off += 8
+	}
+	for i := 0; i < IEEE_8021QAZ_MAX_TCS; i++ {
+		p.Indications[i] = binary.NativeEndian.Uint64(b[off : off+8])
+		off += 8
+	}
+
+	return p, nil
+}
+
+// Enabled returns the priorities PFC is enabled on, in ascending order.
+func (p *IEEEPFC) Enabled() []uint8 {
+	var prios []uint8
+	for prio := uint8(0); prio < IEEE_8021QAZ_MAX_TCS; prio++ {
+		if p.PFCEn&(1<<prio) != 0 {
+			prios = append(prios, prio)
+		}
+	}
+	return prios
+}
+
+// Validate checks that p enables no more priorities than PFCCap allows, if
+// the driver reported a limit, and that MBC is a flag.
+func (p *IEEEPFC) Validate() error {
+	if n := len(p.Enabled()); p.PFCCap != 0 && n > int(p.PFCCap) {
+		return fmt.Errorf("pfc enabled on %d priorities, pfc_cap allows %d", n, p.PFCCap)
+	}
+	if p.MBC > 1 {
+		return fmt.Errorf("invalid mbc %d, want 0 or 1", p.MBC)
+	}
+	return nil
+}
+
+func (p *IEEEPFC) marshal() []byte {
+	b := make([]byte, ieeePFCLen)
+	b[0], b[1], b[2] = p.PFCCap, p.PFCEn, p.MBC
+	binary.NativeEndian.PutUint16(b[offIeeePfcDelay:], p.Delay)
+
+	off := offIeeePfcRequests
+	for i := 0; i < IEEE_8021QAZ_MAX_TCS; i++ {
+		binary.NativeEndian.PutUint64(b[off:off+8], p.Requests[i])
+		off += 8
+	}
+	for i := 0; i < IEEE_8021QAZ_MAX_TCS; i++ {
+		binary.NativeEndian.PutUint64(b[off:off+8], p.Indications[i])
+		off += 8
+	}
+	return b
+}
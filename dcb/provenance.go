@@ -0,0 +1,109 @@
+package dcb
+
+import "slices"
+
+// A Source tells where a setting of a Snapshot came from.
+type Source string
+
+const (
+	// SourceLocal settings were configured on this end of the link, by the
+	// host, lldpad or the firmware.
+	SourceLocal Source = "local"
+	// SourceDCBX settings were learned from the link partner: the port is
+	// willing and runs what the peer advertised.
+	SourceDCBX Source = "dcbx"
+	// SourceUnknown settings may be either: the port is willing, but the
+	// driver reports no advertisement of the peer to compare with.
+	SourceUnknown Source = "unknown"
+)
+
+// Provenance holds where the objects of a Snapshot came from, so audits
+// can tell the settings a switch pushed from those of the host.
+type Provenance struct {
+	// Owner is the agent in charge of the config of the interface.
+	Owner Owner `json:"owner"`
+	// Objects holds the source of the objects the snapshot has.
+	Objects map[Object]Source `json:"objects"`
+	// Apps holds the source of each APP entry, in the order of
+	// Snapshot.Apps.
+	Apps []Source `json:"apps,omitempty"`
+}
+
+// Provenance returns where the objects of s, a snapshot of ifname, came
+// from, comparing them with what the link partner advertised.
+func (cl *Client) Provenance(ifname string, s *Snapshot) (*Provenance, error) {
+	owner, err := cl.Owner(ifname)
+	if err != nil {
+		return nil, err
+	}
+	peer, err := cl.GetPeer(ifname)
+	if err != nil {
+		return nil, err
+	}
+	p := ProvenanceOf(s, peer)
+	p.Owner = owner
+	return p, nil
+}
+
+// ProvenanceOf returns where the objects of s came from given peer, the
+// advertisement of the link partner, nil if unknown. Only ETS, PFC and APP
+// are exchanged by DCBX, and only adopted by a willing port; dcbnl reports
+// the willing bit in ieee_ets alone, which drivers apply to the whole
+// port. A willing port running exactly what the peer advertised learned it
+// from the peer; maxrate, buffer, trust and the DCBX mode are always
+// local.
+func ProvenanceOf(s *Snapshot, peer *Peer) *Provenance {
+	if peer == nil {
+		peer = &Peer{}
+	}
+	willing := s.ETS != nil && s.ETS.Willing != 0
+	p := &Provenance{Owner: OwnerHost, Objects: map[Object]Source{}}
+	learned := func(advertised, matches bool) Source {
+		switch {
+		case !willing:
+			return SourceLocal
+		case !advertised:
+			return SourceUnknown
+		case matches:
+			return SourceDCBX
+		}
+		return SourceLocal
+	}
+	if s.ETS != nil {
+		p.Objects[ObjectETS] = learned(peer.ETS != nil, peer.ETS != nil && etsAdopted(s.ETS, peer.ETS))
+	}
+	if s.PFC != nil {
+		p.Objects[ObjectPFC] = learned(peer.PFC != nil, peer.PFC != nil && s.PFC.PFCEn == peer.PFC.PFCEn)
+	}
+	if s.Apps != nil {
+		p.Objects[ObjectApp] = SourceLocal
+		p.Apps = make([]Source, len(s.Apps))
+		for i, a := range s.Apps {
+			p.Apps[i] = learned(peer.Apps != nil, slices.Contains(peer.Apps, a))
+			if p.Apps[i] != SourceLocal {
+				// the table holds learned entries
+				p.Objects[ObjectApp] = p.Apps[i]
+			}
+		}
+	}
+	for obj, set := range map[Object]bool{
+		ObjectMaxrate: s.Maxrate != nil,
+		ObjectBuffer:  s.Buffer != nil,
+		ObjectTrust:   s.Trust != nil,
+		ObjectDCBX:    s.DCBX != nil,
+	} {
+		if set {
+			p.Objects[obj] = SourceLocal
+		}
+	}
+	return p
+}
+
+// etsAdopted reports whether ets runs the config peer advertised, its
+// configuration or its recommendation.
+func etsAdopted(ets, peer *IEEEETS) bool {
+	if ets.TCTxBW == peer.TCTxBW && ets.TCTSA == peer.TCTSA && ets.PrioTC == peer.PrioTC {
+		return true
+	}
+	return ets.TCTxBW == peer.TCRecoBW && ets.TCTSA == peer.TCRecoTSA && ets.PrioTC == peer.RecoPrioTC
+}
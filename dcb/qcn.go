@@ -0,0 +1,62 @@
+package dcb
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L141
+type IEEEQCNStats struct { // struct ieee_qcn_stats
+	RPPPRPCentiseconds [IEEE_8021QAZ_MAX_TCS]uint64 // time a reaction point was active on the tc
+	RPPPCreatedRPs     [IEEE_8021QAZ_MAX_TCS]uint32 // count of the reaction points created on the tc
+}
+
+const ieeeQCNStatsLen = sizeofIeeeQcnStats
+
+func parseIEEEQCNStats(b []byte) (*IEEEQCNStats, error) {
+	if len(b) < ieeeQCNStatsLen {
+		return nil, fmt.Errorf("invalid struct ieee_qcn_stats length %d", len(b))
+	}
+	s := &IEEEQCNStats{}
+	for i := range s.RPPPRPCentiseconds {
+		off := offIeeeQcnStatsRpppRpCentiseconds + i*8
+		s.RPPPRPCentiseconds[i] = binary.NativeEndian.Uint64(b[off : off+8])
+	}
+	for i := range s.RPPPCreatedRPs {
+		off := offIeeeQcnStatsRpppCreatedRps + i*4
+		s.RPPPCreatedRPs[i] = binary.NativeEndian.Uint32(b[off : off+4])
+	}
+	return s, nil
+}
+
+func (s *IEEEQCNStats) marshal() []byte {
+	b := make([]byte, ieeeQCNStatsLen)
+	for i, v := range s.RPPPRPCentiseconds {
+		off := offIeeeQcnStatsRpppRpCentiseconds + i*8
+		binary.NativeEndian.PutUint64(b[off:off+8], v)
+	}
+	for i, v := range s.RPPPCreatedRPs {
+		off := offIeeeQcnStatsRpppCreatedRps + i*4
+		binary.NativeEndian.PutUint32(b[off:off+4], v)
+	}
+	return b
+}
+
+// GetQCNStats returns the IEEE 802.1Qau congestion notification counters of
+// ifname. They are reported apart from the DCB configuration, and only by
+// drivers implementing QCN, mlx4 among the upstream ones.
+func (cl *Client) GetQCNStats(ifname string) (*IEEEQCNStats, error) {
+	var s *IEEEQCNStats
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		if cfg.QCNStats == nil {
+			return fmt.Errorf("ifname: %v, get ieee qcn stats: %w", ifname, ErrNoAttribute)
+		}
+		s = cfg.QCNStats
+		return nil
+	})
+	return s, err
+}
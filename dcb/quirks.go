@@ -0,0 +1,92 @@
+package dcb
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// driverQuirks describes how the set operations of a multi-object apply
+// must be arranged for a driver to accept them.
+type driverQuirks struct {
+	// order is the order objects are set in. Objects it leaves out are set
+	// after the listed ones, in defaultOrder.
+	order []Object
+	// unwilling clears the willing bit of ETS in a set of its own before
+	// the rest, for drivers that keep following the peer and ignore or
+	// refuse local changes while willing.
+	unwilling bool
+}
+
+// defaultOrder sets the DCBX mode first since it decides whether the host
+// may change the rest, and replaces the APP table last.
+var defaultOrder = []Object{ObjectDCBX, ObjectTrust, ObjectETS, ObjectMaxrate, ObjectPFC, ObjectBuffer, ObjectApp}
+
+// quirks is keyed by the name of the kernel driver.
+var quirks = map[string]driverQuirks{
+	// mlx5 sizes the port buffers from the PFC config; setting the buffer
+	// after PFC makes the firmware redistribute it once more and may drop
+	// the requested split.
+	"mlx5_core": {order: []Object{ObjectDCBX, ObjectTrust, ObjectBuffer, ObjectETS, ObjectMaxrate, ObjectPFC, ObjectApp}},
+	// ice and i40e refuse changes while the firmware LLDP agent owns the
+	// config, which the DCBX mode hands over, and then apply ETS before
+	// enabling PFC on its TCs.
+	"ice":  {order: []Object{ObjectDCBX, ObjectETS, ObjectPFC, ObjectApp, ObjectTrust}, unwilling: true},
+	"i40e": {order: []Object{ObjectDCBX, ObjectETS, ObjectPFC, ObjectApp}, unwilling: true},
+	// bnxt_en maps PFC priorities through the TCs of the current ETS
+	// config, so ETS must be in place first.
+	"bnxt_en": {order: []Object{ObjectDCBX, ObjectETS, ObjectPFC, ObjectApp}, unwilling: true},
+}
+
+// Driver returns the name of the kernel driver of ifname, or "" for
+// virtual interfaces.
+func Driver(ifname string) string {
+	dst, err := os.Readlink(sysClassNet + "/" + ifname + "/device/driver")
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(dst)
+}
+
+// quirksOf returns the quirks for the driver of ifname.
+func quirksOf(ifname string) driverQuirks {
+	return quirks[Driver(ifname)]
+}
+
+// applyOrder returns the objects in the order they are set in for q.
+func (q driverQuirks) applyOrder() []Object {
+	order := append([]Object(nil), q.order...)
+	for _, obj := range defaultOrder {
+		if !slices.Contains(order, obj) {
+			order = append(order, obj)
+		}
+	}
+	return order
+}
+
+// setETSQuirks sets ets, first clearing the willing bit on its own if the
+// driver needs that.
+func (cl *Client) setETSQuirks(ifname string, ets *IEEEETS, q driverQuirks) error {
+	if err := cl.clearWilling(ifname, ets, q); err != nil {
+		return err
+	}
+	return cl.SetETS(ifname, ets)
+}
+
+// clearWilling clears the willing bit of the current ETS config of ifname
+// when q asks for it and ets, about to be set, is not willing.
+func (cl *Client) clearWilling(ifname string, ets *IEEEETS, q driverQuirks) error {
+	if !q.unwilling || ets.Willing != 0 {
+		return nil
+	}
+	have, err := cl.GetETS(ifname)
+	if err != nil {
+		return err
+	}
+	if have.Willing == 0 {
+		return nil
+	}
+	unwilling := *have
+	unwilling.Willing = 0
+	return cl.SetETS(ifname, &unwilling)
+}
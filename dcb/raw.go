@@ -0,0 +1,99 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A RawAttr is a netlink attribute of a dcbnl message as Client.Raw sends
+// and returns them, for attributes the package has no support for yet, such
+// as vendor extensions or those of a newer kernel.
+type RawAttr struct {
+	// Type is the attribute type. Sent attributes carry it as given, with
+	// any NLA_F_NESTED flag the caller sets; the flags are cleared in
+	// replies.
+	Type uint16
+	// Data is the payload. Attributes with Attrs set are sent with those
+	// encoded instead.
+	Data []byte
+	// Attrs are the nested attributes to send. Replies leave them nil, as
+	// a payload cannot be told from nested attributes; decode them with
+	// Nested.
+	Attrs []RawAttr
+}
+
+// Nested decodes the payload of a as nested attributes.
+func (a RawAttr) Nested() ([]RawAttr, error) {
+	ad, err := netlink.NewAttributeDecoder(a.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode attribute %d: %w", a.Type, err)
+	}
+	attrs := decodeRawAttrs(ad)
+	if err := ad.Err(); err != nil {
+		return nil, fmt.Errorf("decode attribute %d: %w", a.Type, err)
+	}
+	return attrs, nil
+}
+
+func decodeRawAttrs(ad *netlink.AttributeDecoder) []RawAttr {
+	var attrs []RawAttr
+	for ad.Next() {
+		attrs = append(attrs, RawAttr{Type: ad.Type(), Data: ad.Bytes()})
+	}
+	return attrs
+}
+
+// encodeRawAttrs adds attrs to ae, encoding nested attributes recursively.
+func encodeRawAttrs(ae *netlink.AttributeEncoder, attrs []RawAttr) error {
+	for _, a := range attrs {
+		if a.Attrs == nil {
+			ae.Bytes(a.Type, a.Data)
+			continue
+		}
+		nae := netlink.NewAttributeEncoder()
+		if err := encodeRawAttrs(nae, a.Attrs); err != nil {
+			return err
+		}
+		b, err := nae.Encode()
+		if err != nil {
+			return fmt.Errorf("attribute %d: %w", a.Type, err)
+		}
+		ae.Bytes(a.Type, b)
+	}
+	return nil
+}
+
+// Raw sends the dcbnl command cmd for ifname with the top-level attributes
+// attrs following DCB_ATTR_IFNAME, and returns the top-level attributes of
+// the replies to it, in order. set sends it as RTM_SETDCB, which the kernel
+// requires CAP_NET_ADMIN for and the set commands use, rather than
+// RTM_GETDCB.
+//
+// Raw is an escape hatch for experimenting with attributes before the
+// package supports them: nothing is validated or verified, and the status
+// a driver reports for a set command is left in the reply attributes.
+func (cl *Client) Raw(cmd Command, ifname string, set bool, attrs []RawAttr) ([]RawAttr, error) {
+	var typ netlink.HeaderType = rtmGetDCB
+	if set {
+		typ = rtmSetDCB
+	}
+	var reply []RawAttr
+	err := cl.do(func(c *conn) error {
+		msgs, err := execute(c, typ, uint8(cmd), ifname, func(ae *netlink.AttributeEncoder) error {
+			return encodeRawAttrs(ae, attrs)
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, raw: %w", ifname, err)
+		}
+		err = replyAttrs(msgs, uint8(cmd), func(ad *netlink.AttributeDecoder) error {
+			reply = append(reply, decodeRawAttrs(ad)...)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ifname: %v, raw %v: %w", ifname, cmd, err)
+		}
+		return nil
+	})
+	return reply, err
+}
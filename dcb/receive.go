@@ -0,0 +1,105 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// ErrDumpInterrupted is returned when the kernel keeps flagging a dump with
+// NLM_F_DUMP_INTR: the objects changed while it was being read, and the
+// retries saw the same.
+var ErrDumpInterrupted = errors.New("dump interrupted by concurrent changes")
+
+// dumpAttempts bounds how often an interrupted dump is read again.
+const dumpAttempts = 3
+
+// roundTrip sends req and returns the replies, passing both to the
+// observers of c. Dump requests flagged NLM_F_DUMP_INTR are sent again.
+func (c *conn) roundTrip(req netlink.Message) ([]netlink.Message, error) {
+	for attempt := 1; ; attempt++ {
+		sent, msgs, err := c.exchange(req)
+		if c.capture != nil {
+			c.capture.record(sent, msgs, err)
+		}
+		if err != nil || req.Header.Flags&netlink.Dump != netlink.Dump || !interrupted(msgs) {
+			return msgs, err
+		}
+		if attempt == dumpAttempts {
+			return nil, fmt.Errorf("%d attempts: %w", attempt, ErrDumpInterrupted)
+		}
+	}
+}
+
+// exchange sends req and reads until its answer is complete: the
+// NLMSG_DONE of a dump, the acknowledgement of a request asking for one, or
+// else the first reply. Messages left over from an earlier request, such as
+// the acknowledgement of a request whose receive failed, carry another
+// sequence number and are dropped; a reply addressed to another port is an
+// error. Acknowledgements are not returned. The request is returned with
+// the sequence number and port the socket filled in.
+func (c *conn) exchange(req netlink.Message) (netlink.Message, []netlink.Message, error) {
+	sent, err := c.Send(req)
+	if err == nil {
+		req = sent
+	}
+	if c.trace != nil {
+		c.trace(req, true)
+	}
+	if err != nil {
+		return req, nil, err
+	}
+
+	dump := req.Header.Flags&netlink.Dump == netlink.Dump
+	wantAck := !dump && req.Header.Flags&netlink.Acknowledge != 0
+	var replies []netlink.Message
+	for {
+		// an NLMSG_ERROR with an errno, whether it answers the request or is
+		// interleaved in a dump, ends the receive with it
+		msgs, err := c.Receive()
+		if err != nil {
+			return req, replies, err
+		}
+		if dump && len(msgs) == 0 {
+			// a dump of no objects is its NLMSG_DONE alone, which Receive
+			// consumes
+			return req, replies, nil
+		}
+		current, acked := false, false
+		for _, m := range msgs {
+			if c.trace != nil {
+				c.trace(m, false)
+			}
+			if m.Header.Sequence != req.Header.Sequence {
+				continue
+			}
+			if m.Header.PID != 0 && m.Header.PID != req.Header.PID {
+				return req, replies, fmt.Errorf("reply for port %d, want %d", m.Header.PID, req.Header.PID)
+			}
+			current = true
+			if m.Header.Type == netlink.Error {
+				acked = true
+				continue
+			}
+			replies = append(replies, m)
+		}
+		switch {
+		case !current:
+			// only stale messages, the answer is still queued
+		case dump, acked, !wantAck && len(replies) > 0:
+			return req, replies, nil
+		}
+	}
+}
+
+// interrupted reports whether the kernel flagged any part of a dump as
+// inconsistent.
+func interrupted(msgs []netlink.Message) bool {
+	for _, m := range msgs {
+		if m.Header.Flags&netlink.DumpInterrupted != 0 {
+			return true
+		}
+	}
+	return false
+}
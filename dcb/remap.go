@@ -0,0 +1,53 @@
+package dcb
+
+import "fmt"
+
+// SwapPriorities returns the objects of s that depend on priorities with
+// the config of priorities a and b exchanged, to move a class of traffic,
+// such as RoCE, to another priority without leaving it unprotected: the
+// PFC enable bits, the traffic classes of the ETS prio_tc map, the buffers
+// of the priority to buffer mapping and the priority of APP entries,
+// DSCP ones included. The objects not holding priorities are nil, so
+// restoring the result leaves them as they are, as it does the objects s
+// lacks.
+func SwapPriorities(s *Snapshot, a, b uint8) (*Snapshot, error) {
+	if a >= IEEE_8021Q_MAX_PRIORITIES || b >= IEEE_8021Q_MAX_PRIORITIES {
+		return nil, fmt.Errorf("invalid priorities %d and %d, want 0 to %d", a, b, IEEE_8021Q_MAX_PRIORITIES-1)
+	}
+	out := &Snapshot{Schema: s.Schema, Ifname: s.Ifname, Time: s.Time}
+	if s.PFC != nil {
+		pfc := *s.PFC
+		ea, eb := pfc.PFCEn&(1<<a) != 0, pfc.PFCEn&(1<<b) != 0
+		pfc.PFCEn &^= 1<<a | 1<<b
+		if ea {
+			pfc.PFCEn |= 1 << b
+		}
+		if eb {
+			pfc.PFCEn |= 1 << a
+		}
+		out.PFC = &pfc
+	}
+	if s.ETS != nil {
+		ets := *s.ETS
+		ets.PrioTC[a], ets.PrioTC[b] = ets.PrioTC[b], ets.PrioTC[a]
+		out.ETS = &ets
+	}
+	if s.Buffer != nil {
+		buf := *s.Buffer
+		buf.Prio2Buffer[a], buf.Prio2Buffer[b] = buf.Prio2Buffer[b], buf.Prio2Buffer[a]
+		out.Buffer = &buf
+	}
+	if s.Apps != nil {
+		out.Apps = make([]App, len(s.Apps))
+		for i, app := range s.Apps {
+			switch app.Priority {
+			case a:
+				app.Priority = b
+			case b:
+				app.Priority = a
+			}
+			out.Apps[i] = app
+		}
+	}
+	return out, nil
+}
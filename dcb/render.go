@@ -0,0 +1,338 @@
+package dcb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// A Renderer formats the DCB state of an interface, as GetAll returns it,
+// for output. The built-in ones are registered as json, yaml, table and
+// prometheus; programs register their own with RegisterRenderer.
+type Renderer interface {
+	Render(cfg *DCBConfig) ([]byte, error)
+}
+
+// RendererFunc adapts a function to a Renderer.
+type RendererFunc func(cfg *DCBConfig) ([]byte, error)
+
+func (f RendererFunc) Render(cfg *DCBConfig) ([]byte, error) { return f(cfg) }
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		"json":       RendererFunc(renderJSON),
+		"yaml":       RendererFunc(renderYAML),
+		"table":      RendererFunc(renderTable),
+		"prometheus": RendererFunc(renderPrometheus),
+	}
+)
+
+// RegisterRenderer registers r as the renderer name, replacing any
+// registered before, built-in ones included. It is safe for concurrent use.
+func RegisterRenderer(name string, r Renderer) {
+	if name == "" || r == nil {
+		panic("dcb: RegisterRenderer with an empty name or nil renderer")
+	}
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[name] = r
+}
+
+// LookupRenderer returns the renderer registered as name.
+func LookupRenderer(name string) (Renderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	r, ok := renderers[name]
+	return r, ok
+}
+
+// Renderers returns the names of the registered renderers, sorted.
+func Renderers() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func renderJSON(cfg *DCBConfig) ([]byte, error) {
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// renderYAML renders the JSON encoding of cfg as block-style YAML, with
+// the keys in the same order.
+func renderYAML(cfg *DCBConfig) ([]byte, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if v, err = decodeOrdered(dec); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	writeYAML(&out, v, 0, false)
+	return out.Bytes(), nil
+}
+
+// A yamlMap is a JSON object with its keys in order.
+type yamlMap struct {
+	keys   []string
+	values []any
+}
+
+// decodeOrdered decodes the next JSON value of dec, objects as yamlMaps.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := &yamlMap{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys, m.values = append(m.keys, k.(string)), append(m.values, v)
+		}
+		_, err := dec.Token()
+		return m, err
+	case json.Delim('['):
+		l := []any{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		_, err := dec.Token()
+		return l, err
+	}
+	return tok, nil
+}
+
+// writeYAML writes v at indent, starting on the current line if inline,
+// as after a "- " of a list.
+func writeYAML(out *bytes.Buffer, v any, indent int, inline bool) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case *yamlMap:
+		if len(v.keys) == 0 {
+			out.WriteString("{}\n")
+			return
+		}
+		for i, k := range v.keys {
+			if i > 0 || !inline {
+				out.WriteString(pad)
+			}
+			out.WriteString(yamlScalar(k) + ":")
+			writeYAMLValue(out, v.values[i], indent+1)
+		}
+	case []any:
+		if len(v) == 0 {
+			out.WriteString("[]\n")
+			return
+		}
+		for i, e := range v {
+			if i > 0 || !inline {
+				out.WriteString(pad)
+			}
+			out.WriteString("- ")
+			if isYAMLCollection(e) {
+				writeYAML(out, e, indent+1, true)
+			} else {
+				out.WriteString(yamlScalar(e) + "\n")
+			}
+		}
+	default:
+		out.WriteString(yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value of a key: scalars, short lists of
+// scalars and empty collections on its line, others nested below it.
+func writeYAMLValue(out *bytes.Buffer, v any, indent int) {
+	if l, ok := v.([]any); ok && len(l) > 0 && !slices.ContainsFunc(l, isYAMLCollection) {
+		items := make([]string, len(l))
+		for i, e := range l {
+			items[i] = yamlScalar(e)
+		}
+		out.WriteString(" [" + strings.Join(items, ", ") + "]\n")
+		return
+	}
+	if isYAMLCollection(v) {
+		out.WriteString("\n")
+		writeYAML(out, v, indent, false)
+		return
+	}
+	out.WriteString(" ")
+	writeYAML(out, v, indent, true)
+}
+
+func isYAMLCollection(v any) bool {
+	switch v := v.(type) {
+	case *yamlMap:
+		return len(v.keys) > 0
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		// quoted where YAML would read another type or syntax
+		if v == "" || strings.ContainsAny(v, ":#{}[],&*!|>'\"%@`\n") || strings.TrimSpace(v) != v ||
+			slices.Contains([]string{"true", "false", "null", "yes", "no", "on", "off", "~"}, strings.ToLower(v)) {
+			return strconv.Quote(v)
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return strconv.Quote(v)
+		}
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// renderTable renders cfg as a summary and a table per priority, as the
+// commands of iproute2's dcb show them.
+func renderTable(cfg *DCBConfig) ([]byte, error) {
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ifname:\t%s\n", cfg.Ifname)
+	if cfg.DCBX != nil {
+		fmt.Fprintf(w, "dcbx:\t%#02x\n", *cfg.DCBX)
+	}
+	if cfg.PFC != nil {
+		fmt.Fprintf(w, "pfc:\ton %v, cap %d, delay %d, mbc %d\n", cfg.PFC.Enabled(), cfg.PFC.PFCCap, cfg.PFC.Delay, cfg.PFC.MBC)
+	}
+	if cfg.ETS != nil {
+		fmt.Fprintf(w, "ets:\twilling %d, cap %d, cbs %d\n", cfg.ETS.Willing, cfg.ETS.ETSCap, cfg.ETS.CBS)
+	}
+	if len(cfg.Trust) > 0 {
+		fmt.Fprintf(w, "trust:\t%v\n", cfg.Trust)
+	}
+	for _, a := range cfg.Apps {
+		fmt.Fprintf(w, "app:\t%v %d prio %d\n", a.Selector, a.Protocol, a.Priority)
+	}
+	w.Flush()
+
+	if cfg.PFC == nil && cfg.ETS == nil && cfg.Maxrate == nil && cfg.Buffer == nil {
+		return out.Bytes(), nil
+	}
+	out.WriteString("\n")
+	w = tabwriter.NewWriter(&out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "prio\tpfc\ttx pause\trx pause\ttc\ttc tsa\ttc bw\ttc maxrate\tbuffer\t\n")
+	for prio := 0; prio < IEEE_8021Q_MAX_PRIORITIES; prio++ {
+		cells := []string{strconv.Itoa(prio), "-", "-", "-", "-", "-", "-", "-", "-"}
+		if p := cfg.PFC; p != nil {
+			cells[1] = strconv.FormatBool(p.PFCEn&(1<<prio) != 0)
+			cells[2], cells[3] = strconv.FormatUint(p.Requests[prio], 10), strconv.FormatUint(p.Indications[prio], 10)
+		}
+		if e := cfg.ETS; e != nil {
+			tc := e.PrioTC[prio]
+			cells[4] = strconv.Itoa(int(tc))
+			if int(tc) < IEEE_8021QAZ_MAX_TCS {
+				cells[5], cells[6] = e.TCTSA[tc].String(), strconv.Itoa(int(e.TCTxBW[tc]))+"%"
+				if cfg.Maxrate != nil {
+					cells[7] = strconv.FormatUint(cfg.Maxrate.TCMaxrate[tc], 10)
+				}
+			}
+		}
+		if cfg.Buffer != nil {
+			cells[8] = strconv.Itoa(int(cfg.Buffer.Prio2Buffer[prio]))
+		}
+		fmt.Fprint(w, strings.Join(cells, "\t")+"\t\n")
+	}
+	w.Flush()
+	return out.Bytes(), nil
+}
+
+// renderPrometheus renders cfg in the Prometheus text exposition format,
+// such as for the textfile collector of node_exporter. The HELP and TYPE
+// lines come with every interface, so the output of several interfaces
+// must be merged by metric name rather than concatenated.
+func renderPrometheus(cfg *DCBConfig) ([]byte, error) {
+	var out bytes.Buffer
+	ifname := strconv.Quote(cfg.Ifname)
+	metric := func(name, typ, help string, values func(emit func(labels string, v uint64))) {
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		values(func(labels string, v uint64) {
+			fmt.Fprintf(&out, "%s{ifname=%s%s} %d\n", name, ifname, labels, v)
+		})
+	}
+	perIndex := func(label string, n int, value func(i int) uint64) func(emit func(string, uint64)) {
+		return func(emit func(string, uint64)) {
+			for i := 0; i < n; i++ {
+				emit(fmt.Sprintf(",%s=\"%d\"", label, i), value(i))
+			}
+		}
+	}
+	if cfg.DCBX != nil {
+		metric("dcb_dcbx_mode", "gauge", "DCBX mode, a mask of the DCB_CAP_DCBX flags.", func(emit func(string, uint64)) {
+			emit("", uint64(*cfg.DCBX))
+		})
+	}
+	if p := cfg.PFC; p != nil {
+		metric("dcb_pfc_enabled", "gauge", "Whether PFC is enabled on the priority.", perIndex("prio", IEEE_8021Q_MAX_PRIORITIES, func(i int) uint64 {
+			return uint64(p.PFCEn >> i & 1)
+		}))
+		metric("dcb_pfc_requests_total", "counter", "PFC frames sent for the priority.", perIndex("prio", IEEE_8021QAZ_MAX_TCS, func(i int) uint64 {
+			return p.Requests[i]
+		}))
+		metric("dcb_pfc_indications_total", "counter", "PFC frames received for the priority.", perIndex("prio", IEEE_8021QAZ_MAX_TCS, func(i int) uint64 {
+			return p.Indications[i]
+		}))
+	}
+	if e := cfg.ETS; e != nil {
+		metric("dcb_ets_prio_tc", "gauge", "Traffic class of the priority.", perIndex("prio", IEEE_8021Q_MAX_PRIORITIES, func(i int) uint64 {
+			return uint64(e.PrioTC[i])
+		}))
+		metric("dcb_ets_tc_bandwidth_percent", "gauge", "ETS tx bandwidth share of the traffic class.", perIndex("tc", IEEE_8021QAZ_MAX_TCS, func(i int) uint64 {
+			return uint64(e.TCTxBW[i])
+		}))
+		metric("dcb_ets_tc_tsa", "gauge", "Transmission selection algorithm of the traffic class, as IEEE_8021QAZ_TSA.", perIndex("tc", IEEE_8021QAZ_MAX_TCS, func(i int) uint64 {
+			return uint64(e.TCTSA[i])
+		}))
+	}
+	if m := cfg.Maxrate; m != nil {
+		metric("dcb_maxrate_kbps", "gauge", "Tx rate limit of the traffic class in kbit/s, 0 for none.", perIndex("tc", IEEE_8021QAZ_MAX_TCS, func(i int) uint64 {
+			return m.TCMaxrate[i]
+		}))
+	}
+	if b := cfg.Buffer; b != nil {
+		metric("dcb_buffer_size_bytes", "gauge", "Size of the receive buffer.", perIndex("buffer", DCBX_MAX_BUFFERS, func(i int) uint64 {
+			return uint64(b.BufferSize[i])
+		}))
+	}
+	return out.Bytes(), nil
+}
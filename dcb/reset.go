@@ -0,0 +1,85 @@
+package dcb
+
+import (
+	"github.com/mdlayher/netlink"
+)
+
+// FlatETS returns an ETS configuration sharing the bandwidth evenly between
+// tcs traffic classes, with the priorities spread over them in order. The
+// first classes get the remainder of the split.
+func FlatETS(tcs uint8) *IEEEETS {
+	if tcs == 0 || tcs > IEEE_8021QAZ_MAX_TCS {
+		tcs = IEEE_8021QAZ_MAX_TCS
+	}
+	ets := &IEEEETS{}
+	for tc := uint8(0); tc < tcs; tc++ {
+		ets.TCTSA[tc] = TSAETS
+		ets.TCTxBW[tc] = 100 / tcs
+		if tc < 100%tcs {
+			ets.TCTxBW[tc]++
+		}
+	}
+	for prio := range ets.PrioTC {
+		ets.PrioTC[prio] = uint8(prio) * tcs / IEEE_8021Q_MAX_PRIORITIES
+	}
+	return ets
+}
+
+// Reset returns the IEEE configuration of ifname to defaults: it removes
+// every APP entry, disables PFC on all priorities, restores a flat ETS split
+// over the supported traffic classes and maps all priorities to buffer 0.
+//
+// The order matters: classification is dropped first so no traffic is
+// steered to a priority whose PFC is being disabled, and buffers are
+// remapped last because drivers such as mlx5 size lossless buffers from the
+// PFC state. Objects the driver does not report are left alone.
+func (cl *Client) Reset(ifname string) error {
+	return cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+
+		if len(cfg.Apps) > 0 {
+			if err := delIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				encodeAppTable(nae, cfg.Apps)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cfg.PFC != nil {
+			pfc := &IEEEPFC{MBC: cfg.PFC.MBC, Delay: cfg.PFC.Delay}
+			if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				nae.Bytes(DCB_ATTR_IEEE_PFC, pfc.marshal())
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cfg.ETS != nil {
+			ets := FlatETS(cfg.ETS.ETSCap)
+			ets.Willing = cfg.ETS.Willing
+			if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				nae.Bytes(DCB_ATTR_IEEE_ETS, ets.marshal())
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cfg.Buffer != nil {
+			buf := *cfg.Buffer
+			buf.Prio2Buffer = [IEEE_8021Q_MAX_PRIORITIES]uint8{}
+			if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+				nae.Bytes(DCB_ATTR_DCB_BUFFER, buf.marshal())
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,210 @@
+package dcb
+
+import "fmt"
+
+// RoCEv2Port is the UDP destination port of RoCEv2.
+const RoCEv2Port = 4791
+
+// CheckStatus is the outcome of one readiness criterion.
+type CheckStatus int
+
+const (
+	CheckPass CheckStatus = iota
+	CheckWarn
+	CheckFail
+	// CheckSkip means the driver does not report what the criterion needs.
+	CheckSkip
+)
+
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckPass:
+		return "pass"
+	case CheckWarn:
+		return "warn"
+	case CheckFail:
+		return "fail"
+	case CheckSkip:
+		return "skip"
+	}
+	return fmt.Sprintf("CheckStatus(%d)", int(s))
+}
+
+// A Check is the result of one readiness criterion.
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+}
+
+// Failed reports whether any check in checks failed.
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRoCE verifies that ifname carries RoCE losslessly on prio: PFC is
+// enabled on it, RoCE traffic is classified to it, ETS gives its traffic
+// class bandwidth, its buffer is lossless and DCBX cannot override the
+// configuration.
+func (cl *Client) CheckRoCE(ifname string, prio uint8) ([]Check, error) {
+	if prio >= IEEE_8021Q_MAX_PRIORITIES {
+		return nil, fmt.Errorf("invalid priority %d", prio)
+	}
+
+	var checks []Check
+	err := cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		dcbx, dcbxErr := getDCBX(c, ifname)
+		checks = checkRoCE(cfg, dcbx, dcbxErr, prio)
+		return nil
+	})
+	return checks, err
+}
+
+func checkRoCE(cfg *ieeeConfig, dcbx uint8, dcbxErr error, prio uint8) []Check {
+	return []Check{
+		checkRoCEPFC(cfg, prio),
+		checkRoCEClassification(cfg, prio),
+		checkRoCEETS(cfg, prio),
+		checkRoCEBuffer(cfg, prio),
+		checkRoCEDCBX(cfg, dcbx, dcbxErr),
+	}
+}
+
+func checkRoCEPFC(cfg *ieeeConfig, prio uint8) Check {
+	c := Check{Name: "pfc"}
+	switch {
+	case cfg.PFC == nil:
+		c.Status, c.Detail = CheckFail, "driver reports no pfc"
+	case cfg.PFC.PFCEn&(1<<prio) == 0:
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("pfc disabled on prio %d (pfc_en %#02x)", prio, cfg.PFC.PFCEn)
+	default:
+		c.Status, c.Detail = CheckPass, fmt.Sprintf("pfc enabled on prio %d", prio)
+	}
+	return c
+}
+
+func checkRoCEClassification(cfg *ieeeConfig, prio uint8) Check {
+	c := Check{Name: "classification"}
+
+	for _, a := range cfg.Apps {
+		if (a.Selector == IEEE_8021QAZ_APP_SEL_DGRAM || a.Selector == IEEE_8021QAZ_APP_SEL_ANY) &&
+			a.Protocol == RoCEv2Port {
+			if a.Priority != prio {
+				c.Status, c.Detail = CheckFail, fmt.Sprintf("udp %d mapped to prio %d", RoCEv2Port, a.Priority)
+				return c
+			}
+			c.Status, c.Detail = CheckPass, fmt.Sprintf("udp %d mapped to prio %d", RoCEv2Port, prio)
+			return c
+		}
+	}
+
+	trust := EffectiveTrust(cfg.Trust, cfg.Apps)
+	switch trust.Mode {
+	case "dscp":
+		only := DSCPMap{}
+		for d, prios := range DSCPApps(cfg.Apps) {
+			for _, p := range prios {
+				if p == prio {
+					only[d] = []uint8{p}
+				}
+			}
+		}
+		if len(only) == 0 {
+			c.Status, c.Detail = CheckFail, fmt.Sprintf("trust dscp but no dscp mapped to prio %d", prio)
+			return c
+		}
+		c.Status, c.Detail = CheckPass, fmt.Sprintf("trust dscp, dscp %s", only)
+	case "pcp":
+		c.Status, c.Detail = CheckWarn, fmt.Sprintf("trust pcp, relies on RoCE being sent with vlan priority %d", prio)
+	default:
+		c.Status, c.Detail = CheckFail, "neither dscp nor pcp is trusted"
+	}
+	return c
+}
+
+func checkRoCEETS(cfg *ieeeConfig, prio uint8) Check {
+	c := Check{Name: "ets"}
+	if cfg.ETS == nil {
+		c.Status, c.Detail = CheckSkip, "driver reports no ets"
+		return c
+	}
+
+	tc := cfg.ETS.PrioTC[prio]
+	if tc >= IEEE_8021QAZ_MAX_TCS {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("prio %d mapped to invalid tc %d", prio, tc)
+		return c
+	}
+	switch tsa, bw := cfg.ETS.TCTSA[tc], cfg.ETS.TCTxBW[tc]; {
+	case tsa == TSAStrict:
+		c.Status, c.Detail = CheckPass, fmt.Sprintf("prio %d on tc %d, strict priority", prio, tc)
+	case tsa == TSAETS && bw > 0:
+		c.Status, c.Detail = CheckPass, fmt.Sprintf("prio %d on tc %d, ets %d%%", prio, tc, bw)
+	case tsa == TSAETS:
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("prio %d on tc %d, ets with 0%% bandwidth", prio, tc)
+	default:
+		c.Status, c.Detail = CheckWarn, fmt.Sprintf("prio %d on tc %d, tsa %v", prio, tc, tsa)
+	}
+
+	if cfg.PFC != nil && c.Status == CheckPass {
+		for p := uint8(0); p < IEEE_8021Q_MAX_PRIORITIES; p++ {
+			if p != prio && cfg.ETS.PrioTC[p] == tc && cfg.PFC.PFCEn&(1<<p) == 0 {
+				c.Status = CheckWarn
+				c.Detail += fmt.Sprintf(", shared with lossy prio %d", p)
+				break
+			}
+		}
+	}
+	return c
+}
+
+func checkRoCEBuffer(cfg *ieeeConfig, prio uint8) Check {
+	c := Check{Name: "buffer"}
+	if cfg.Buffer == nil {
+		c.Status, c.Detail = CheckSkip, "driver does not expose buffers"
+		return c
+	}
+
+	b := cfg.Buffer.Prio2Buffer[prio]
+	if b >= DCBX_MAX_BUFFERS || cfg.Buffer.BufferSize[b] == 0 {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("prio %d on buffer %d without size", prio, b)
+		return c
+	}
+	if cfg.PFC != nil {
+		for p := uint8(0); p < IEEE_8021Q_MAX_PRIORITIES; p++ {
+			if cfg.Buffer.Prio2Buffer[p] == b && cfg.PFC.PFCEn&(1<<p) == 0 {
+				c.Status, c.Detail = CheckFail, fmt.Sprintf("buffer %d shared with lossy prio %d", b, p)
+				return c
+			}
+		}
+	}
+	c.Status, c.Detail = CheckPass, fmt.Sprintf("prio %d on lossless buffer %d (%d bytes)", prio, b, cfg.Buffer.BufferSize[b])
+	return c
+}
+
+func checkRoCEDCBX(cfg *ieeeConfig, dcbx uint8, dcbxErr error) Check {
+	c := Check{Name: "dcbx"}
+	if dcbxErr != nil {
+		c.Status, c.Detail = CheckSkip, "driver reports no dcbx mode"
+		return c
+	}
+
+	negotiates := dcbx&(DCB_CAP_DCBX_HOST|DCB_CAP_DCBX_LLD_MANAGED) != 0 && dcbx&DCB_CAP_DCBX_STATIC == 0
+	switch {
+	case negotiates && cfg.ETS != nil && cfg.ETS.Willing != 0:
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("dcbx mode %#02x with ets willing, the peer can override", dcbx)
+	case dcbx&DCB_CAP_DCBX_LLD_MANAGED != 0 && dcbx&DCB_CAP_DCBX_STATIC == 0:
+		c.Status, c.Detail = CheckWarn, fmt.Sprintf("dcbx mode %#02x, negotiated by firmware", dcbx)
+	default:
+		c.Status, c.Detail = CheckPass, fmt.Sprintf("dcbx mode %#02x, not willing", dcbx)
+	}
+	return c
+}
@@ -0,0 +1,43 @@
+package dcb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selector is the kind of protocol identifier an APP entry or trust table
+// entry matches on.
+type Selector uint8
+
+var selectorNames = map[Selector]string{
+	IEEE_8021QAZ_APP_SEL_ETHERTYPE: "ethertype",
+	IEEE_8021QAZ_APP_SEL_STREAM:    "stream",
+	IEEE_8021QAZ_APP_SEL_DGRAM:     "dgram",
+	IEEE_8021QAZ_APP_SEL_ANY:       "any",
+	IEEE_8021QAZ_APP_SEL_DSCP:      "dscp",
+	DCB_APP_SEL_PCP:                "pcp",
+}
+
+func (s Selector) String() string {
+	if name, ok := selectorNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// ParseSelector parses a selector name as returned by String, or a raw
+// numeric code.
+func ParseSelector(s string) (Selector, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for sel, name := range selectorNames {
+		if s == name {
+			return sel, nil
+		}
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid selector %q, want ethertype, stream, dgram, any, dscp, pcp or a number", s)
+	}
+	return Selector(v), nil
+}
@@ -0,0 +1,182 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"syscall"
+
+	"github.com/mdlayher/netlink"
+)
+
+// SelfTestDecoders checks that the dcbnl structs survive being encoded and
+// decoded, alone and in a DCB_CMD_IEEE_GET reply built as the kernel sends
+// one. It needs neither an interface nor privileges.
+func SelfTestDecoders() []Check {
+	pfc := &IEEEPFC{PFCCap: 8, PFCEn: 0x18, Delay: 32, Requests: [8]uint64{1, 2, 3, 1 << 40}, Indications: [8]uint64{7: 5}}
+	ets := &IEEEETS{Willing: 1, ETSCap: 8,
+		TCTxBW:   [8]uint8{50, 50},
+		TCTSA:    [8]TSA{IEEE_8021QAZ_TSA_ETS, IEEE_8021QAZ_TSA_ETS, IEEE_8021QAZ_TSA_STRICT},
+		PrioTC:   [8]uint8{0, 0, 0, 1, 0, 0, 2, 0},
+		TCRecoBW: [8]uint8{100}, RecoPrioTC: [8]uint8{3: 1}}
+	maxrate := &IEEEMaxrate{TCMaxrate: [8]uint64{1: 25_000_000}}
+	buffer := &Buffer{Prio2Buffer: [8]uint8{3: 1}, BufferSize: [8]uint32{65536, 131072}, TotalSize: 1 << 20}
+	apps := []App{
+		{Selector: IEEE_8021QAZ_APP_SEL_ETHERTYPE, Priority: 3, Protocol: 0x8915},
+		{Selector: IEEE_8021QAZ_APP_SEL_DSCP, Priority: 3, Protocol: 26},
+	}
+
+	checks := []Check{
+		checkRoundTrip("decode pfc", pfc, new(IEEEPFC)),
+		checkRoundTrip("decode ets", ets, new(IEEEETS)),
+		checkRoundTrip("decode maxrate", maxrate, new(IEEEMaxrate)),
+		checkRoundTrip("decode buffer", buffer, new(Buffer)),
+		checkRoundTrip("decode app", &apps[1], new(App)),
+	}
+
+	c := Check{Name: "decode ieee reply", Status: CheckPass, Detail: "reply decoded as encoded"}
+	msg, err := ieeeReply(func(nae *netlink.AttributeEncoder) error {
+		nae.Bytes(DCB_ATTR_IEEE_PFC, pfc.marshal())
+		nae.Bytes(DCB_ATTR_IEEE_ETS, ets.marshal())
+		nae.Bytes(DCB_ATTR_IEEE_MAXRATE, maxrate.marshal())
+		nae.Bytes(DCB_ATTR_DCB_BUFFER, buffer.marshal())
+		encodeAppTable(nae, apps)
+		return nil
+	})
+	var cfg *ieeeConfig
+	if err == nil {
+		cfg, err = parseIEEEReply([]netlink.Message{msg})
+	}
+	switch {
+	case err != nil:
+		c.Status, c.Detail = CheckFail, err.Error()
+	case !reflect.DeepEqual(cfg.PFC, pfc), !reflect.DeepEqual(cfg.ETS, ets), !reflect.DeepEqual(cfg.Maxrate, maxrate),
+		!reflect.DeepEqual(cfg.Buffer, buffer), !reflect.DeepEqual(cfg.Apps, apps):
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("decoded %+v", cfg)
+	}
+	return append(checks, c)
+}
+
+type binaryValue interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(b []byte) error
+}
+
+// checkRoundTrip encodes v, decodes it into into and compares the two.
+func checkRoundTrip(name string, v, into binaryValue) Check {
+	c := Check{Name: name, Status: CheckPass, Detail: "decoded as encoded"}
+	b, err := v.MarshalBinary()
+	if err == nil {
+		err = into.UnmarshalBinary(b)
+	}
+	switch {
+	case err != nil:
+		c.Status, c.Detail = CheckFail, err.Error()
+	case !reflect.DeepEqual(v, into):
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("encoded %+v, decoded %+v", v, into)
+	}
+	return c
+}
+
+// ieeeReply builds a DCB_CMD_IEEE_GET reply with the attributes added by
+// encode nested in DCB_ATTR_IEEE.
+func ieeeReply(encode func(nae *netlink.AttributeEncoder) error) (netlink.Message, error) {
+	hdr, err := (&dcbMsg{family: afUnspec, cmd: DCB_CMD_IEEE_GET}).MarshalBinary()
+	if err != nil {
+		return netlink.Message{}, err
+	}
+	ae := netlink.NewAttributeEncoder()
+	ae.String(DCB_ATTR_IFNAME, "selftest0")
+	ae.Nested(DCB_ATTR_IEEE, encode)
+	attrs, err := ae.Encode()
+	if err != nil {
+		return netlink.Message{}, err
+	}
+	return netlink.Message{Header: netlink.Header{Type: rtmGetDCB}, Data: append(hdr, attrs...)}, nil
+}
+
+// SelfTest runs set and get round trips on ifname: it changes PFC, maxrate
+// and the APP table to test values, reads them back and restores the
+// original, and writes ETS, the buffer and the DCBX mode back as read.
+// Objects the driver does not implement are skipped; an interface without
+// dcbnl ops at all, as dummy and veth interfaces, gives a single check that
+// the kernel reports so.
+//
+// SelfTest changes the configuration of ifname while it runs, so it is
+// meant for test devices such as netdevsim, not interfaces carrying
+// traffic.
+func (cl *Client) SelfTest(ifname string) []Check {
+	if _, err := cl.GetAll(ifname); err != nil {
+		c := Check{Name: "dcbnl", Status: CheckFail, Detail: err.Error()}
+		if errors.Is(err, syscall.EOPNOTSUPP) {
+			c.Status, c.Detail = CheckPass, "no dcbnl ops, reported as not supported"
+		}
+		return []Check{c}
+	}
+
+	return []Check{
+		selfTestObject(ifname, "pfc", cl.GetPFC, cl.SetPFC, func(p *IEEEPFC) *IEEEPFC {
+			t := *p
+			t.PFCEn ^= 1 << 7
+			return &t
+		}, func(a, b *IEEEPFC) bool { return a.PFCEn == b.PFCEn }),
+		selfTestObject(ifname, "ets", cl.GetETS, cl.SetETS, nil, func(a, b *IEEEETS) bool {
+			return a.TCTxBW == b.TCTxBW && a.TCTSA == b.TCTSA && a.PrioTC == b.PrioTC
+		}),
+		selfTestObject(ifname, "maxrate", cl.GetMaxrate, cl.SetMaxrate, func(m *IEEEMaxrate) *IEEEMaxrate {
+			t := *m
+			if t.TCMaxrate[7] == 0 {
+				t.TCMaxrate[7] = 1_000_000
+			} else {
+				t.TCMaxrate[7] = 0
+			}
+			return &t
+		}, func(a, b *IEEEMaxrate) bool { return *a == *b }),
+		selfTestObject(ifname, "buffer", cl.GetBuffer, cl.SetBuffer, nil, func(a, b *Buffer) bool {
+			return a.Prio2Buffer == b.Prio2Buffer && a.BufferSize == b.BufferSize
+		}),
+		selfTestObject(ifname, "app", cl.GetApp, cl.SetApps, func(apps []App) []App {
+			// a DSCP entry unlikely to be in use
+			return mergeApps(append([]App(nil), apps...), []App{{Selector: IEEE_8021QAZ_APP_SEL_DSCP, Priority: 7, Protocol: 62}})
+		}, func(a, b []App) bool { return reflect.DeepEqual(a, b) }),
+		selfTestObject(ifname, "dcbx", cl.GetDCBX, cl.SetDCBX, nil, func(a, b uint8) bool { return a == b }),
+	}
+}
+
+// selfTestObject sets the object name of ifname to the value change
+// derives from the one read, or back as read if change is nil, checks it
+// reads back alike by same and restores the original.
+func selfTestObject[T any](ifname, name string, get func(string) (T, error), set func(string, T) error, change func(T) T, same func(a, b T) bool) Check {
+	c := Check{Name: name + " set/get", Status: CheckPass}
+	orig, err := get(ifname)
+	if err != nil {
+		c.Status, c.Detail = CheckFail, err.Error()
+		if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, ErrNoAttribute) {
+			c.Status, c.Detail = CheckSkip, "not implemented by the driver"
+		}
+		return c
+	}
+	want, what := orig, "written back as read"
+	if change != nil {
+		want, what = change(orig), "changed and restored"
+	}
+	if err := set(ifname, want); err != nil {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("set: %v", err)
+		return c
+	}
+	got, err := get(ifname)
+	switch {
+	case err != nil:
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("read back: %v", err)
+	case !same(got, want):
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("set %+v, read back %+v", want, got)
+	default:
+		c.Detail = what
+	}
+	if change != nil {
+		if err := set(ifname, orig); err != nil {
+			c.Status, c.Detail = CheckFail, fmt.Sprintf("restore: %v", err)
+		}
+	}
+	return c
+}
@@ -0,0 +1,190 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// A Snapshot is the DCB state of an interface, as saved by Client.Snapshot
+// and reapplied by Client.Restore. Objects the driver does not report are
+// nil and left untouched on restore; an empty, non-nil Apps clears the APP
+// table, while a nil Apps, as in a snapshot without the app object, leaves
+// it as is.
+type Snapshot struct {
+	Schema  int          `json:"schema,omitempty"`
+	Ifname  string       `json:"ifname"`
+	Time    time.Time    `json:"time"`
+	DCBX    *uint8       `json:"dcbx,omitempty"`
+	PFC     *IEEEPFC     `json:"pfc,omitempty"`
+	ETS     *IEEEETS     `json:"ets,omitempty"`
+	Maxrate *IEEEMaxrate `json:"maxrate,omitempty"`
+	Apps    []App        `json:"apps"`
+	Buffer  *Buffer      `json:"buffer,omitempty"`
+	Trust   []Selector   `json:"trust,omitempty"`
+}
+
+// SnapshotSchema is the version of the JSON encoding of Snapshot. Fields
+// are only added within a version, so readers of an older version of this
+// package ignore what they do not know; a change that needs a reader to
+// understand it bumps the version.
+const SnapshotSchema = 1
+
+// CheckSchema returns an error if s was encoded with a newer schema than
+// this package reads. Snapshots from before versioning have no schema and
+// are version 1.
+func (s *Snapshot) CheckSchema() error {
+	if s.Schema > SnapshotSchema {
+		return fmt.Errorf("ifname: %v, snapshot schema %d is newer than the supported %d", s.Ifname, s.Schema, SnapshotSchema)
+	}
+	return nil
+}
+
+// Snapshot returns the DCB state of ifname.
+func (cl *Client) Snapshot(ifname string) (*Snapshot, error) {
+	return cl.SnapshotOf(ifname)
+}
+
+// SnapshotObjects are the objects a Snapshot holds.
+var SnapshotObjects = []Object{ObjectDCBX, ObjectPFC, ObjectETS, ObjectMaxrate, ObjectApp, ObjectBuffer, ObjectTrust}
+
+// SnapshotOf returns the part of the DCB state of ifname made of objs, all
+// of SnapshotObjects if none are given. The other objects are nil, so a
+// restore of the snapshot leaves them untouched, and the DCBX mode is only
+// queried if selected.
+func (cl *Client) SnapshotOf(ifname string, objs ...Object) (*Snapshot, error) {
+	if len(objs) == 0 {
+		objs = SnapshotObjects
+	}
+	for _, obj := range objs {
+		if !slices.Contains(SnapshotObjects, obj) {
+			return nil, fmt.Errorf("ifname: %v, snapshot: unknown object %q", ifname, obj)
+		}
+	}
+	want := func(obj Object) bool { return slices.Contains(objs, obj) }
+
+	s := &Snapshot{Schema: SnapshotSchema, Ifname: ifname, Time: time.Now()}
+	err := cl.do(func(c *conn) error {
+		if len(objs) > 1 || objs[0] != ObjectDCBX {
+			cfg, err := getIEEE(c, ifname)
+			if err != nil {
+				return err
+			}
+			if want(ObjectPFC) {
+				s.PFC = cfg.PFC
+			}
+			if want(ObjectETS) {
+				s.ETS = cfg.ETS
+			}
+			if want(ObjectMaxrate) {
+				s.Maxrate = cfg.Maxrate
+			}
+			if want(ObjectApp) {
+				s.Apps = cfg.Apps
+				if s.Apps == nil {
+					s.Apps = []App{}
+				}
+			}
+			if want(ObjectBuffer) {
+				s.Buffer = cfg.Buffer
+			}
+			if want(ObjectTrust) {
+				s.Trust = cfg.Trust
+			}
+		}
+
+		// the DCBX mode is optional, drivers without getdcbx fail the
+		// command rather than omit the attribute
+		if want(ObjectDCBX) {
+			if mode, err := getDCBX(c, ifname); err == nil {
+				s.DCBX = &mode
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Restore reapplies s to ifname, which may differ from s.Ifname when the
+// interface was renamed or the NIC replaced. Objects the driver of ifname
+// does not implement, or the running kernel predates, are skipped and
+// returned rather than failing the restore, as happens when a NIC is
+// replaced by another model.
+//
+// The DCBX mode is set first since it decides whether the host may change
+// the rest. The APP table is replaced last, adding the saved entries before
+// deleting the others so classification never falls back in between.
+// Drivers known to need another order, see quirks, get theirs. PFC, ETS,
+// maxrate, buffer and APP additions go in a single DCB_CMD_IEEE_SET when
+// that order allows, so other readers never see them half applied.
+func (cl *Client) Restore(ifname string, s *Snapshot) ([]Object, error) {
+	caps, err := cl.Probe(ifname)
+	if err != nil {
+		return nil, err
+	}
+
+	var skipped []Object
+	apps := s.Apps
+	if apps != nil && !caps.Supports(ObjectPCPApp) {
+		apps = []App{}
+		for _, a := range s.Apps {
+			if appAttrType(a) == DCB_ATTR_DCB_APP {
+				continue
+			}
+			apps = append(apps, a)
+		}
+		if len(apps) < len(s.Apps) {
+			skipped = append(skipped, ObjectPCPApp)
+		}
+	}
+	q := quirksOf(ifname)
+	steps := map[Object]struct {
+		saved bool
+		apply func() error
+	}{
+		ObjectDCBX:    {s.DCBX != nil, func() error { return cl.SetDCBX(ifname, *s.DCBX) }},
+		ObjectTrust:   {s.Trust != nil, func() error { return cl.SetTrust(ifname, s.Trust) }},
+		ObjectETS:     {s.ETS != nil, func() error { return cl.setETSQuirks(ifname, s.ETS, q) }},
+		ObjectMaxrate: {s.Maxrate != nil, func() error { return cl.SetMaxrate(ifname, s.Maxrate) }},
+		ObjectPFC:     {s.PFC != nil, func() error { return cl.SetPFC(ifname, s.PFC) }},
+		ObjectBuffer:  {s.Buffer != nil, func() error { return cl.SetBuffer(ifname, s.Buffer) }},
+		ObjectApp:     {apps != nil, func() error { return cl.SetApps(ifname, apps) }},
+	}
+	order := q.applyOrder()
+	batch := batchable(order, func(obj Object) bool { return steps[obj].saved && caps.Supports(obj) })
+	batched := false
+	for _, obj := range order {
+		step := steps[obj]
+		if !step.saved {
+			continue
+		}
+		if !caps.Supports(obj) {
+			skipped = append(skipped, obj)
+			continue
+		}
+		if slices.Contains(batch, obj) {
+			if batched {
+				continue
+			}
+			err := cl.setBatch(ifname, s, apps, batch, q)
+			if err == nil {
+				batched = true
+				continue
+			}
+			if errors.As(err, new(*VerifyError)) {
+				return skipped, err
+			}
+			// set the objects one by one instead, which also names the
+			// one the driver refuses
+			batch = nil
+		}
+		if err := step.apply(); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,36 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// sysClassNet is where the kernel lists the netdevs of the host.
+const sysClassNet = "/sys/class/net"
+
+// IsVF reports whether ifname is an SR-IOV virtual function. DCB is a port
+// property configured on the physical function; drivers mostly refuse dcbnl
+// on VFs with EOPNOTSUPP.
+func IsVF(ifname string) bool {
+	_, err := os.Stat(sysClassNet + "/" + ifname + "/device/physfn")
+	return err == nil
+}
+
+// PhysFn returns the netdev of the physical function VF ifname belongs to.
+// If the PF has several netdevs, as with switchdev representors, the first
+// by name is returned.
+func PhysFn(ifname string) (string, error) {
+	if !IsVF(ifname) {
+		return "", fmt.Errorf("ifname: %v, not an sr-iov vf", ifname)
+	}
+	ents, err := os.ReadDir(sysClassNet + "/" + ifname + "/device/physfn/net")
+	if errors.Is(err, os.ErrNotExist) || err == nil && len(ents) == 0 {
+		return "", fmt.Errorf("ifname: %v, pf of vf has no netdev", ifname)
+	}
+	if err != nil {
+		return "", fmt.Errorf("ifname: %v, find pf: %w", ifname, err)
+	}
+	// sorted by name
+	return ents[0].Name(), nil
+}
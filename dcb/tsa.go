@@ -0,0 +1,46 @@
+package dcb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TSA is the transmission selection algorithm of an ETS traffic class.
+type TSA uint8
+
+const (
+	TSAStrict   TSA = IEEE_8021QAZ_TSA_STRICT
+	TSACBShaper TSA = IEEE_8021QAZ_TSA_CB_SHAPER
+	TSAETS      TSA = IEEE_8021QAZ_TSA_ETS
+	TSAVendor   TSA = IEEE_8021QAZ_TSA_VENDOR
+)
+
+var tsaNames = map[TSA]string{
+	TSAStrict:   "strict",
+	TSACBShaper: "cbs",
+	TSAETS:      "ets",
+	TSAVendor:   "vendor",
+}
+
+func (t TSA) String() string {
+	if name, ok := tsaNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// ParseTSA parses a TSA name as returned by String, or a raw numeric code.
+func ParseTSA(s string) (TSA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for t, name := range tsaNames {
+		if s == name {
+			return t, nil
+		}
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tsa %q, want strict, ets, cbs, vendor or a number", s)
+	}
+	return TSA(v), nil
+}
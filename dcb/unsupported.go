@@ -0,0 +1,34 @@
+//go:build !linux
+
+package dcb
+
+import "github.com/mdlayher/netlink"
+
+// dcbnl, ethtool and the sysfs layout are Linux interfaces. Elsewhere the
+// package builds, so its types and decoders can be used, for instance on
+// snapshot files or captures, but every query and watch fails with
+// ErrUnsupportedPlatform.
+
+func dialNetlink(int, *netlink.Config) (*netlink.Conn, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+func setBuffers(*netlink.Conn, int, int) error {
+	return ErrUnsupportedPlatform
+}
+
+func ethtoolStats(string) ([]string, []uint64, error) {
+	return nil, nil, ErrUnsupportedPlatform
+}
+
+func ethtoolSpeed(string) (uint64, error) {
+	return 0, ErrUnsupportedPlatform
+}
+
+func drvinfo(ifname string) (driver, firmware string) {
+	return "", ""
+}
+
+func kernelRelease() (string, error) {
+	return "", ErrUnsupportedPlatform
+}
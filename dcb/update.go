@@ -0,0 +1,105 @@
+package dcb
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mdlayher/netlink"
+)
+
+// The Update methods read an object of an interface, let fn change it and
+// write it back whole, so the fields fn leaves alone keep the values the
+// driver reported rather than the zero values of a freshly built object.
+// The result is validated before it is written, and nothing is written if
+// fn changed nothing or returned an error, which is returned wrapped.
+//
+// The read and the write go over the same socket but are not atomic: a
+// change made by another writer in between is overwritten.
+
+// UpdatePFC changes the IEEE 802.1Qaz PFC managed object of ifname with fn.
+func (cl *Client) UpdatePFC(ifname string, fn func(pfc *IEEEPFC) error) error {
+	return update(cl, ifname, ObjectPFC, DCB_ATTR_IEEE_PFC, func(cfg *ieeeConfig) *IEEEPFC { return cfg.PFC }, fn)
+}
+
+// UpdateETS changes the IEEE 802.1Qaz ETS managed object of ifname with fn.
+func (cl *Client) UpdateETS(ifname string, fn func(ets *IEEEETS) error) error {
+	return update(cl, ifname, ObjectETS, DCB_ATTR_IEEE_ETS, func(cfg *ieeeConfig) *IEEEETS { return cfg.ETS }, fn)
+}
+
+// UpdateMaxrate changes the per traffic class rate limits of ifname with fn.
+func (cl *Client) UpdateMaxrate(ifname string, fn func(m *IEEEMaxrate) error) error {
+	return update(cl, ifname, ObjectMaxrate, DCB_ATTR_IEEE_MAXRATE, func(cfg *ieeeConfig) *IEEEMaxrate { return cfg.Maxrate }, fn)
+}
+
+// UpdateBuffer changes the priority to buffer mapping and buffer sizes of
+// ifname with fn.
+func (cl *Client) UpdateBuffer(ifname string, fn func(buf *Buffer) error) error {
+	return update(cl, ifname, ObjectBuffer, DCB_ATTR_DCB_BUFFER, func(cfg *ieeeConfig) *Buffer { return cfg.Buffer }, fn)
+}
+
+// UpdateApps changes the APP table of ifname to the one fn returns when
+// passed a copy of the current table. Only the entries that differ are
+// added and deleted, as by SetApps.
+func (cl *Client) UpdateApps(ifname string, fn func(apps []App) ([]App, error)) error {
+	return cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		apps, err := fn(slices.Clone(cfg.Apps))
+		if err != nil {
+			return fmt.Errorf("ifname: %v, update %s: %w", ifname, ObjectApp, err)
+		}
+		if err := checkAppSelectors(apps); err != nil {
+			return fmt.Errorf("ifname: %v, update %s: %w", ifname, ObjectApp, err)
+		}
+		if add, stale := appChanges(cfg.Apps, apps); len(add) == 0 && len(stale) == 0 {
+			return nil
+		}
+		if err := setApps(c, ifname, apps); err != nil {
+			return err
+		}
+		return cl.verifyApps(c, ifname, apps, true)
+	})
+}
+
+// An ieeeObject is a pointer to a struct sent as a single attribute of
+// DCB_ATTR_IEEE.
+type ieeeObject[T any] interface {
+	*T
+	marshal() []byte
+}
+
+// update reads the IEEE config of ifname, changes the object selected by
+// have with fn and writes it back as the attribute attr.
+func update[T comparable, P ieeeObject[T]](cl *Client, ifname string, obj Object, attr uint16, have func(cfg *ieeeConfig) P, fn func(P) error) error {
+	return cl.do(func(c *conn) error {
+		cfg, err := getIEEE(c, ifname)
+		if err != nil {
+			return err
+		}
+		cur := have(cfg)
+		if cur == nil {
+			return fmt.Errorf("ifname: %v, get %s: %w", ifname, obj, ErrNoAttribute)
+		}
+		orig := *cur
+		if err := fn(cur); err != nil {
+			return fmt.Errorf("ifname: %v, update %s: %w", ifname, obj, err)
+		}
+		if v, ok := any(cur).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("ifname: %v, update %s: %w", ifname, obj, err)
+			}
+		}
+		if *cur == orig {
+			return nil
+		}
+		if err := setIEEE(c, ifname, func(nae *netlink.AttributeEncoder) error {
+			nae.Bytes(attr, cur.marshal())
+			return nil
+		}); err != nil {
+			return err
+		}
+		return cl.verifyIEEE(c, ifname, obj, cur, func(cfg *ieeeConfig) any { return have(cfg) })
+	})
+}
@@ -0,0 +1,67 @@
+package dcb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A VerifyError is returned by the set operations of a Client dialed with
+// Config.Verify when the driver acknowledged a set but reports other values
+// when read back. In Changes, Old is the requested value and New the one the
+// driver reports.
+type VerifyError struct {
+	Ifname  string
+	Object  Object
+	Changes []Change
+}
+
+func (e *VerifyError) Error() string {
+	diffs := make([]string, len(e.Changes))
+	for i, ch := range e.Changes {
+		diffs[i] = fmt.Sprintf("%s requested %+v, reported %+v", ch.Path, display(ch.Old), display(ch.New))
+	}
+	return fmt.Sprintf("ifname: %v, verify %s: driver did not apply: %s", e.Ifname, e.Object, strings.Join(diffs, "; "))
+}
+
+// verifyIEEE reads the IEEE config of ifname back and compares the object
+// selected by have with want, skipping read-only fields.
+func (cl *Client) verifyIEEE(c *conn, ifname string, obj Object, want any, have func(cfg *ieeeConfig) any) error {
+	if !cl.verify {
+		return nil
+	}
+	cfg, err := getIEEE(c, ifname)
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", obj, err)
+	}
+	var changes []Change
+	diffValue(string(obj), reflect.ValueOf(want), reflect.ValueOf(have(cfg)), true, &changes)
+	if len(changes) > 0 {
+		return &VerifyError{Ifname: ifname, Object: obj, Changes: changes}
+	}
+	return nil
+}
+
+// verifyApps checks that the APP table of ifname holds apps, and nothing
+// else if exact is set.
+func (cl *Client) verifyApps(c *conn, ifname string, apps []App, exact bool) error {
+	if !cl.verify {
+		return nil
+	}
+	cfg, err := getIEEE(c, ifname)
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", ObjectApp, err)
+	}
+	var changes []Change
+	for _, ch := range diffApps(apps, cfg.Apps) {
+		// entries the table has beyond the requested ones only matter
+		// when replacing it
+		if ch.Old != nil || exact {
+			changes = append(changes, ch)
+		}
+	}
+	if len(changes) > 0 {
+		return &VerifyError{Ifname: ifname, Object: ObjectApp, Changes: changes}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// watchPoll is how often Watch reads every interface again, for the peers
+// and drivers that change the config without a dcbnl notification.
+const watchPoll = 5 * time.Second
+
+// An Event is a change of the DCB config of an interface delivered by
+// Watch: a *PFCChanged, *ETSChanged, *AppTableChanged or *PeerUpdated.
+type Event interface {
+	// Info returns the interface and time of the event.
+	Info() EventInfo
+}
+
+// EventInfo is what all events have in common.
+type EventInfo struct {
+	Ifname string
+	Time   time.Time
+}
+
+func (e EventInfo) Info() EventInfo { return e }
+
+// PFCChanged reports a change of the PFC config; counters are not config
+// and changing alone is no event, but Old and New carry them.
+type PFCChanged struct {
+	EventInfo
+	Old, New *IEEEPFC
+}
+
+// ETSChanged reports a change of the ETS config.
+type ETSChanged struct {
+	EventInfo
+	Old, New *IEEEETS
+}
+
+// AppTableChanged reports a change of the APP table.
+type AppTableChanged struct {
+	EventInfo
+	Old, New []App
+}
+
+// PeerUpdated reports that the link partner advertises another config
+// than before.
+type PeerUpdated struct {
+	EventInfo
+	Old, New *Peer
+}
+
+// Watch delivers the changes of the PFC, ETS and APP config and of the peer
+// of every interface of the host with IEEE DCB, until ctx is done, when the
+// channel is closed. Interfaces are read again on each dcbnl notification,
+// and all of them every few seconds for the changes that come without one;
+// the interfaces of the host are followed with a LinkCache.
+// The state first read of an interface, such as one appearing later, is
+// the baseline and no event.
+//
+// Events are sent as they are found, so a receiver falling behind delays
+// the next reads rather than losing events. ctx must be done before the
+// Client is closed.
+func (cl *Client) Watch(ctx context.Context) (<-chan Event, error) {
+	w, err := WatchConfig()
+	if err != nil {
+		return nil, err
+	}
+	links, err := cl.NewLinkCache()
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	ifnames := links.Names()
+	state := map[string]*ieeeConfig{}
+	for _, ifname := range ifnames {
+		if cfg, err := cl.getIEEE(ifname); err == nil {
+			state[ifname] = cfg
+		}
+	}
+
+	notified := make(chan []string)
+	go func() {
+		for {
+			ifnames, err := w.Next()
+			if errors.Is(err, syscall.ENOBUFS) {
+				// notifications lost, reading all is left to the poll
+				continue
+			}
+			if err != nil {
+				// closed, or broken: polling goes on
+				return
+			}
+			select {
+			case notified <- ifnames:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer w.Close()
+		defer links.Close()
+		ticker := time.NewTicker(watchPoll)
+		defer ticker.Stop()
+		// send is false once ctx is done
+		send := func(ev Event) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			var ifnames []string
+			select {
+			case <-ctx.Done():
+				return
+			case ifnames = <-notified:
+			case <-ticker.C:
+				ifnames = links.Names()
+				for ifname := range state {
+					if !slices.Contains(ifnames, ifname) {
+						delete(state, ifname)
+					}
+				}
+			}
+			for _, ifname := range ifnames {
+				cfg, err := cl.getIEEE(ifname)
+				if err != nil {
+					continue
+				}
+				old := state[ifname]
+				state[ifname] = cfg
+				if old == nil {
+					continue
+				}
+				for _, ev := range configEvents(ifname, old, cfg) {
+					if !send(ev) {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// getIEEE reads the IEEE config of ifname with a socket of the Client.
+func (cl *Client) getIEEE(ifname string) (*ieeeConfig, error) {
+	var cfg *ieeeConfig
+	err := cl.do(func(c *conn) error {
+		var err error
+		cfg, err = getIEEE(c, ifname)
+		return err
+	})
+	return cfg, err
+}
+
+// configEvents returns the events of ifname going from old to cfg.
+func configEvents(ifname string, old, cfg *ieeeConfig) []Event {
+	info := EventInfo{Ifname: ifname, Time: time.Now()}
+	var events []Event
+	if !pfcConfigEqual(old.PFC, cfg.PFC) {
+		events = append(events, &PFCChanged{info, old.PFC, cfg.PFC})
+	}
+	if !reflect.DeepEqual(old.ETS, cfg.ETS) {
+		events = append(events, &ETSChanged{info, old.ETS, cfg.ETS})
+	}
+	if !slices.Equal(old.Apps, cfg.Apps) {
+		events = append(events, &AppTableChanged{info, old.Apps, cfg.Apps})
+	}
+	if !reflect.DeepEqual(old.Peer, cfg.Peer) {
+		oldPeer, peer := old.Peer, cfg.Peer
+		events = append(events, &PeerUpdated{info, &oldPeer, &peer})
+	}
+	return events
+}
+
+// pfcConfigEqual compares a and b leaving out the counters.
+func pfcConfigEqual(a, b *IEEEPFC) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.Requests, x.Indications = y.Requests, y.Indications
+	return x == y
+}
@@ -0,0 +1,563 @@
+// Code generated by dcbnlgen from dcbnl.h; DO NOT EDIT.
+
+package dcb
+
+import "strconv"
+
+const (
+	IEEE_8021QAZ_MAX_TCS       = 8
+	IEEE_8021QAZ_TSA_STRICT    = 0
+	IEEE_8021QAZ_TSA_CB_SHAPER = 1
+	IEEE_8021QAZ_TSA_ETS       = 2
+	IEEE_8021QAZ_TSA_VENDOR    = 255
+)
+
+// struct ieee_ets
+const (
+	sizeofIeeeEts        = 59
+	offIeeeEtsWilling    = 0
+	offIeeeEtsEtsCap     = 1
+	offIeeeEtsCbs        = 2
+	offIeeeEtsTcTxBw     = 3
+	offIeeeEtsTcRxBw     = 11
+	offIeeeEtsTcTsa      = 19
+	offIeeeEtsPrioTc     = 27
+	offIeeeEtsTcRecoBw   = 35
+	offIeeeEtsTcRecoTsa  = 43
+	offIeeeEtsRecoPrioTc = 51
+)
+
+// struct ieee_maxrate
+const (
+	sizeofIeeeMaxrate       = 64
+	offIeeeMaxrateTcMaxrate = 0
+)
+
+// enum dcbnl_cndd_states
+const (
+	DCB_CNDD_RESET          = 0
+	DCB_CNDD_EDGE           = 1
+	DCB_CNDD_INTERIOR       = 2
+	DCB_CNDD_INTERIOR_READY = 3
+)
+
+// struct ieee_qcn
+const (
+	sizeofIeeeQcn              = 360
+	offIeeeQcnRpgEnable        = 0
+	offIeeeQcnRpppMaxRps       = 8
+	offIeeeQcnRpgTimeReset     = 40
+	offIeeeQcnRpgByteReset     = 72
+	offIeeeQcnRpgThreshold     = 104
+	offIeeeQcnRpgMaxRate       = 136
+	offIeeeQcnRpgAiRate        = 168
+	offIeeeQcnRpgHaiRate       = 200
+	offIeeeQcnRpgGd            = 232
+	offIeeeQcnRpgMinDecFac     = 264
+	offIeeeQcnRpgMinRate       = 296
+	offIeeeQcnCnddStateMachine = 328
+)
+
+// struct ieee_qcn_stats
+const (
+	sizeofIeeeQcnStats                = 96
+	offIeeeQcnStatsRpppRpCentiseconds = 0
+	offIeeeQcnStatsRpppCreatedRps     = 64
+)
+
+// struct ieee_pfc
+const (
+	sizeofIeeePfc         = 136
+	offIeeePfcPfcCap      = 0
+	offIeeePfcPfcEn       = 1
+	offIeeePfcMbc         = 2
+	offIeeePfcDelay       = 4
+	offIeeePfcRequests    = 8
+	offIeeePfcIndications = 72
+)
+
+const (
+	IEEE_8021Q_MAX_PRIORITIES = 8
+	DCBX_MAX_BUFFERS          = 8
+)
+
+// struct dcbnl_buffer
+const (
+	sizeofDcbnlBuffer         = 44
+	offDcbnlBufferPrio2buffer = 0
+	offDcbnlBufferBufferSize  = 8
+	offDcbnlBufferTotalSize   = 40
+)
+
+const (
+	CEE_DCBX_MAX_PGS  = 8
+	CEE_DCBX_MAX_PRIO = 8
+)
+
+// struct cee_pg
+const (
+	sizeofCeePg          = 20
+	offCeePgWilling      = 0
+	offCeePgError        = 1
+	offCeePgPgEn         = 2
+	offCeePgTcsSupported = 3
+	offCeePgPgBw         = 4
+	offCeePgPrioPg       = 12
+)
+
+// struct cee_pfc
+const (
+	sizeofCeePfc          = 4
+	offCeePfcWilling      = 0
+	offCeePfcError        = 1
+	offCeePfcPfcEn        = 2
+	offCeePfcTcsSupported = 3
+)
+
+const (
+	IEEE_8021QAZ_APP_SEL_ETHERTYPE = 1
+	IEEE_8021QAZ_APP_SEL_STREAM    = 2
+	IEEE_8021QAZ_APP_SEL_DGRAM     = 3
+	IEEE_8021QAZ_APP_SEL_ANY       = 4
+	IEEE_8021QAZ_APP_SEL_DSCP      = 5
+	DCB_APP_SEL_PCP                = 255
+)
+
+// struct dcb_app
+const (
+	sizeofDcbApp      = 4
+	offDcbAppSelector = 0
+	offDcbAppPriority = 1
+	offDcbAppProtocol = 2
+)
+
+// struct dcb_peer_app_info
+const (
+	sizeofDcbPeerAppInfo     = 2
+	offDcbPeerAppInfoWilling = 0
+	offDcbPeerAppInfoError   = 1
+)
+
+// struct dcbmsg
+const (
+	sizeofDcbmsg       = 4
+	offDcbmsgDcbFamily = 0
+	offDcbmsgCmd       = 1
+	offDcbmsgDcbPad    = 2
+)
+
+// enum dcbnl_commands
+const (
+	DCB_CMD_UNDEFINED    = 0
+	DCB_CMD_GSTATE       = 1
+	DCB_CMD_SSTATE       = 2
+	DCB_CMD_PGTX_GCFG    = 3
+	DCB_CMD_PGTX_SCFG    = 4
+	DCB_CMD_PGRX_GCFG    = 5
+	DCB_CMD_PGRX_SCFG    = 6
+	DCB_CMD_PFC_GCFG     = 7
+	DCB_CMD_PFC_SCFG     = 8
+	DCB_CMD_SET_ALL      = 9
+	DCB_CMD_GPERM_HWADDR = 10
+	DCB_CMD_GCAP         = 11
+	DCB_CMD_GNUMTCS      = 12
+	DCB_CMD_SNUMTCS      = 13
+	DCB_CMD_PFC_GSTATE   = 14
+	DCB_CMD_PFC_SSTATE   = 15
+	DCB_CMD_BCN_GCFG     = 16
+	DCB_CMD_BCN_SCFG     = 17
+	DCB_CMD_GAPP         = 18
+	DCB_CMD_SAPP         = 19
+	DCB_CMD_IEEE_SET     = 20
+	DCB_CMD_IEEE_GET     = 21
+	DCB_CMD_GDCBX        = 22
+	DCB_CMD_SDCBX        = 23
+	DCB_CMD_GFEATCFG     = 24
+	DCB_CMD_SFEATCFG     = 25
+	DCB_CMD_CEE_GET      = 26
+	DCB_CMD_IEEE_DEL     = 27
+	DCB_CMD_MAX          = 27
+)
+
+// Command is a value of enum dcbnl_commands.
+type Command uint8
+
+func (v Command) String() string {
+	switch v {
+	case DCB_CMD_UNDEFINED:
+		return "DCB_CMD_UNDEFINED"
+	case DCB_CMD_GSTATE:
+		return "DCB_CMD_GSTATE"
+	case DCB_CMD_SSTATE:
+		return "DCB_CMD_SSTATE"
+	case DCB_CMD_PGTX_GCFG:
+		return "DCB_CMD_PGTX_GCFG"
+	case DCB_CMD_PGTX_SCFG:
+		return "DCB_CMD_PGTX_SCFG"
+	case DCB_CMD_PGRX_GCFG:
+		return "DCB_CMD_PGRX_GCFG"
+	case DCB_CMD_PGRX_SCFG:
+		return "DCB_CMD_PGRX_SCFG"
+	case DCB_CMD_PFC_GCFG:
+		return "DCB_CMD_PFC_GCFG"
+	case DCB_CMD_PFC_SCFG:
+		return "DCB_CMD_PFC_SCFG"
+	case DCB_CMD_SET_ALL:
+		return "DCB_CMD_SET_ALL"
+	case DCB_CMD_GPERM_HWADDR:
+		return "DCB_CMD_GPERM_HWADDR"
+	case DCB_CMD_GCAP:
+		return "DCB_CMD_GCAP"
+	case DCB_CMD_GNUMTCS:
+		return "DCB_CMD_GNUMTCS"
+	case DCB_CMD_SNUMTCS:
+		return "DCB_CMD_SNUMTCS"
+	case DCB_CMD_PFC_GSTATE:
+		return "DCB_CMD_PFC_GSTATE"
+	case DCB_CMD_PFC_SSTATE:
+		return "DCB_CMD_PFC_SSTATE"
+	case DCB_CMD_BCN_GCFG:
+		return "DCB_CMD_BCN_GCFG"
+	case DCB_CMD_BCN_SCFG:
+		return "DCB_CMD_BCN_SCFG"
+	case DCB_CMD_GAPP:
+		return "DCB_CMD_GAPP"
+	case DCB_CMD_SAPP:
+		return "DCB_CMD_SAPP"
+	case DCB_CMD_IEEE_SET:
+		return "DCB_CMD_IEEE_SET"
+	case DCB_CMD_IEEE_GET:
+		return "DCB_CMD_IEEE_GET"
+	case DCB_CMD_GDCBX:
+		return "DCB_CMD_GDCBX"
+	case DCB_CMD_SDCBX:
+		return "DCB_CMD_SDCBX"
+	case DCB_CMD_GFEATCFG:
+		return "DCB_CMD_GFEATCFG"
+	case DCB_CMD_SFEATCFG:
+		return "DCB_CMD_SFEATCFG"
+	case DCB_CMD_CEE_GET:
+		return "DCB_CMD_CEE_GET"
+	case DCB_CMD_IEEE_DEL:
+		return "DCB_CMD_IEEE_DEL"
+	}
+	return "Command(" + strconv.FormatInt(int64(v), 10) + ")"
+}
+
+// enum dcbnl_attrs
+const (
+	DCB_ATTR_UNDEFINED   = 0
+	DCB_ATTR_IFNAME      = 1
+	DCB_ATTR_STATE       = 2
+	DCB_ATTR_PFC_STATE   = 3
+	DCB_ATTR_PFC_CFG     = 4
+	DCB_ATTR_NUM_TC      = 5
+	DCB_ATTR_PG_CFG      = 6
+	DCB_ATTR_SET_ALL     = 7
+	DCB_ATTR_PERM_HWADDR = 8
+	DCB_ATTR_CAP         = 9
+	DCB_ATTR_NUMTCS      = 10
+	DCB_ATTR_BCN         = 11
+	DCB_ATTR_APP         = 12
+	DCB_ATTR_IEEE        = 13
+	DCB_ATTR_DCBX        = 14
+	DCB_ATTR_FEATCFG     = 15
+	DCB_ATTR_CEE         = 16
+	DCB_ATTR_MAX         = 16
+)
+
+// Attr is a value of enum dcbnl_attrs.
+type Attr uint16
+
+func (v Attr) String() string {
+	switch v {
+	case DCB_ATTR_UNDEFINED:
+		return "DCB_ATTR_UNDEFINED"
+	case DCB_ATTR_IFNAME:
+		return "DCB_ATTR_IFNAME"
+	case DCB_ATTR_STATE:
+		return "DCB_ATTR_STATE"
+	case DCB_ATTR_PFC_STATE:
+		return "DCB_ATTR_PFC_STATE"
+	case DCB_ATTR_PFC_CFG:
+		return "DCB_ATTR_PFC_CFG"
+	case DCB_ATTR_NUM_TC:
+		return "DCB_ATTR_NUM_TC"
+	case DCB_ATTR_PG_CFG:
+		return "DCB_ATTR_PG_CFG"
+	case DCB_ATTR_SET_ALL:
+		return "DCB_ATTR_SET_ALL"
+	case DCB_ATTR_PERM_HWADDR:
+		return "DCB_ATTR_PERM_HWADDR"
+	case DCB_ATTR_CAP:
+		return "DCB_ATTR_CAP"
+	case DCB_ATTR_NUMTCS:
+		return "DCB_ATTR_NUMTCS"
+	case DCB_ATTR_BCN:
+		return "DCB_ATTR_BCN"
+	case DCB_ATTR_APP:
+		return "DCB_ATTR_APP"
+	case DCB_ATTR_IEEE:
+		return "DCB_ATTR_IEEE"
+	case DCB_ATTR_DCBX:
+		return "DCB_ATTR_DCBX"
+	case DCB_ATTR_FEATCFG:
+		return "DCB_ATTR_FEATCFG"
+	case DCB_ATTR_CEE:
+		return "DCB_ATTR_CEE"
+	}
+	return "Attr(" + strconv.FormatInt(int64(v), 10) + ")"
+}
+
+// enum ieee_attrs
+const (
+	DCB_ATTR_IEEE_UNSPEC         = 0
+	DCB_ATTR_IEEE_ETS            = 1
+	DCB_ATTR_IEEE_PFC            = 2
+	DCB_ATTR_IEEE_APP_TABLE      = 3
+	DCB_ATTR_IEEE_PEER_ETS       = 4
+	DCB_ATTR_IEEE_PEER_PFC       = 5
+	DCB_ATTR_IEEE_PEER_APP       = 6
+	DCB_ATTR_IEEE_MAXRATE        = 7
+	DCB_ATTR_IEEE_QCN            = 8
+	DCB_ATTR_IEEE_QCN_STATS      = 9
+	DCB_ATTR_DCB_BUFFER          = 10
+	DCB_ATTR_DCB_APP_TRUST_TABLE = 11
+)
+
+// IEEEAttr is a value of enum ieee_attrs.
+type IEEEAttr uint16
+
+func (v IEEEAttr) String() string {
+	switch v {
+	case DCB_ATTR_IEEE_UNSPEC:
+		return "DCB_ATTR_IEEE_UNSPEC"
+	case DCB_ATTR_IEEE_ETS:
+		return "DCB_ATTR_IEEE_ETS"
+	case DCB_ATTR_IEEE_PFC:
+		return "DCB_ATTR_IEEE_PFC"
+	case DCB_ATTR_IEEE_APP_TABLE:
+		return "DCB_ATTR_IEEE_APP_TABLE"
+	case DCB_ATTR_IEEE_PEER_ETS:
+		return "DCB_ATTR_IEEE_PEER_ETS"
+	case DCB_ATTR_IEEE_PEER_PFC:
+		return "DCB_ATTR_IEEE_PEER_PFC"
+	case DCB_ATTR_IEEE_PEER_APP:
+		return "DCB_ATTR_IEEE_PEER_APP"
+	case DCB_ATTR_IEEE_MAXRATE:
+		return "DCB_ATTR_IEEE_MAXRATE"
+	case DCB_ATTR_IEEE_QCN:
+		return "DCB_ATTR_IEEE_QCN"
+	case DCB_ATTR_IEEE_QCN_STATS:
+		return "DCB_ATTR_IEEE_QCN_STATS"
+	case DCB_ATTR_DCB_BUFFER:
+		return "DCB_ATTR_DCB_BUFFER"
+	case DCB_ATTR_DCB_APP_TRUST_TABLE:
+		return "DCB_ATTR_DCB_APP_TRUST_TABLE"
+	}
+	return "IEEEAttr(" + strconv.FormatInt(int64(v), 10) + ")"
+}
+
+const (
+	DCB_ATTR_IEEE_MAX = 11
+)
+
+// enum ieee_attrs_app
+const (
+	DCB_ATTR_IEEE_APP_UNSPEC = 0
+	DCB_ATTR_IEEE_APP        = 1
+	DCB_ATTR_DCB_APP         = 2
+)
+
+const (
+	DCB_ATTR_IEEE_APP_MAX = 2
+)
+
+// enum cee_attrs
+const (
+	DCB_ATTR_CEE_UNSPEC         = 0
+	DCB_ATTR_CEE_PEER_PG        = 1
+	DCB_ATTR_CEE_PEER_PFC       = 2
+	DCB_ATTR_CEE_PEER_APP_TABLE = 3
+	DCB_ATTR_CEE_TX_PG          = 4
+	DCB_ATTR_CEE_RX_PG          = 5
+	DCB_ATTR_CEE_PFC            = 6
+	DCB_ATTR_CEE_APP_TABLE      = 7
+	DCB_ATTR_CEE_FEAT           = 8
+)
+
+const (
+	DCB_ATTR_CEE_MAX = 8
+)
+
+// enum peer_app_attr
+const (
+	DCB_ATTR_CEE_PEER_APP_UNSPEC = 0
+	DCB_ATTR_CEE_PEER_APP_INFO   = 1
+	DCB_ATTR_CEE_PEER_APP        = 2
+)
+
+const (
+	DCB_ATTR_CEE_PEER_APP_MAX = 2
+)
+
+// enum cee_attrs_app
+const (
+	DCB_ATTR_CEE_APP_UNSPEC = 0
+	DCB_ATTR_CEE_APP        = 1
+)
+
+const (
+	DCB_ATTR_CEE_APP_MAX = 1
+)
+
+// enum dcbnl_pfc_up_attrs
+const (
+	DCB_PFC_UP_ATTR_UNDEFINED = 0
+	DCB_PFC_UP_ATTR_0         = 1
+	DCB_PFC_UP_ATTR_1         = 2
+	DCB_PFC_UP_ATTR_2         = 3
+	DCB_PFC_UP_ATTR_3         = 4
+	DCB_PFC_UP_ATTR_4         = 5
+	DCB_PFC_UP_ATTR_5         = 6
+	DCB_PFC_UP_ATTR_6         = 7
+	DCB_PFC_UP_ATTR_7         = 8
+	DCB_PFC_UP_ATTR_ALL       = 9
+	DCB_PFC_UP_ATTR_MAX       = 9
+)
+
+// enum dcbnl_pg_attrs
+const (
+	DCB_PG_ATTR_UNDEFINED = 0
+	DCB_PG_ATTR_TC_0      = 1
+	DCB_PG_ATTR_TC_1      = 2
+	DCB_PG_ATTR_TC_2      = 3
+	DCB_PG_ATTR_TC_3      = 4
+	DCB_PG_ATTR_TC_4      = 5
+	DCB_PG_ATTR_TC_5      = 6
+	DCB_PG_ATTR_TC_6      = 7
+	DCB_PG_ATTR_TC_7      = 8
+	DCB_PG_ATTR_TC_MAX    = 9
+	DCB_PG_ATTR_TC_ALL    = 10
+	DCB_PG_ATTR_BW_ID_0   = 11
+	DCB_PG_ATTR_BW_ID_1   = 12
+	DCB_PG_ATTR_BW_ID_2   = 13
+	DCB_PG_ATTR_BW_ID_3   = 14
+	DCB_PG_ATTR_BW_ID_4   = 15
+	DCB_PG_ATTR_BW_ID_5   = 16
+	DCB_PG_ATTR_BW_ID_6   = 17
+	DCB_PG_ATTR_BW_ID_7   = 18
+	DCB_PG_ATTR_BW_ID_MAX = 19
+	DCB_PG_ATTR_BW_ID_ALL = 20
+	DCB_PG_ATTR_MAX       = 20
+)
+
+// enum dcbnl_tc_attrs
+const (
+	DCB_TC_ATTR_PARAM_UNDEFINED   = 0
+	DCB_TC_ATTR_PARAM_PGID        = 1
+	DCB_TC_ATTR_PARAM_UP_MAPPING  = 2
+	DCB_TC_ATTR_PARAM_STRICT_PRIO = 3
+	DCB_TC_ATTR_PARAM_BW_PCT      = 4
+	DCB_TC_ATTR_PARAM_ALL         = 5
+	DCB_TC_ATTR_PARAM_MAX         = 5
+)
+
+// enum dcbnl_cap_attrs
+const (
+	DCB_CAP_ATTR_UNDEFINED = 0
+	DCB_CAP_ATTR_ALL       = 1
+	DCB_CAP_ATTR_PG        = 2
+	DCB_CAP_ATTR_PFC       = 3
+	DCB_CAP_ATTR_UP2TC     = 4
+	DCB_CAP_ATTR_PG_TCS    = 5
+	DCB_CAP_ATTR_PFC_TCS   = 6
+	DCB_CAP_ATTR_GSP       = 7
+	DCB_CAP_ATTR_BCN       = 8
+	DCB_CAP_ATTR_DCBX      = 9
+	DCB_CAP_ATTR_MAX       = 9
+)
+
+const (
+	DCB_CAP_DCBX_HOST        = 0x01
+	DCB_CAP_DCBX_LLD_MANAGED = 0x02
+	DCB_CAP_DCBX_VER_CEE     = 0x04
+	DCB_CAP_DCBX_VER_IEEE    = 0x08
+	DCB_CAP_DCBX_STATIC      = 0x10
+)
+
+// enum dcbnl_numtcs_attrs
+const (
+	DCB_NUMTCS_ATTR_UNDEFINED = 0
+	DCB_NUMTCS_ATTR_ALL       = 1
+	DCB_NUMTCS_ATTR_PG        = 2
+	DCB_NUMTCS_ATTR_PFC       = 3
+	DCB_NUMTCS_ATTR_MAX       = 3
+)
+
+// enum dcbnl_bcn_attrs
+const (
+	DCB_BCN_ATTR_UNDEFINED = 0
+	DCB_BCN_ATTR_RP_0      = 1
+	DCB_BCN_ATTR_RP_1      = 2
+	DCB_BCN_ATTR_RP_2      = 3
+	DCB_BCN_ATTR_RP_3      = 4
+	DCB_BCN_ATTR_RP_4      = 5
+	DCB_BCN_ATTR_RP_5      = 6
+	DCB_BCN_ATTR_RP_6      = 7
+	DCB_BCN_ATTR_RP_7      = 8
+	DCB_BCN_ATTR_RP_ALL    = 9
+	DCB_BCN_ATTR_BCNA_0    = 10
+	DCB_BCN_ATTR_BCNA_1    = 11
+	DCB_BCN_ATTR_ALPHA     = 12
+	DCB_BCN_ATTR_BETA      = 13
+	DCB_BCN_ATTR_GD        = 14
+	DCB_BCN_ATTR_GI        = 15
+	DCB_BCN_ATTR_TMAX      = 16
+	DCB_BCN_ATTR_TD        = 17
+	DCB_BCN_ATTR_RMIN      = 18
+	DCB_BCN_ATTR_W         = 19
+	DCB_BCN_ATTR_RD        = 20
+	DCB_BCN_ATTR_RU        = 21
+	DCB_BCN_ATTR_WRTT      = 22
+	DCB_BCN_ATTR_RI        = 23
+	DCB_BCN_ATTR_C         = 24
+	DCB_BCN_ATTR_ALL       = 25
+	DCB_BCN_ATTR_MAX       = 25
+)
+
+// enum dcb_general_attr_values
+const (
+	DCB_ATTR_VALUE_UNDEFINED = 255
+)
+
+const (
+	DCB_APP_IDTYPE_ETHTYPE = 0x00
+	DCB_APP_IDTYPE_PORTNUM = 0x01
+)
+
+// enum dcbnl_app_attrs
+const (
+	DCB_APP_ATTR_UNDEFINED = 0
+	DCB_APP_ATTR_IDTYPE    = 1
+	DCB_APP_ATTR_ID        = 2
+	DCB_APP_ATTR_PRIORITY  = 3
+	DCB_APP_ATTR_MAX       = 3
+)
+
+const (
+	DCB_FEATCFG_ERROR     = 0x01
+	DCB_FEATCFG_ENABLE    = 0x02
+	DCB_FEATCFG_WILLING   = 0x04
+	DCB_FEATCFG_ADVERTISE = 0x08
+)
+
+// enum dcbnl_featcfg_attrs
+const (
+	DCB_FEATCFG_ATTR_UNDEFINED = 0
+	DCB_FEATCFG_ATTR_ALL       = 1
+	DCB_FEATCFG_ATTR_PG        = 2
+	DCB_FEATCFG_ATTR_PFC       = 3
+	DCB_FEATCFG_ATTR_APP       = 4
+	DCB_FEATCFG_ATTR_MAX       = 4
+)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("dcbx", "<ifname>", "show the DCBX mode, who negotiates the config and which protocol")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			mode, err := cl.GetDCBX(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			fmt.Printf("ifname: %s\n", args[0])
+			fmt.Printf("dcbx: %s (%#x)\n", dcbxModes(mode), mode)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(setGroup, "dcbx", "<ifname> <mode>[,mode...]", "change the DCBX mode: host or lld-managed, and the version cee or ieee, static to not negotiate")
+	set.ifaceArgs = true
+	set.run = func(args []string) int {
+		if len(args) != 2 {
+			set.fs.Usage()
+			return exitUsage
+		}
+		ifname := args[0]
+		return withClient(func(cl *dcb.Client) int {
+			have, err := cl.GetDCBX(ifname)
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			mode, err := parseDCBXMode(args[1], have)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			// the mode hands the config over between the firmware and
+			// the host, so it is not subject to the owner check
+			if err := recorded(cl, cliUser(), "set dcbx", ifname, func() error { return cl.SetDCBX(ifname, mode) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Infof("ifname: %v, dcbx mode %s", ifname, dcbxModes(mode))
+			return exitOK
+		})
+	}
+}
+
+// parseDCBXMode parses comma-separated dcbxFlags names. The version bits
+// of have are kept when s names none, so `host` alone hands an IEEE port
+// over to the host as an IEEE port.
+func parseDCBXMode(s string, have uint8) (uint8, error) {
+	const versions = dcb.DCB_CAP_DCBX_VER_CEE | dcb.DCB_CAP_DCBX_VER_IEEE
+	var mode uint8
+	for _, name := range splitList(s) {
+		found := false
+		for _, f := range dcbxFlags {
+			if f.name == name {
+				mode |= f.bit
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid dcbx mode %q, want host, lld-managed, cee, ieee or static", name)
+		}
+	}
+	if mode&dcb.DCB_CAP_DCBX_HOST != 0 && mode&dcb.DCB_CAP_DCBX_LLD_MANAGED != 0 {
+		return 0, fmt.Errorf("invalid dcbx mode %q, host and lld-managed exclude each other", s)
+	}
+	if mode&versions == 0 {
+		mode |= have & versions
+	}
+	if mode&(dcb.DCB_CAP_DCBX_HOST|dcb.DCB_CAP_DCBX_LLD_MANAGED) == 0 {
+		return 0, fmt.Errorf("invalid dcbx mode %q, want host or lld-managed", strings.TrimSpace(s))
+	}
+	return mode, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("delay", "", "compute the PFC delay and headroom a link needs")
+	speed := c.fs.String("speed", "", "link speed, e.g. 25G or 100000 (Mb/s)")
+	cable := c.fs.String("cable", "", "cable length, e.g. 3m or 0.1km")
+	mtu := c.fs.Int("mtu", 9000, "MTU of the link in bytes")
+	response := c.fs.Uint64("response-bits", dcb.DefaultResponseBits, "MAC/PHY response allowance in bit times")
+	c.run = func(args []string) int {
+		if len(args) != 0 || *speed == "" || *cable == "" {
+			c.fs.Usage()
+			return exitUsage
+		}
+		p, err := delayParams(*speed, *cable, *mtu, *response)
+		if err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		printDelayEstimate(p, dcb.EstimateDelay(p))
+		return exitOK
+	}
+}
+
+func delayParams(speed, cable string, mtu int, response uint64) (dcb.DelayParams, error) {
+	s, err := parseSpeed(speed)
+	if err != nil {
+		return dcb.DelayParams{}, err
+	}
+	l, err := parseLength(cable)
+	if err != nil {
+		return dcb.DelayParams{}, err
+	}
+	if mtu <= 0 {
+		return dcb.DelayParams{}, fmt.Errorf("invalid mtu %d", mtu)
+	}
+	return dcb.DelayParams{
+		SpeedMbps:    s,
+		CableMeters:  l,
+		MTU:          mtu,
+		ResponseBits: response,
+	}, nil
+}
+
+func printDelayEstimate(p dcb.DelayParams, e dcb.DelayEstimate) {
+	fmt.Printf("link: %d Mb/s, cable %gm, mtu %d\n", p.SpeedMbps, p.CableMeters, p.MTU)
+	fmt.Printf("cable round trip: %d bit times\n", e.CableBits)
+	fmt.Printf("frames in flight: %d bit times\n", e.FrameBits)
+	fmt.Printf("mac/phy response: %d bit times\n", e.ResponseBits)
+	fmt.Printf("pfc delay: %s\n", formatDelay(e.TotalBits, p.SpeedMbps))
+	fmt.Printf("headroom: %s per lossless priority\n", formatSize(e.HeadroomBytes))
+	if !e.Fits() {
+		log.Warnf("delay %d exceeds the 16-bit ieee_pfc delay field, drivers will clamp it", e.TotalBits)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("diff-snapshot", "<a.json> <b.json>", "compare two saved snapshots field by field, exit 4 if they differ")
+	c.run = func(args []string) int {
+		if len(args) != 2 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		a, err := readSnapshots(args[0])
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		b, err := readSnapshots(args[1])
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+
+		code := exitOK
+		for _, p := range pairSnapshots(a, b) {
+			changes := dcb.Diff(p.a, p.b)
+			if len(changes) == 0 {
+				continue
+			}
+			code = exitDrift
+			fmt.Printf("ifname: %s\n", p.name)
+			for _, ch := range changes {
+				fmt.Printf("  %s\n", ch)
+			}
+		}
+		return code
+	}
+}
+
+type snapshotPair struct {
+	name string
+	a, b *dcb.Snapshot
+}
+
+// pairSnapshots matches the snapshots of two files by interface name, in
+// the order of a then b. Two single-interface files are compared with each
+// other whatever the names, to audit a NIC replacement. A snapshot missing
+// on one side is compared with an empty one.
+func pairSnapshots(a, b []*dcb.Snapshot) []snapshotPair {
+	if len(a) == 1 && len(b) == 1 {
+		name := a[0].Ifname
+		if b[0].Ifname != name {
+			name += " -> " + b[0].Ifname
+		}
+		return []snapshotPair{{name, a[0], b[0]}}
+	}
+
+	byName := map[string]*dcb.Snapshot{}
+	for _, s := range b {
+		byName[s.Ifname] = s
+	}
+	var pairs []snapshotPair
+	seen := map[string]bool{}
+	for _, s := range a {
+		other, ok := byName[s.Ifname]
+		if !ok {
+			other = &dcb.Snapshot{Ifname: s.Ifname}
+		}
+		pairs = append(pairs, snapshotPair{s.Ifname, s, other})
+		seen[s.Ifname] = true
+	}
+	for _, s := range b {
+		if !seen[s.Ifname] {
+			pairs = append(pairs, snapshotPair{s.Ifname, &dcb.Snapshot{Ifname: s.Ifname}, s})
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("drift", "[ifname...]", "watch interfaces, never changing them, and report drift from a baseline to a webhook")
+	c.ifaceArgs = true
+	webhook := c.fs.String("webhook", envOr("DCB_DRIFT_WEBHOOK", ""), "POST a JSON drift report to this URL when the drift of an interface changes (env DCB_DRIFT_WEBHOOK)")
+	baseline := c.fs.String("baseline", "", "snapshot file to compare with; if missing, the current state is recorded to it, and its interfaces are watched when none are given")
+	interval := c.fs.Duration("interval", time.Minute, "poll interval, as not every driver sends change notifications")
+	events := c.fs.Bool("events", true, "also check an interface as soon as the kernel reports a dcbnl change on it")
+	c.run = func(ifnames []string) int {
+		if *interval <= 0 || len(ifnames) == 0 && *baseline == "" {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if len(ifnames) > 0 {
+			if ifnames = global.ifaces.args(ifnames); len(ifnames) == 0 {
+				log.Error("every interface is excluded by the interface filter")
+				return exitUsage
+			}
+		}
+		return withClient(func(cl *dcb.Client) int {
+			d := &driftWatch{cl: cl, webhook: *webhook, reported: map[string]string{}}
+			if err := d.loadBaseline(*baseline, ifnames); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			var changed chan string
+			if *events {
+				w, err := dcb.WatchConfig()
+				if err != nil {
+					log.Error(err)
+					return exitNetlink
+				}
+				defer w.Close()
+				changed = make(chan string, 16)
+				go watchConfig(w, changed)
+			}
+			return d.run(*interval, changed)
+		})
+	}
+}
+
+// A driftWatch compares interfaces with their baseline and reports each
+// change of their drift, including its return to none.
+type driftWatch struct {
+	cl       *dcb.Client
+	webhook  string
+	baseline map[string]*dcb.Snapshot
+	order    []string
+	// reported is the JSON of the changes last reported per interface.
+	reported map[string]string
+}
+
+// loadBaseline reads the baseline at path, or records the state of ifnames
+// as the baseline, saving it to path if given.
+func (d *driftWatch) loadBaseline(path string, ifnames []string) error {
+	var snaps []*dcb.Snapshot
+	if path != "" {
+		var err error
+		snaps, err = readSnapshots(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if snaps == nil {
+		for _, ifname := range ifnames {
+			s, err := d.cl.Snapshot(ifname)
+			if err != nil {
+				return err
+			}
+			snaps = append(snaps, s)
+		}
+		if path != "" {
+			if err := writeSnapshots(path, snaps); err != nil {
+				return fmt.Errorf("save baseline: %w", err)
+			}
+			log.Infof("recorded baseline of %d interfaces to %s", len(snaps), path)
+		}
+	}
+	d.baseline = map[string]*dcb.Snapshot{}
+	for _, s := range snaps {
+		if len(ifnames) > 0 && !slices.Contains(ifnames, s.Ifname) || !global.ifaces.allows(s.Ifname) {
+			continue
+		}
+		d.baseline[s.Ifname] = s
+		d.order = append(d.order, s.Ifname)
+	}
+	for _, ifname := range ifnames {
+		if d.baseline[ifname] == nil {
+			return fmt.Errorf("ifname: %v, not in the baseline %s", ifname, path)
+		}
+	}
+	return nil
+}
+
+func (d *driftWatch) run(interval time.Duration, changed <-chan string) int {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, ifname := range d.order {
+			d.check(ifname)
+		}
+	inner:
+		for {
+			select {
+			case <-sigs:
+				return exitOK
+			case ifname := <-changed:
+				if ifname == "" {
+					// events lost, check all
+					break inner
+				}
+				if d.baseline[ifname] != nil {
+					d.check(ifname)
+				}
+			case <-ticker.C:
+				break inner
+			}
+		}
+	}
+}
+
+// driftSchema is the version of driftReport, see dcb.SnapshotSchema.
+const driftSchema = 1
+
+// A driftReport is the body POSTed to the webhook, described by
+// schema/drift.json.
+type driftReport struct {
+	Schema   int          `json:"schema"`
+	Time     time.Time    `json:"time"`
+	Host     string       `json:"host"`
+	Ifname   string       `json:"ifname"`
+	Baseline time.Time    `json:"baseline"`
+	Changes  []dcb.Change `json:"changes"`
+	// Resolved is set when the interface matches its baseline again.
+	Resolved bool `json:"resolved,omitempty"`
+}
+
+func (d *driftWatch) check(ifname string) {
+	base := d.baseline[ifname]
+	have, err := d.cl.SnapshotOf(ifname, snapshotObjectsOf(base)...)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	changes := dcb.Diff(base, have)
+	if changes == nil {
+		changes = []dcb.Change{}
+	}
+	key, _ := json.Marshal(changes)
+	last, seen := d.reported[ifname]
+	if string(key) == last || !seen && len(changes) == 0 {
+		return
+	}
+	d.reported[ifname] = string(key)
+
+	host, _ := os.Hostname()
+	rep := &driftReport{Schema: driftSchema, Time: time.Now(), Host: host, Ifname: ifname, Baseline: base.Time, Changes: changes, Resolved: len(changes) == 0}
+	if rep.Resolved {
+		log.Infof("ifname: %v, back to the baseline", ifname)
+	} else {
+		for _, ch := range changes {
+			log.Warnf("ifname: %v, drift: %s", ifname, ch)
+		}
+	}
+	if d.webhook != "" {
+		if err := postJSON(d.webhook, rep); err != nil {
+			log.Errorf("ifname: %v, drift webhook: %v", ifname, err)
+		}
+	}
+}
+
+// snapshotObjectsOf returns the objects s holds, so interfaces whose
+// baseline lacks an object are not reported as drifting on it.
+func snapshotObjectsOf(s *dcb.Snapshot) []dcb.Object {
+	saved := map[dcb.Object]bool{
+		dcb.ObjectDCBX:    s.DCBX != nil,
+		dcb.ObjectPFC:     s.PFC != nil,
+		dcb.ObjectETS:     s.ETS != nil,
+		dcb.ObjectMaxrate: s.Maxrate != nil,
+		dcb.ObjectApp:     s.Apps != nil,
+		dcb.ObjectBuffer:  s.Buffer != nil,
+		dcb.ObjectTrust:   s.Trust != nil,
+	}
+	var objs []dcb.Object
+	for _, obj := range dcb.SnapshotObjects {
+		if saved[obj] {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// driftWebhookTimeout bounds a webhook POST so a slow receiver cannot stall
+// the checks.
+const driftWebhookTimeout = 10 * time.Second
+
+func postJSON(url string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	hc := &http.Client{Timeout: driftWebhookTimeout}
+	resp, err := hc.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// watchConfig forwards the interfaces w reports to changed, and "" when
+// notifications were lost, until w is closed.
+func watchConfig(w *dcb.ConfigWatcher, changed chan<- string) {
+	for {
+		ifnames, err := w.Next()
+		if errors.Is(err, syscall.ENOBUFS) {
+			changed <- ""
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				log.Errorf("dcb events stopped: %v", err)
+			}
+			return
+		}
+		for _, ifname := range ifnames {
+			changed <- ifname
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("ets", "<ifname>", "show the IEEE ETS configuration")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			ets, err := cl.GetETS(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printETS(args[0], ets)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(setGroup, "ets", "<ifname>", "change the ETS algorithms and bandwidth per traffic class")
+	set.ifaceArgs = true
+	tsa := set.fs.String("tsa", "", "comma-separated algorithm per tc: strict, ets, cbs, vendor; empty entries are left as is, or give tc:algorithm pairs such as 3:ets")
+	bw := set.fs.String("bw", "", "comma-separated tx bandwidth per tc, in whole percents such as 40 or 40%, or as a rate such as 25Gbit converted to a share of the link speed; empty entries are left as is, or give tc:bandwidth pairs such as 3:40,4:20")
+	willing := set.fs.String("willing", "", "ETS willing bit: on or off")
+	recoTSA := set.fs.String("reco-tsa", "", "recommended algorithm per tc advertised to a willing peer, as -tsa")
+	recoBW := set.fs.String("reco-bw", "", "recommended tx bandwidth per tc advertised to a willing peer, in whole percents, as -bw")
+	recoPrioTC := set.fs.String("reco-prio-tc", "", "recommended tc per priority advertised to a willing peer, comma-separated or prio:tc pairs such as 3:1")
+	set.run = func(args []string) int {
+		flags := etsFlags{tsa: *tsa, bw: *bw, willing: *willing, recoTSA: *recoTSA, recoBW: *recoBW, recoPrioTC: *recoPrioTC}
+		if len(args) != 1 || flags == (etsFlags{}) {
+			set.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			ets, err := cl.GetETS(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			if err := applyETSFlags(ets, flags, linkSpeed(args[0])); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if err := audited(cl, cliUser(), "set ets", args[0], func() error { return cl.SetETS(args[0], ets) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+}
+
+// etsFlags are the values of the set ets flags, empty when not given.
+type etsFlags struct {
+	tsa, bw, willing            string
+	recoTSA, recoBW, recoPrioTC string
+}
+
+// applyETSFlags updates ets from the set ets flags and validates the result,
+// so a partial change must leave the bandwidths summing to 100.
+// Bandwidths given as rates are shares of speedMbps, 0 if the link speed is
+// unknown.
+func applyETSFlags(ets *dcb.IEEEETS, f etsFlags, speedMbps uint64) error {
+	if err := editTSA(&ets.TCTSA, f.tsa); err != nil {
+		return fmt.Errorf("tsa: %w", err)
+	}
+	if err := editBandwidth(&ets.TCTxBW, f.bw, speedMbps); err != nil {
+		return fmt.Errorf("bw: %w", err)
+	}
+	if err := editTSA(&ets.TCRecoTSA, f.recoTSA); err != nil {
+		return fmt.Errorf("reco-tsa: %w", err)
+	}
+	// the recommendation is for the link of the peer, whose speed may
+	// differ, so in percents only
+	if err := editBandwidth(&ets.TCRecoBW, f.recoBW, 0); err != nil {
+		return fmt.Errorf("reco-bw: %w", err)
+	}
+	if f.recoPrioTC != "" {
+		err := editList(f.recoPrioTC, dcb.IEEE_8021Q_MAX_PRIORITIES, func(prio int, s string) error {
+			tc, err := strconv.ParseUint(s, 10, 8)
+			if err != nil || tc >= dcb.IEEE_8021QAZ_MAX_TCS {
+				return fmt.Errorf("prio %d: invalid tc %q, want 0 to %d", prio, s, dcb.IEEE_8021QAZ_MAX_TCS-1)
+			}
+			ets.RecoPrioTC[prio] = uint8(tc)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("reco-prio-tc: %w", err)
+		}
+	}
+	switch f.willing {
+	case "":
+	case "on":
+		ets.Willing = 1
+	case "off":
+		ets.Willing = 0
+	default:
+		return fmt.Errorf("invalid willing %q, want on or off", f.willing)
+	}
+	return ets.Validate()
+}
+
+// editTSA updates the algorithms of tsa from the -tsa style list s.
+func editTSA(tsa *[dcb.IEEE_8021QAZ_MAX_TCS]dcb.TSA, s string) error {
+	if s == "" {
+		return nil
+	}
+	return editList(s, dcb.IEEE_8021QAZ_MAX_TCS, func(tc int, s string) error {
+		t, err := dcb.ParseTSA(s)
+		if err != nil {
+			return err
+		}
+		tsa[tc] = t
+		return nil
+	})
+}
+
+// editBandwidth updates the bandwidths of bw from the -bw style list s.
+func editBandwidth(bw *[dcb.IEEE_8021QAZ_MAX_TCS]uint8, s string, speedMbps uint64) error {
+	if s == "" {
+		return nil
+	}
+	return editList(s, dcb.IEEE_8021QAZ_MAX_TCS, func(tc int, s string) error {
+		v, err := parseBandwidth(s, speedMbps)
+		if err != nil {
+			return fmt.Errorf("tc %d: %w", tc, err)
+		}
+		bw[tc] = v
+		return nil
+	})
+}
+
+func splitList(s string) []string {
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func printETS(ifname string, ets *dcb.IEEEETS) {
+	fmt.Printf("ifname: %s\n", ifname)
+	fmt.Printf("ieee ets: %+v\n", ets)
+	if ets.HasReco() {
+		fmt.Printf("ets reco: tc_tsa %v tc_bw %v prio_tc %v\n", ets.TCRecoTSA, ets.TCRecoBW, ets.RecoPrioTC)
+	} else {
+		fmt.Println("ets reco: none advertised")
+	}
+}
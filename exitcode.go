@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// Exit codes of the tool, stable so scripts can branch on them.
+const (
+	exitOK         = 0
+	exitFailure    = 1 // anything not covered below
+	exitUsage      = 2 // bad flags or arguments
+	exitNotCapable = 3 // device missing or not DCB-capable
+	exitDrift      = 4 // diff/verify found a difference
+	exitNetlink    = 5 // netlink or permission error
+)
+
+// notCapableModes are the values of -not-capable.
+var notCapableModes = []string{"skip", "mark", "error"}
+
+// skipNotCapable reports whether a command over several interfaces passes
+// over the interface whose query failed with err, as -not-capable selects.
+// It is false for the other errors, and for all errors in error mode.
+func skipNotCapable(err error) bool {
+	if !isNotCapable(err) {
+		return false
+	}
+	switch global.notCapable {
+	case "skip":
+		log.Debugf("not capable, skipped: %v", err)
+		return true
+	case "mark":
+		log.Warnf("not capable, skipped: %v", err)
+		return true
+	}
+	return false
+}
+
+// markNotCapable reports whether the output lists the interfaces
+// skipNotCapable passed over.
+func markNotCapable() bool {
+	return global.notCapable == "mark"
+}
+
+// exitCode maps an error returned by the dcb library to an exit code.
+func exitCode(err error) int {
+	var he *helperError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &he) && he.Code > 0:
+		// the helper ran into it
+		return he.Code
+	case errors.As(err, new(*dcb.VerifyError)):
+		return exitDrift
+	case isNotCapable(err), errors.Is(err, dcb.ErrNoAttribute):
+		return exitNotCapable
+	case isNetlinkError(err):
+		return exitNetlink
+	}
+	return exitFailure
+}
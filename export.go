@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// exportFormats render a snapshot as the commands of another tool that
+// reproduce it.
+var exportFormats = map[string]func(s *dcb.Snapshot) []string{
+	"dcb-commands": dcbCommands,
+	"mlnx_qos":     mlnxQOSCommands,
+}
+
+func init() {
+	c := newCommand("export", "<ifname> [ifname...]", "print the iproute2 dcb or mlnx_qos commands that reproduce the DCB state of interfaces")
+	c.ifaceArgs = true
+	as := c.fs.String("as", "dcb-commands", "command set: dcb-commands for iproute2 dcb(8), or mlnx_qos")
+	provenance := c.fs.Bool("provenance", false, "comment the source of each object: local, learned from the peer through dcbx, or unknown")
+	c.run = func(ifnames []string) int {
+		if len(ifnames) == 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		render, ok := exportFormats[*as]
+		if !ok {
+			log.Errorf("unknown command set %q, want dcb-commands or mlnx_qos", *as)
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			for _, ifname := range ifnames {
+				s, err := cl.Snapshot(ifname)
+				if len(ifnames) > 1 && skipNotCapable(err) {
+					if markNotCapable() {
+						fmt.Printf("# %s not capable\n", ifname)
+					}
+					continue
+				}
+				if err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+				fmt.Printf("# %s state of %s at %s\n", *as, ifname, s.Time.Format("2006-01-02 15:04:05"))
+				if *provenance {
+					p, err := cl.Provenance(ifname, s)
+					if err != nil {
+						log.Error(err)
+						return exitCode(err)
+					}
+					for _, line := range provenanceComments(s, p) {
+						fmt.Println(line)
+					}
+				}
+				for _, line := range render(s) {
+					fmt.Println(line)
+				}
+			}
+			return exitOK
+		})
+	}
+}
+
+// provenanceComments lists the source of the objects of s, and of the APP
+// entries not set locally, as comments.
+func provenanceComments(s *dcb.Snapshot, p *dcb.Provenance) []string {
+	lines := []string{fmt.Sprintf("# config owned by %s", p.Owner)}
+	for _, obj := range dcb.SnapshotObjects {
+		if src, ok := p.Objects[obj]; ok {
+			lines = append(lines, fmt.Sprintf("# %s: %s", obj, src))
+		}
+	}
+	for i, src := range p.Apps {
+		if src != dcb.SourceLocal {
+			a := s.Apps[i]
+			lines = append(lines, fmt.Sprintf("# app %s %s:%d: %s", a.Selector, appProtocol(a), a.Priority, src))
+		}
+	}
+	return lines
+}
+
+// dcbAppKeys are the iproute2 dcb app and apptrust keywords of the
+// selectors, in the order dcb app lists them.
+var dcbAppKeys = []struct {
+	sel   dcb.Selector
+	app   string
+	trust string
+}{
+	{dcb.IEEE_8021QAZ_APP_SEL_ETHERTYPE, "ethtype-prio", "ethtype"},
+	{dcb.IEEE_8021QAZ_APP_SEL_STREAM, "stream-port-prio", "stream-port"},
+	{dcb.IEEE_8021QAZ_APP_SEL_DGRAM, "dgram-port-prio", "dgram-port"},
+	{dcb.IEEE_8021QAZ_APP_SEL_ANY, "port-prio", "port"},
+	{dcb.IEEE_8021QAZ_APP_SEL_DSCP, "dscp-prio", "dscp"},
+	{dcb.DCB_APP_SEL_PCP, "pcp-prio", "pcp"},
+}
+
+// dcbCommands renders s as iproute2 dcb(8) commands. The DCBX mode comes
+// first, as it decides whether the rest may be set, and the APP table last,
+// flushed and added back whole.
+func dcbCommands(s *dcb.Snapshot) []string {
+	dev := "dev " + s.Ifname
+	var lines []string
+	if s.DCBX != nil && *s.DCBX != 0 {
+		// the names of caps are the dcb dcbx keywords
+		lines = append(lines, "dcb dcbx set "+dev+" "+strings.ReplaceAll(dcbxModes(*s.DCBX), ",", " "))
+	}
+	if s.Trust != nil {
+		order := make([]string, len(s.Trust))
+		for i, sel := range s.Trust {
+			order[i] = trustKey(sel)
+		}
+		lines = append(lines, "dcb apptrust set "+dev+" order "+strings.Join(order, " "))
+	}
+	if e := s.ETS; e != nil {
+		cmd := fmt.Sprintf("dcb ets set %s willing %s tc-tsa %s tc-bw %s prio-tc %s", dev, onOff(e.Willing != 0),
+			dcbMap(e.TCTSA[:]), dcbMap(e.TCTxBW[:]), dcbMap(e.PrioTC[:]))
+		if e.HasReco() {
+			cmd += fmt.Sprintf(" reco-tc-tsa %s reco-tc-bw %s reco-prio-tc %s",
+				dcbMap(e.TCRecoTSA[:]), dcbMap(e.TCRecoBW[:]), dcbMap(e.RecoPrioTC[:]))
+		}
+		lines = append(lines, cmd)
+	}
+	if m := s.Maxrate; m != nil {
+		rates := make([]string, len(m.TCMaxrate))
+		for tc, r := range m.TCMaxrate {
+			// tc(8) units, 0 for unlimited
+			rates[tc] = strings.ToLower(rateWithUnit(r))
+		}
+		lines = append(lines, "dcb maxrate set "+dev+" tc-maxrate "+dcbMap(rates))
+	}
+	if p := s.PFC; p != nil {
+		prios := make([]string, dcb.IEEE_8021QAZ_MAX_TCS)
+		for prio := range prios {
+			prios[prio] = onOff(p.PFCEn&(1<<prio) != 0)
+		}
+		lines = append(lines, fmt.Sprintf("dcb pfc set %s prio-pfc %s macsec-bypass %s delay %d", dev, dcbMap(prios), onOff(p.MBC != 0), p.Delay))
+	}
+	if b := s.Buffer; b != nil {
+		lines = append(lines, fmt.Sprintf("dcb buffer set %s prio-buffer %s buffer-size %s", dev, dcbMap(b.Prio2Buffer[:]), dcbMap(b.BufferSize[:])))
+	}
+	if s.Apps != nil {
+		lines = append(lines, "dcb app flush "+dev)
+		cmd := "dcb app add " + dev
+		for _, a := range s.Apps {
+			// dcb app shows ethertype 0 as the default priority
+			if a.Selector == dcb.IEEE_8021QAZ_APP_SEL_ETHERTYPE && a.Protocol == 0 {
+				cmd += " default-prio " + strconv.Itoa(int(a.Priority))
+			}
+		}
+		for _, k := range dcbAppKeys {
+			var entries []string
+			for _, a := range s.Apps {
+				if a.Selector == k.sel && !(a.Selector == dcb.IEEE_8021QAZ_APP_SEL_ETHERTYPE && a.Protocol == 0) {
+					entries = append(entries, appProtocol(a)+":"+strconv.Itoa(int(a.Priority)))
+				}
+			}
+			if len(entries) > 0 {
+				cmd += " " + k.app + " " + strings.Join(entries, " ")
+			}
+		}
+		if cmd != "dcb app add "+dev {
+			lines = append(lines, cmd)
+		}
+	}
+	return lines
+}
+
+// mlnxQOSCommands renders s as mlnx_qos commands. What mlnx_qos has no
+// option for is listed in comments instead.
+func mlnxQOSCommands(s *dcb.Snapshot) []string {
+	cmd := "mlnx_qos -i " + s.Ifname
+	var lines, missing []string
+	if s.DCBX != nil {
+		missing = append(missing, "dcbx mode")
+	}
+	if s.Trust != nil {
+		switch {
+		case slices.Equal(s.Trust, []dcb.Selector{dcb.IEEE_8021QAZ_APP_SEL_DSCP}):
+			cmd += " --trust dscp"
+		case slices.Equal(s.Trust, []dcb.Selector{dcb.DCB_APP_SEL_PCP}):
+			cmd += " --trust pcp"
+		default:
+			missing = append(missing, "trust order "+fmt.Sprint(s.Trust))
+		}
+	}
+	if p := s.PFC; p != nil {
+		en := make([]string, dcb.IEEE_8021QAZ_MAX_TCS)
+		for prio := range en {
+			en[prio] = strconv.Itoa(int(p.PFCEn >> prio & 1))
+		}
+		cmd += " --pfc " + strings.Join(en, ",")
+		if p.MBC != 0 || p.Delay != 0 {
+			missing = append(missing, "pfc macsec bypass and delay")
+		}
+	}
+	if e := s.ETS; e != nil {
+		tsa := make([]string, len(e.TCTSA))
+		for tc, t := range e.TCTSA {
+			tsa[tc] = t.String()
+		}
+		cmd += " --prio_tc " + commaList(e.PrioTC[:]) + " --tsa " + strings.Join(tsa, ",") + " --tcbw " + commaList(e.TCTxBW[:])
+		if e.Willing != 0 {
+			missing = append(missing, "ets willing")
+		}
+	}
+	if m := s.Maxrate; m != nil {
+		rates := make([]string, len(m.TCMaxrate))
+		for tc, r := range m.TCMaxrate {
+			// Gb/s, 0 for unlimited
+			rates[tc] = formatDecimal(r, 1e6)
+		}
+		cmd += " --ratelimit " + strings.Join(rates, ",")
+	}
+	if b := s.Buffer; b != nil {
+		cmd += " --prio2buffer " + commaList(b.Prio2Buffer[:]) + " --buffer_size " + commaList(b.BufferSize[:])
+	}
+	if cmd != "mlnx_qos -i "+s.Ifname {
+		lines = append(lines, cmd)
+	}
+	for _, a := range s.Apps {
+		if a.Selector != dcb.IEEE_8021QAZ_APP_SEL_DSCP {
+			missing = append(missing, fmt.Sprintf("app %s %s:%d", a.Selector, appProtocol(a), a.Priority))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("mlnx_qos -i %s --dscp2prio set,%d,%d", s.Ifname, a.Protocol, a.Priority))
+	}
+	for _, m := range missing {
+		lines = append(lines, "# not expressible with mlnx_qos: "+m)
+	}
+	return lines
+}
+
+// dcbMap formats v as an iproute2 dcb map of index:value pairs.
+func dcbMap[T any](v []T) string {
+	pairs := make([]string, len(v))
+	for i, x := range v {
+		pairs[i] = fmt.Sprintf("%d:%v", i, x)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func commaList[T uint8 | uint32](v []T) string {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i] = strconv.FormatUint(uint64(x), 10)
+	}
+	return strings.Join(s, ",")
+}
+
+// trustKey returns the dcb apptrust keyword of sel, or the selector number
+// if it has none.
+func trustKey(sel dcb.Selector) string {
+	for _, k := range dcbAppKeys {
+		if k.sel == sel {
+			return k.trust
+		}
+	}
+	return strconv.Itoa(int(sel))
+}
+
+// appProtocol formats the protocol of a the way dcb app reads it: ethertypes
+// in hex, PCP entries as the priority code point with nd or de for the drop
+// eligible indicator, the others in decimal.
+func appProtocol(a dcb.App) string {
+	switch a.Selector {
+	case dcb.IEEE_8021QAZ_APP_SEL_ETHERTYPE:
+		return fmt.Sprintf("%#x", a.Protocol)
+	case dcb.DCB_APP_SEL_PCP:
+		dei := "nd"
+		if a.Protocol&8 != 0 {
+			dei = "de"
+		}
+		return strconv.Itoa(int(a.Protocol&7)) + dei
+	}
+	return strconv.Itoa(int(a.Protocol))
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fanzu8/go-dcb/api/dcbpb"
+	"github.com/fanzu8/go-dcb/dcb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func init() {
+	c := newCommand("fleet", "<target> [target...]", "collect the DCB state of many hosts from their serve daemons and report the settings that differ across them")
+	hostsFile := c.fs.String("hosts", "", "file of further targets, one per line, with # comments")
+	ifnames := c.fs.String("ifname", "", "comma-separated interfaces to query on targets that name none")
+	tokenFile := c.fs.String("token-file", envOr("DCB_TOKEN_FILE", ""), "file holding the bearer token of the REST and gRPC APIs (env DCB_TOKEN_FILE)")
+	timeout := c.fs.Duration("timeout", 10*time.Second, "timeout of the queries of a target")
+	parallel := c.fs.Int("parallel", 16, "targets queried at once")
+	output := c.fs.String("output", "text", "output format: text, or json")
+	c.fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s fleet [flags] <target> [target...]\n\n%s\n\n", progName(), c.help)
+		fmt.Fprintf(os.Stderr, "A target is http://host:port or grpc://host:port, optionally followed by\n/ifname,ifname to name the interfaces to query there instead of -ifname.\n\n")
+		c.fs.PrintDefaults()
+	}
+	c.run = func(args []string) int {
+		if *hostsFile != "" {
+			more, err := readTargets(*hostsFile)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			args = append(args, more...)
+		}
+		if len(args) == 0 || *parallel <= 0 || *timeout <= 0 || (*output != "text" && *output != "json") {
+			c.fs.Usage()
+			return exitUsage
+		}
+		var defaults []string
+		if *ifnames != "" {
+			defaults = strings.Split(*ifnames, ",")
+		}
+		targets := make([]fleetTarget, len(args))
+		for i, arg := range args {
+			t, err := parseFleetTarget(arg, defaults)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			targets[i] = t
+		}
+		token := &httpToken{path: *tokenFile}
+		if err := token.load(); err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+
+		states := collectFleet(targets, token.get(), *timeout, *parallel)
+		report := fleetReport(states)
+		if *output == "json" {
+			if err := printJSON(report, true); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+		} else {
+			printFleetReport(report)
+		}
+		switch {
+		case len(report.Inconsistencies) > 0:
+			return exitDrift
+		case len(report.Unreachable) > 0:
+			return exitFailure
+		}
+		return exitOK
+	}
+}
+
+// A fleetTarget is a serve daemon and the interfaces to query there.
+type fleetTarget struct {
+	scheme string // http or grpc
+	addr   string
+	// ifnames are the interfaces to query.
+	ifnames []string
+}
+
+func (t fleetTarget) String() string {
+	return t.scheme + "://" + t.addr
+}
+
+// parseFleetTarget parses a target, scheme://host:port[/ifname,...],
+// querying ifnames if it names no interface.
+func parseFleetTarget(s string, ifnames []string) (fleetTarget, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || (scheme != "http" && scheme != "grpc") {
+		return fleetTarget{}, fmt.Errorf("invalid target %q, want http:// or grpc://host:port[/ifname,...]", s)
+	}
+	t := fleetTarget{scheme: scheme, ifnames: ifnames}
+	addr, list, ok := strings.Cut(rest, "/")
+	if ok && list != "" {
+		t.ifnames = strings.Split(list, ",")
+	}
+	t.addr = addr
+	if t.addr == "" || len(t.ifnames) == 0 {
+		return fleetTarget{}, fmt.Errorf("target %q: want host:port and interfaces, after the address or with -ifname", s)
+	}
+	return t, nil
+}
+
+// readTargets reads the targets of a -hosts file.
+func readTargets(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var targets []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return targets, nil
+}
+
+// A fleetState is what a target reported, or failed to.
+type fleetState struct {
+	target fleetTarget
+	snaps  []*dcb.Snapshot
+	err    error
+}
+
+// collectFleet queries the targets, parallel at once.
+func collectFleet(targets []fleetTarget, token string, timeout time.Duration, parallel int) []fleetState {
+	states := make([]fleetState, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			snaps, err := fetchFleetTarget(ctx, t, token)
+			states[i] = fleetState{target: t, snaps: snaps, err: err}
+		}()
+	}
+	wg.Wait()
+	return states
+}
+
+func fetchFleetTarget(ctx context.Context, t fleetTarget, token string) ([]*dcb.Snapshot, error) {
+	var snaps []*dcb.Snapshot
+	if t.scheme == "grpc" {
+		conn, err := grpc.NewClient(t.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		defer conn.Close()
+		client := dcbpb.NewDCBClient(conn)
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		for _, ifname := range t.ifnames {
+			cfg, err := client.Get(ctx, &dcbpb.GetRequest{Ifname: ifname})
+			if err != nil {
+				return nil, fmt.Errorf("%s: ifname: %v, %w", t, ifname, err)
+			}
+			snaps = append(snaps, snapshotFromPB(cfg))
+		}
+		return snaps, nil
+	}
+	for _, ifname := range t.ifnames {
+		url := "http://" + t.addr + "/v1/interfaces/" + ifname
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var s dcb.Snapshot
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %s", url, resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&s)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+		snaps = append(snaps, &s)
+	}
+	return snaps, nil
+}
+
+// fleetSettings are the settings compared across the fleet, each rendered
+// as a string, empty when the interface does not report it. They are
+// those that must agree end to end for lossless traffic.
+var fleetSettings = []struct {
+	name  string
+	value func(s *dcb.Snapshot) string
+}{
+	{"roce prio", func(s *dcb.Snapshot) string {
+		for _, a := range s.Apps {
+			if (a.Selector == dcb.IEEE_8021QAZ_APP_SEL_DGRAM || a.Selector == dcb.IEEE_8021QAZ_APP_SEL_ANY) && a.Protocol == dcb.RoCEv2Port {
+				return fmt.Sprint(a.Priority)
+			}
+		}
+		return "none"
+	}},
+	{"pfc", func(s *dcb.Snapshot) string {
+		if s.PFC == nil {
+			return ""
+		}
+		return fmt.Sprint(s.PFC.Enabled())
+	}},
+	{"prio_tc", func(s *dcb.Snapshot) string {
+		if s.ETS == nil {
+			return ""
+		}
+		return fmt.Sprint(s.ETS.PrioTC)
+	}},
+	{"tc_tsa", func(s *dcb.Snapshot) string {
+		if s.ETS == nil {
+			return ""
+		}
+		return fmt.Sprint(s.ETS.TCTSA)
+	}},
+	{"tc_bw", func(s *dcb.Snapshot) string {
+		if s.ETS == nil {
+			return ""
+		}
+		return fmt.Sprint(s.ETS.TCTxBW)
+	}},
+	{"ets willing", func(s *dcb.Snapshot) string {
+		if s.ETS == nil {
+			return ""
+		}
+		return onOff(s.ETS.Willing != 0)
+	}},
+	{"trust", func(s *dcb.Snapshot) string {
+		if s.Trust == nil {
+			return ""
+		}
+		return fmt.Sprint(s.Trust)
+	}},
+	{"dscp map", func(s *dcb.Snapshot) string {
+		var m []string
+		for _, a := range s.Apps {
+			if a.Selector == dcb.IEEE_8021QAZ_APP_SEL_DSCP {
+				m = append(m, fmt.Sprintf("%d:%d", a.Protocol, a.Priority))
+			}
+		}
+		return strings.Join(m, " ")
+	}},
+	{"dcbx", func(s *dcb.Snapshot) string {
+		if s.DCBX == nil {
+			return ""
+		}
+		return dcbxModes(*s.DCBX)
+	}},
+}
+
+// A fleetSummary is the outcome of a fleet query.
+type fleetSummary struct {
+	Hosts      int `json:"hosts"`
+	Interfaces int `json:"interfaces"`
+	// Unreachable holds the targets that failed, with their error.
+	Unreachable map[string]string `json:"unreachable,omitempty"`
+	// Inconsistencies holds the settings not all interfaces agree on.
+	Inconsistencies []fleetInconsistency `json:"inconsistencies"`
+}
+
+// A fleetInconsistency is a setting whose value differs across the fleet:
+// the value most interfaces have, and the interfaces with another.
+type fleetInconsistency struct {
+	Setting  string `json:"setting"`
+	Majority string `json:"majority"`
+	Count    int    `json:"count"`
+	// Differ maps target/ifname to the value there.
+	Differ map[string]string `json:"differ"`
+}
+
+// fleetReport compares the settings of the interfaces of states, against
+// the most common value of each.
+func fleetReport(states []fleetState) *fleetSummary {
+	r := &fleetSummary{Hosts: len(states), Inconsistencies: []fleetInconsistency{}}
+	type port struct {
+		name string
+		snap *dcb.Snapshot
+	}
+	var ports []port
+	for _, st := range states {
+		if st.err != nil {
+			if r.Unreachable == nil {
+				r.Unreachable = map[string]string{}
+			}
+			r.Unreachable[st.target.String()] = st.err.Error()
+			continue
+		}
+		for _, s := range st.snaps {
+			ports = append(ports, port{st.target.addr + "/" + s.Ifname, s})
+		}
+	}
+	r.Interfaces = len(ports)
+	for _, setting := range fleetSettings {
+		counts := map[string]int{}
+		values := make([]string, len(ports))
+		for i, p := range ports {
+			values[i] = setting.value(p.snap)
+			if values[i] != "" {
+				counts[values[i]]++
+			}
+		}
+		if len(counts) < 2 {
+			continue
+		}
+		keys := make([]string, 0, len(counts))
+		for v := range counts {
+			keys = append(keys, v)
+		}
+		// ties go to the smaller value, so runs report alike
+		slices.Sort(keys)
+		majority := keys[0]
+		for _, v := range keys {
+			if counts[v] > counts[majority] {
+				majority = v
+			}
+		}
+		inc := fleetInconsistency{Setting: setting.name, Majority: majority, Count: counts[majority], Differ: map[string]string{}}
+		for i, p := range ports {
+			if values[i] != "" && values[i] != majority {
+				inc.Differ[p.name] = values[i]
+			}
+		}
+		r.Inconsistencies = append(r.Inconsistencies, inc)
+	}
+	return r
+}
+
+func printFleetReport(r *fleetSummary) {
+	fmt.Printf("hosts: %d, interfaces: %d, unreachable: %d\n", r.Hosts, r.Interfaces, len(r.Unreachable))
+	for _, target := range sortedKeys(r.Unreachable) {
+		fmt.Printf("unreachable %s: %s\n", target, r.Unreachable[target])
+	}
+	if len(r.Inconsistencies) == 0 {
+		fmt.Println("consistent")
+		return
+	}
+	for _, inc := range r.Inconsistencies {
+		fmt.Printf("%s: %s on %d interfaces, differs on %d\n", inc.Setting, inc.Majority, inc.Count, len(inc.Differ))
+		for _, name := range sortedKeys(inc.Differ) {
+			fmt.Printf("  %s: %s\n", name, inc.Differ[name])
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
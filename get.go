@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("get", "<ifname> [ifname...]", "show the IEEE PFC configuration and counters")
+	c.ifaceArgs = true
+	all := c.fs.Bool("all", false, "query every interface of the host")
+	concurrency := c.fs.Int("concurrency", dcb.DefaultConcurrency, "maximum number of interfaces queried in parallel")
+	vfs := c.fs.Bool("vfs", false, "with -all, include SR-IOV virtual functions, which are skipped by default")
+	pci := c.fs.String("pci", "", "query the interfaces whose PCI address matches this pattern, e.g. 0000:3b:00.*")
+	group := c.fs.Bool("group", false, "group the output by physical adapter")
+	pf := c.fs.Bool("pf", false, "query the physical function of SR-IOV virtual functions instead")
+	output := c.fs.String("output", "text", "output format: text, or csv with a row per interface and priority")
+	statePath := c.fs.String("state", "", "keep the counters in this file and show the change since the previous run, for cron jobs")
+	counters := registerCounters(c.fs)
+	c.run = func(ifnames []string) int {
+		if *output != "text" && *output != "csv" {
+			log.Errorf("unknown output format %q", *output)
+			return exitUsage
+		}
+		if !slices.Contains(counterSources, *counters) {
+			log.Errorf("unknown counter source %q", *counters)
+			return exitUsage
+		}
+		if *all {
+			ifaces, err := hostInterfaces()
+			if err != nil {
+				log.Errorf("list interfaces: %v", err)
+				return exitNetlink
+			}
+			ifnames = ifnames[:0]
+			for _, ifname := range ifaces {
+				if !*vfs && dcb.IsVF(ifname) {
+					log.Debugf("ifname: %v, sr-iov vf, skipped", ifname)
+					continue
+				}
+				ifnames = append(ifnames, ifname)
+			}
+		}
+		if *pci != "" {
+			byPCI, err := dcb.InterfacesByPCI(*pci)
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if len(byPCI) == 0 {
+				log.Errorf("no interface matches pci address %q", *pci)
+				return exitNotCapable
+			}
+			slices.SortFunc(byPCI, dcb.CompareIfnames)
+			ifnames = append(ifnames, byPCI...)
+		}
+		if *pf {
+			var err error
+			if ifnames, err = physFns(ifnames); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+		}
+		if len(ifnames) == 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if *group {
+			ifnames = byAdapter(ifnames)
+		}
+
+		cl, err := dial(min(*concurrency, len(ifnames)))
+		if err != nil {
+			log.Error(err)
+			return exitNetlink
+		}
+		defer cl.Close()
+
+		var state counterState
+		if *statePath != "" {
+			if state, err = loadCounterState(*statePath); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			defer func() {
+				if err := state.save(*statePath); err != nil {
+					log.Errorf("save counter state: %v", err)
+				}
+			}()
+		}
+		show := func(ifname string, pfc *dcb.IEEEPFC) {
+			printPFC(ifname, pfc, linkInfo(cl, ifname))
+			if state != nil {
+				printDelta(ifname, state.update(ifname, time.Now(), pfc))
+			}
+		}
+		if *output == "csv" {
+			w := newStatsCSV(os.Stdout)
+			defer w.flush()
+			show = func(ifname string, pfc *dcb.IEEEPFC) {
+				now := time.Now()
+				w.write(now, ifname, pfc)
+				if state != nil {
+					state.update(ifname, now, pfc)
+				}
+			}
+		}
+		adapter := "-"
+		withCounters := func(ifname string, pfc *dcb.IEEEPFC) {
+			applyCounterSource(*counters, ifname, pfc)
+			if a := dcb.Adapter(ifname); *group && *output == "text" && a != adapter {
+				printAdapter(a, ifname)
+				adapter = a
+			}
+			show(ifname, pfc)
+		}
+		if len(ifnames) == 1 && !*all && *pci == "" {
+			return getOne(cl, ifnames[0], withCounters)
+		}
+		mark := func(ifname string) { fmt.Printf("ifname: %s\nnot capable\n", ifname) }
+		if *output != "text" {
+			mark = nil
+		}
+		return getMany(cl, ifnames, *concurrency, withCounters, mark)
+	}
+}
+
+func getOne(cl *dcb.Client, ifname string, show func(string, *dcb.IEEEPFC)) int {
+	pfc, err := cl.GetPFC(ifname)
+	if err != nil {
+		log.Error(err)
+		if isNotCapable(err) && dcb.IsVF(ifname) {
+			log.Warnf("ifname: %v, sr-iov vf, dcb is configured on its pf, see -pf", ifname)
+		}
+		return exitCode(err)
+	}
+	show(ifname, pfc)
+	return exitOK
+}
+
+// getMany shows the PFC state of ifnames, passing over those without DCB
+// support as -not-capable selects, and listing them with mark, if not nil,
+// when marked.
+func getMany(cl *dcb.Client, ifnames []string, concurrency int, show func(string, *dcb.IEEEPFC), mark func(string)) int {
+	results, _ := cl.GetMany(ifnames, concurrency)
+
+	code := exitOK
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			if skipNotCapable(r.Err) {
+				if mark != nil && markNotCapable() {
+					mark(r.Ifname)
+				}
+				continue
+			}
+			log.Error(r.Err)
+			failed++
+			if c := exitCode(r.Err); c > code {
+				code = c
+			}
+			continue
+		}
+		show(r.Ifname, r.PFC)
+	}
+	if failed > 0 {
+		log.Errorf("%d of %d interfaces failed", failed, len(results))
+	}
+	return code
+}
+
+// byAdapter sorts ifnames by physical adapter, keeping the order of the
+// interfaces of an adapter; virtual interfaces go last.
+func byAdapter(ifnames []string) []string {
+	out := slices.Clone(ifnames)
+	slices.SortStableFunc(out, func(a, b string) int {
+		aa, ab := dcb.Adapter(a), dcb.Adapter(b)
+		switch {
+		case aa == ab:
+			return 0
+		case aa == "":
+			return 1
+		case ab == "":
+			return -1
+		}
+		return strings.Compare(aa, ab)
+	})
+	return out
+}
+
+func printAdapter(adapter, ifname string) {
+	switch {
+	case adapter == "":
+		fmt.Println("adapter: none (virtual interfaces)")
+	case dcb.PhysPortID(ifname) != "":
+		fmt.Printf("adapter: pci %s (phys_port_id %s)\n", adapter, dcb.PhysPortID(ifname))
+	default:
+		fmt.Printf("adapter: pci %s\n", adapter)
+	}
+}
+
+// physFns replaces the SR-IOV VFs in ifnames by their PF, dropping
+// duplicates.
+func physFns(ifnames []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, ifname := range ifnames {
+		if dcb.IsVF(ifname) {
+			pf, err := dcb.PhysFn(ifname)
+			if err != nil {
+				return nil, err
+			}
+			log.Infof("ifname: %v, sr-iov vf of %v, querying the pf", ifname, pf)
+			ifname = pf
+		}
+		if !seen[ifname] {
+			seen[ifname] = true
+			out = append(out, ifname)
+		}
+	}
+	return out, nil
+}
+
+func printPFC(ifname string, pfc *dcb.IEEEPFC, li *dcb.LinkInfo) {
+	fmt.Printf("ifname: %s\n", ifname)
+	if li != nil {
+		printLink(li)
+	}
+	fmt.Printf("pfc_cap: %d (traffic classes that can have pfc enabled at once)\n", pfc.PFCCap)
+	fmt.Printf("pfc_en: %#x (prios %v)\n", pfc.PFCEn, pfc.Enabled())
+	fmt.Printf("mbc: %s (macsec bypass capability, on when the station cannot bypass macsec processing with macsec disabled)\n", onOff(pfc.MBC != 0))
+	fmt.Println("pfc counters:")
+	var requests, indications uint64
+	for prio := range pfc.Requests {
+		fmt.Printf("  prio %s: requests %d indications %d\n", global.labels.name(prio), pfc.Requests[prio], pfc.Indications[prio])
+		requests += pfc.Requests[prio]
+		indications += pfc.Indications[prio]
+	}
+	fmt.Printf("  total: requests %d indications %d\n", requests, indications)
+	var speed uint64
+	if li != nil {
+		speed = li.Speed
+	}
+	fmt.Printf("pfc delay: %s\n", formatDelay(uint64(pfc.Delay), speed))
+}
+
+func printDelta(ifname string, d *counterDelta) {
+	if d == nil {
+		fmt.Println("pfc delta: first run, no previous counters")
+		return
+	}
+	if d.Reset {
+		log.Warnf("ifname: %v, pfc counters reset since %v, driver reloaded?", ifname, d.Since.Format(time.RFC3339))
+	}
+	fmt.Printf("pfc delta since %s: requests %s, indications %s\n", d.Since.Format(time.RFC3339),
+		global.labels.counters(d.Requests), global.labels.counters(d.Indications))
+}
+
+// hostInterfaces lists the interfaces of the host, ordered by name, over a
+// socket of its own, as the pool is sized by the interfaces found.
+func hostInterfaces() ([]string, error) {
+	cl, err := dial(1)
+	if err != nil {
+		return nil, err
+	}
+	defer cl.Close()
+	ifnames, err := cl.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(ifnames, dcb.CompareIfnames)
+	return ifnames, nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/api/dcbpb"
+	"github.com/fanzu8/go-dcb/dcb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer implements the DCB service on top of a shared Client.
+type grpcServer struct {
+	dcbpb.UnimplementedDCBServer
+	cl *dcb.Client
+}
+
+// newGRPCServer returns the gRPC API. As the REST API, calls must carry
+// "authorization: Bearer <token>" metadata unless the token is empty.
+func newGRPCServer(cl *dcb.Client, token *httpToken) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := grpcAuthorized(ctx, token); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := grpcAuthorized(ss.Context(), token); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	dcbpb.RegisterDCBServer(s, &grpcServer{cl: cl})
+	return s
+}
+
+// grpcAuthorized checks the bearer token of the call against token.
+func grpcAuthorized(ctx context.Context, token *httpToken) error {
+	want := token.get()
+	if want == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		got, ok := strings.CutPrefix(v, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+func (s *grpcServer) Get(_ context.Context, req *dcbpb.GetRequest) (*dcbpb.Config, error) {
+	if err := grpcAllowed(req.GetIfname()); err != nil {
+		return nil, err
+	}
+	snap, err := s.cl.Snapshot(req.GetIfname())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return snapshotToPB(snap), nil
+}
+
+func (s *grpcServer) Set(ctx context.Context, req *dcbpb.SetRequest) (*dcbpb.SetResponse, error) {
+	cfg := req.GetConfig()
+	if cfg.GetIfname() == "" {
+		return nil, status.Error(codes.InvalidArgument, "config.ifname is required")
+	}
+	if err := grpcAllowed(cfg.GetIfname()); err != nil {
+		return nil, err
+	}
+	var skipped []dcb.Object
+	err := audited(s.cl, grpcPeer(ctx), "grpc Set", cfg.GetIfname(), func() error {
+		var err error
+		skipped, err = restore(s.cl, cfg.GetIfname(), snapshotFromPB(cfg))
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &dcbpb.SetResponse{}
+	for _, obj := range skipped {
+		resp.Skipped = append(resp.Skipped, string(obj))
+	}
+	log.Infof("ifname: %v, config set over grpc", cfg.GetIfname())
+	return resp, nil
+}
+
+func (s *grpcServer) Watch(req *dcbpb.WatchRequest, stream grpc.ServerStreamingServer[dcbpb.Config]) error {
+	if err := grpcAllowed(req.GetIfname()); err != nil {
+		return err
+	}
+	interval := time.Second
+	if ms := req.GetIntervalMs(); ms > 0 {
+		interval = time.Duration(ms) * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *dcb.Snapshot
+	for {
+		snap, err := s.cl.Snapshot(req.GetIfname())
+		if err != nil {
+			return grpcError(err)
+		}
+		if last == nil || len(dcb.Diff(last, snap)) > 0 {
+			if err := stream.Send(snapshotToPB(snap)); err != nil {
+				return err
+			}
+			last = snap
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// grpcAllowed fails RPCs on interfaces the interface filter excludes.
+func grpcAllowed(ifname string) error {
+	if !global.ifaces.allows(ifname) {
+		return status.Errorf(codes.PermissionDenied, "ifname: %v, excluded by the interface filter", ifname)
+	}
+	return nil
+}
+
+// grpcPeer identifies the client of an RPC for the audit log.
+func grpcPeer(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil && p.Addr.String() != "" {
+		return "grpc " + p.Addr.String()
+	}
+	return "grpc"
+}
+
+// grpcError maps library errors to status codes, along the lines of
+// exitCode.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, syscall.ENODEV):
+		return status.Error(codes.NotFound, err.Error())
+	case isNotCapable(err), errors.Is(err, dcb.ErrNoAttribute):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, syscall.EINVAL), errors.Is(err, syscall.ERANGE):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, new(*dcb.OwnerError)):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func snapshotToPB(s *dcb.Snapshot) *dcbpb.Config {
+	cfg := &dcbpb.Config{Ifname: s.Ifname, Time: timestamppb.New(s.Time)}
+	if s.DCBX != nil {
+		mode := uint32(*s.DCBX)
+		cfg.Dcbx = &mode
+	}
+	if s.PFC != nil {
+		cfg.Pfc = pfcToPB(s.PFC)
+	}
+	if e := s.ETS; e != nil {
+		cfg.Ets = &dcbpb.ETS{
+			Willing:    uint32(e.Willing),
+			EtsCap:     uint32(e.ETSCap),
+			Cbs:        uint32(e.CBS),
+			TcTxBw:     widen(e.TCTxBW[:]),
+			TcRxBw:     widen(e.TCRxBW[:]),
+			TcTsa:      widen(e.TCTSA[:]),
+			PrioTc:     widen(e.PrioTC[:]),
+			TcRecoBw:   widen(e.TCRecoBW[:]),
+			TcRecoTsa:  widen(e.TCRecoTSA[:]),
+			RecoPrioTc: widen(e.RecoPrioTC[:]),
+		}
+	}
+	if m := s.Maxrate; m != nil {
+		cfg.Maxrate = &dcbpb.Maxrate{TcMaxrate: m.TCMaxrate[:]}
+	}
+	if s.Apps != nil {
+		cfg.AppTable = &dcbpb.AppTable{}
+		for _, a := range s.Apps {
+			cfg.AppTable.Apps = append(cfg.AppTable.Apps, &dcbpb.App{
+				Selector: uint32(a.Selector),
+				Priority: uint32(a.Priority),
+				Protocol: uint32(a.Protocol),
+			})
+		}
+	}
+	if b := s.Buffer; b != nil {
+		cfg.Buffer = &dcbpb.Buffer{
+			Prio2Buffer: widen(b.Prio2Buffer[:]),
+			BufferSize:  b.BufferSize[:],
+			TotalSize:   b.TotalSize,
+		}
+	}
+	if s.Trust != nil {
+		cfg.Trust = &dcbpb.Trust{Selectors: widen(s.Trust)}
+	}
+	return cfg
+}
+
+func pfcToPB(p *dcb.IEEEPFC) *dcbpb.PFC {
+	return &dcbpb.PFC{
+		PfcCap:      uint32(p.PFCCap),
+		PfcEn:       uint32(p.PFCEn),
+		Mbc:         uint32(p.MBC),
+		Delay:       uint32(p.Delay),
+		Requests:    p.Requests[:],
+		Indications: p.Indications[:],
+	}
+}
+
+// snapshotFromPB converts a config to the snapshot Restore applies. Objects
+// absent from cfg stay nil and are left untouched.
+func snapshotFromPB(cfg *dcbpb.Config) *dcb.Snapshot {
+	s := &dcb.Snapshot{Ifname: cfg.GetIfname(), Time: cfg.GetTime().AsTime()}
+	if cfg.Dcbx != nil {
+		mode := uint8(cfg.GetDcbx())
+		s.DCBX = &mode
+	}
+	if p := cfg.GetPfc(); p != nil {
+		s.PFC = &dcb.IEEEPFC{
+			PFCCap: uint8(p.GetPfcCap()),
+			PFCEn:  uint8(p.GetPfcEn()),
+			MBC:    uint8(p.GetMbc()),
+			Delay:  uint16(p.GetDelay()),
+		}
+	}
+	if e := cfg.GetEts(); e != nil {
+		s.ETS = &dcb.IEEEETS{
+			Willing: uint8(e.GetWilling()),
+			ETSCap:  uint8(e.GetEtsCap()),
+			CBS:     uint8(e.GetCbs()),
+		}
+		narrow(s.ETS.TCTxBW[:], e.GetTcTxBw())
+		narrow(s.ETS.TCRxBW[:], e.GetTcRxBw())
+		narrow(s.ETS.TCTSA[:], e.GetTcTsa())
+		narrow(s.ETS.PrioTC[:], e.GetPrioTc())
+		narrow(s.ETS.TCRecoBW[:], e.GetTcRecoBw())
+		narrow(s.ETS.TCRecoTSA[:], e.GetTcRecoTsa())
+		narrow(s.ETS.RecoPrioTC[:], e.GetRecoPrioTc())
+	}
+	if m := cfg.GetMaxrate(); m != nil {
+		s.Maxrate = &dcb.IEEEMaxrate{}
+		copy(s.Maxrate.TCMaxrate[:], m.GetTcMaxrate())
+	}
+	if t := cfg.GetAppTable(); t != nil {
+		s.Apps = []dcb.App{}
+		for _, a := range t.GetApps() {
+			s.Apps = append(s.Apps, dcb.App{
+				Selector: dcb.Selector(a.GetSelector()),
+				Priority: uint8(a.GetPriority()),
+				Protocol: uint16(a.GetProtocol()),
+			})
+		}
+	}
+	if b := cfg.GetBuffer(); b != nil {
+		s.Buffer = &dcb.Buffer{TotalSize: b.GetTotalSize()}
+		narrow(s.Buffer.Prio2Buffer[:], b.GetPrio2Buffer())
+		copy(s.Buffer.BufferSize[:], b.GetBufferSize())
+	}
+	if t := cfg.GetTrust(); t != nil {
+		s.Trust = make([]dcb.Selector, len(t.GetSelectors()))
+		narrow(s.Trust, t.GetSelectors())
+	}
+	return s
+}
+
+// widen converts the u8 arrays of the kernel structs to proto repeated
+// uint32 fields.
+func widen[T ~uint8](v []T) []uint32 {
+	out := make([]uint32, len(v))
+	for i, x := range v {
+		out[i] = uint32(x)
+	}
+	return out
+}
+
+// narrow copies the proto values in src into the u8 array dst, dropping
+// any excess.
+func narrow[T ~uint8](dst []T, src []uint32) {
+	for i := 0; i < len(dst) && i < len(src); i++ {
+		dst[i] = T(src[i])
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("health", "[ifname...]", "summarize the DCB posture of the physical ports of the host, for triage")
+	c.ifaceArgs = true
+	window := c.fs.Duration("window", 5*time.Second, "window the PFC counters are sampled over for storms, 0 to skip the storm check")
+	stormRate := c.fs.Float64("storm-rate", 1000, "rate of received PFC frames per second on a priority reported as a storm")
+	output := c.fs.String("output", "text", "output format: text, or json")
+	counters := registerCounters(c.fs)
+	c.run = func(ifnames []string) int {
+		if *window < 0 || (*output != "text" && *output != "json") || !slices.Contains(counterSources, *counters) {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if len(ifnames) == 0 {
+			all, err := hostInterfaces()
+			if err != nil {
+				log.Errorf("list interfaces: %v", err)
+				return exitNetlink
+			}
+			for _, ifname := range all {
+				if dcb.PCIAddress(ifname) != "" && !dcb.IsVF(ifname) {
+					ifnames = append(ifnames, ifname)
+				}
+			}
+			if len(ifnames) == 0 {
+				log.Error("no physical port found")
+				return exitNotCapable
+			}
+		}
+		return withClient(func(cl *dcb.Client) int {
+			reports := healthOf(cl, ifnames, *window, *stormRate, *counters)
+			if *output == "json" {
+				host, _ := os.Hostname()
+				rep := &healthReport{Schema: healthSchema, Time: time.Now(), Host: host, Interfaces: reports}
+				if err := printJSON(rep, true); err != nil {
+					log.Error(err)
+					return exitFailure
+				}
+			} else {
+				printHealth(reports)
+			}
+			for _, r := range reports {
+				if r.Status == dcb.CheckFail.String() {
+					return exitFailure
+				}
+			}
+			return exitOK
+		})
+	}
+}
+
+// healthSchema is the version of healthReport, see dcb.SnapshotSchema.
+const healthSchema = 1
+
+// A healthReport is the json output of health, described by
+// schema/health.json.
+type healthReport struct {
+	Schema     int            `json:"schema"`
+	Time       time.Time      `json:"time"`
+	Host       string         `json:"host"`
+	Interfaces []*healthIface `json:"interfaces"`
+}
+
+type healthIface struct {
+	Ifname string `json:"ifname"`
+	// Status is the worst status of Checks.
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks"`
+}
+
+type healthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// healthOf runs the health checks of ifnames: whether the driver does DCB,
+// who owns the config, the lossless priorities, mismatches with what the
+// link partner advertises, and PFC storms over window. The counters of all
+// interfaces are sampled together, so the whole takes one window, from
+// the counter source src.
+func healthOf(cl *dcb.Client, ifnames []string, window time.Duration, stormRate float64, src string) []*healthIface {
+	checks := make([][]dcb.Check, len(ifnames))
+	first := make([]*dcb.IEEEPFC, len(ifnames))
+	for i, ifname := range ifnames {
+		checks[i], first[i] = portHealth(cl, ifname)
+		if first[i] != nil {
+			applyCounterSource(src, ifname, first[i])
+		}
+	}
+	start := time.Now()
+	if window > 0 && slices.ContainsFunc(first, func(p *dcb.IEEEPFC) bool { return p != nil }) {
+		time.Sleep(window)
+	}
+	secs := time.Since(start).Seconds()
+
+	reports := make([]*healthIface, len(ifnames))
+	for i, ifname := range ifnames {
+		if first[i] != nil {
+			c := dcb.Check{Name: "pfc-storm", Status: dcb.CheckSkip, Detail: "not sampled"}
+			if window > 0 {
+				last, err := cl.GetPFC(ifname)
+				if err != nil {
+					c.Status, c.Detail = dcb.CheckFail, err.Error()
+				} else {
+					applyCounterSource(src, ifname, last)
+					c = stormCheck(first[i], last, secs, stormRate)
+				}
+			}
+			checks[i] = append(checks[i], c)
+		}
+		r := &healthIface{Ifname: ifname, Status: worstStatus(checks[i]).String()}
+		for _, c := range checks[i] {
+			r.Checks = append(r.Checks, healthCheck{Name: c.Name, Status: c.Status.String(), Detail: c.Detail})
+		}
+		reports[i] = r
+	}
+	return reports
+}
+
+// portHealth runs the checks of ifname that need no sampling and returns
+// them with the PFC counters, nil if the driver does not do DCB.
+func portHealth(cl *dcb.Client, ifname string) ([]dcb.Check, *dcb.IEEEPFC) {
+	pfc, err := cl.GetPFC(ifname)
+	switch {
+	case isNotCapable(err), errors.Is(err, dcb.ErrNoAttribute):
+		return []dcb.Check{{Name: "dcb", Status: dcb.CheckSkip, Detail: "driver does not implement ieee dcbnl pfc"}}, nil
+	case err != nil:
+		return []dcb.Check{{Name: "dcb", Status: dcb.CheckFail, Detail: err.Error()}}, nil
+	}
+
+	c := dcb.Check{Name: "dcb", Status: dcb.CheckPass, Detail: "ieee dcbnl"}
+	if mode, err := cl.GetDCBX(ifname); err == nil {
+		c.Detail += ", dcbx " + dcbxModes(mode)
+	}
+	checks := []dcb.Check{c}
+
+	c = dcb.Check{Name: "owner", Status: dcb.CheckPass}
+	if owner, err := cl.Owner(ifname); err != nil {
+		c.Status, c.Detail = dcb.CheckSkip, err.Error()
+	} else {
+		c.Detail = string(owner)
+		if owner != dcb.OwnerHost {
+			c.Detail += ", changes from the host are refused"
+		}
+	}
+	checks = append(checks, c)
+
+	c = dcb.Check{Name: "lossless", Status: dcb.CheckPass}
+	if prios := enabledPrios(pfc.PFCEn); len(prios) > 0 {
+		c.Detail = "pfc on prio " + strings.Join(prios, ",")
+	} else {
+		c.Status, c.Detail = dcb.CheckWarn, "no lossless priority, pfc_en 0"
+	}
+	checks = append(checks, c)
+
+	return append(checks, peerCheck(cl, ifname, pfc)), pfc
+}
+
+// peerCheck compares the PFC and ETS priority mapping of ifname with what
+// the link partner advertises: a PFC mismatch drops lossless traffic, a
+// different mapping only shifts it between classes.
+func peerCheck(cl *dcb.Client, ifname string, pfc *dcb.IEEEPFC) dcb.Check {
+	c := dcb.Check{Name: "peer", Status: dcb.CheckPass, Detail: "matches the peer"}
+	peer, err := cl.GetPeer(ifname)
+	switch {
+	case err != nil:
+		c.Status, c.Detail = dcb.CheckSkip, err.Error()
+		return c
+	case peer.PFC == nil && peer.ETS == nil:
+		c.Status, c.Detail = dcb.CheckSkip, "peer advertises nothing"
+		return c
+	}
+	var diffs []string
+	if peer.PFC != nil && peer.PFC.PFCEn != pfc.PFCEn {
+		c.Status = dcb.CheckFail
+		diffs = append(diffs, fmt.Sprintf("pfc_en %#02x, peer %#02x", pfc.PFCEn, peer.PFC.PFCEn))
+	}
+	if ets, err := cl.GetETS(ifname); err == nil && peer.ETS != nil && ets.PrioTC != peer.ETS.PrioTC {
+		if c.Status == dcb.CheckPass {
+			c.Status = dcb.CheckWarn
+		}
+		diffs = append(diffs, fmt.Sprintf("prio_tc %v, peer %v", ets.PrioTC, peer.ETS.PrioTC))
+	}
+	if len(diffs) > 0 {
+		c.Detail = strings.Join(diffs, "; ")
+	}
+	return c
+}
+
+// stormCheck fails when PFC frames were received on a priority at
+// stormRate or more between the counters first and last, secs apart.
+func stormCheck(first, last *dcb.IEEEPFC, secs, stormRate float64) dcb.Check {
+	c := dcb.Check{Name: "pfc-storm", Status: dcb.CheckPass}
+	var storm []string
+	peak := 0.0
+	for prio := range last.Indications {
+		// counters restart when the driver resets
+		if last.Indications[prio] < first.Indications[prio] {
+			continue
+		}
+		rate := float64(last.Indications[prio]-first.Indications[prio]) / secs
+		peak = max(peak, rate)
+		if rate >= stormRate {
+			storm = append(storm, fmt.Sprintf("prio %d at %.0f frames/s", prio, rate))
+		}
+	}
+	if len(storm) > 0 {
+		c.Status, c.Detail = dcb.CheckFail, "received "+strings.Join(storm, ", ")
+		return c
+	}
+	c.Detail = fmt.Sprintf("peak %.0f received frames/s over %.0fs", peak, secs)
+	return c
+}
+
+// worstStatus returns the most severe status of checks, skips counting
+// below passes.
+func worstStatus(checks []dcb.Check) dcb.CheckStatus {
+	worst := dcb.CheckSkip
+	for _, c := range checks {
+		switch {
+		case c.Status == dcb.CheckSkip:
+		case worst == dcb.CheckSkip, c.Status > worst:
+			worst = c.Status
+		}
+	}
+	return worst
+}
+
+func enabledPrios(en uint8) []string {
+	var prios []string
+	for prio := 0; prio < dcb.IEEE_8021QAZ_MAX_TCS; prio++ {
+		if en&(1<<prio) != 0 {
+			prios = append(prios, fmt.Sprint(prio))
+		}
+	}
+	return prios
+}
+
+func printHealth(reports []*healthIface) {
+	count := map[string]int{}
+	for _, r := range reports {
+		count[r.Status]++
+		fmt.Printf("%-4s %s\n", r.Status, r.Ifname)
+		for _, c := range r.Checks {
+			fmt.Printf("  %-4s %-10s %s\n", c.Status, c.Name, c.Detail)
+		}
+	}
+	fmt.Printf("%d ports: %d pass, %d warn, %d fail, %d skip\n", len(reports),
+		count["pass"], count["warn"], count["fail"], count["skip"])
+}
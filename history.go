@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("history", "<ifname>", "show the PFC and ETS samples a serve daemon running with -history keeps of an interface, to find storms between scrapes")
+	c.ifaceArgs = true
+	addr := c.fs.String("server", envOr("DCB_SERVER", ""), "REST API of the serve daemon, host:port or unix:/path (env DCB_SERVER)")
+	tokenFile := c.fs.String("token-file", envOr("DCB_TOKEN_FILE", ""), "file holding the bearer token of the REST API (env DCB_TOKEN_FILE)")
+	since := c.fs.String("since", "", "only samples after this time, as a duration back such as 5m or an RFC 3339 time; all kept if empty")
+	all := c.fs.Bool("all", false, "show every sample, not only those where the PFC counters moved or ETS changed")
+	output := c.fs.String("output", "text", "output format: text, or json")
+	timeout := c.fs.Duration("timeout", 10*time.Second, "timeout of the query")
+	c.run = func(args []string) int {
+		if len(args) != 1 || *addr == "" || (*output != "text" && *output != "json") {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if _, err := parseSince(*since, time.Now()); err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		token := &httpToken{path: *tokenFile}
+		if err := token.load(); err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		reply, err := fetchHistory(ctx, *addr, token.get(), args[0], *since)
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		if *output == "json" {
+			if err := printJSON(reply, true); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			return exitOK
+		}
+		printHistory(reply, *all)
+		return exitOK
+	}
+}
+
+// A historySample is the PFC and ETS state of an interface at a time.
+type historySample struct {
+	Time time.Time    `json:"time"`
+	PFC  *dcb.IEEEPFC `json:"pfc,omitempty"`
+	ETS  *dcb.IEEEETS `json:"ets,omitempty"`
+}
+
+// historyReply is the answer of GET /v1/interfaces/{ifname}/history.
+type historyReply struct {
+	Ifname    string          `json:"ifname"`
+	Interval  string          `json:"interval"`
+	Retention string          `json:"retention"`
+	Samples   []historySample `json:"samples"`
+}
+
+// A sampleRing holds the last samples of an interface, overwriting the
+// oldest once full.
+type sampleRing struct {
+	buf  []historySample
+	next int
+	full bool
+}
+
+func (r *sampleRing) add(s historySample) {
+	r.buf[r.next] = s
+	r.next = (r.next + 1) % len(r.buf)
+	r.full = r.full || r.next == 0
+}
+
+// after returns the samples taken after t, oldest first.
+func (r *sampleRing) after(t time.Time) []historySample {
+	samples := r.buf[:r.next]
+	if r.full {
+		samples = append(append([]historySample(nil), r.buf[r.next:]...), samples...)
+	}
+	var out []historySample
+	for _, s := range samples {
+		if s.Time.After(t) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// A history samples the PFC and ETS state of the interfaces of the host
+// every interval for serve -history, keeping retention's worth in memory,
+// so storms shorter than the scrape interval of a collector still show.
+type history struct {
+	interval, retention time.Duration
+
+	mu    sync.Mutex
+	rings map[string]*sampleRing
+}
+
+func newHistory(interval, retention time.Duration) *history {
+	return &history{interval: interval, retention: retention, rings: map[string]*sampleRing{}}
+}
+
+// run samples until ctx is done.
+func (h *history) run(ctx context.Context, cl *dcb.Client) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		h.sample(cl)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample records the state of the interfaces allowed by -iface-filter,
+// dropping the rings of interfaces gone. Interfaces without DCB support
+// are skipped.
+func (h *history) sample(cl *dcb.Client) {
+	ifnames, err := cl.Interfaces()
+	if err != nil {
+		log.Warnf("history: list interfaces: %v", err)
+		return
+	}
+	seen := map[string]bool{}
+	for _, ifname := range ifnames {
+		if !global.ifaces.allows(ifname) {
+			continue
+		}
+		pfc, err := cl.GetPFC(ifname)
+		if err != nil {
+			if !isNotCapable(err) {
+				log.Debugf("history: %v", err)
+			}
+			continue
+		}
+		s := historySample{Time: time.Now(), PFC: pfc}
+		s.ETS, _ = cl.GetETS(ifname)
+		seen[ifname] = true
+
+		h.mu.Lock()
+		r := h.rings[ifname]
+		if r == nil {
+			r = &sampleRing{buf: make([]historySample, int(h.retention/h.interval)+1)}
+			h.rings[ifname] = r
+		}
+		r.add(s)
+		h.mu.Unlock()
+	}
+	h.mu.Lock()
+	for ifname := range h.rings {
+		if !seen[ifname] {
+			delete(h.rings, ifname)
+		}
+	}
+	h.mu.Unlock()
+}
+
+// query returns the samples of ifname after since, within the retention,
+// and whether the interface is sampled.
+func (h *history) query(ifname string, since time.Time) ([]historySample, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rings[ifname]
+	if !ok {
+		return nil, false
+	}
+	if cutoff := time.Now().Add(-h.retention); since.Before(cutoff) {
+		since = cutoff
+	}
+	return r.after(since), true
+}
+
+// handler serves GET /v1/interfaces/{ifname}/history, with the optional
+// query parameter since as parseSince takes it.
+func (h *history) handler(w http.ResponseWriter, r *http.Request) {
+	ifname := r.PathValue("ifname")
+	since, err := parseSince(r.URL.Query().Get("since"), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	samples, ok := h.query(ifname, since)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("ifname: %v, no history, not a DCB interface or excluded", ifname)})
+		return
+	}
+	writeJSON(w, http.StatusOK, &historyReply{Ifname: ifname, Interval: h.interval.String(), Retention: h.retention.String(), Samples: samples})
+}
+
+// parseSince parses s as a duration back from now, such as 5m, or an RFC
+// 3339 time. The empty string is the zero time.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q, want a duration such as 5m or an RFC 3339 time", s)
+	}
+	return t, nil
+}
+
+// fetchHistory queries the history of ifname from the REST API at addr.
+func fetchHistory(ctx context.Context, addr, token, ifname, since string) (*historyReply, error) {
+	client := http.DefaultClient
+	host := addr
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		client = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		host = "unix"
+	}
+	u := "http://" + host + "/v1/interfaces/" + url.PathEscape(ifname) + "/history"
+	if since != "" {
+		u += "?since=" + url.QueryEscape(since)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct{ Error string }
+		json.NewDecoder(resp.Body).Decode(&e)
+		return nil, fmt.Errorf("%s: %s: %s", addr, resp.Status, e.Error)
+	}
+	var reply historyReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("%s: %w", addr, err)
+	}
+	return &reply, nil
+}
+
+// printHistory prints a line per sample with the PFC frames each priority
+// sent and received since the previous one, and the ETS config when it
+// changed. Without all, samples where nothing moved are left out.
+func printHistory(reply *historyReply, all bool) {
+	fmt.Printf("ifname: %s, %d samples every %s, kept %s\n", reply.Ifname, len(reply.Samples), reply.Interval, reply.Retention)
+	var prev *historySample
+	for i := range reply.Samples {
+		s := &reply.Samples[i]
+		var parts []string
+		if prev != nil && prev.PFC != nil && s.PFC != nil {
+			var reset bool
+			for prio := 0; prio < dcb.IEEE_8021QAZ_MAX_TCS; prio++ {
+				tx := counterSub(s.PFC.Requests[prio], prev.PFC.Requests[prio], &reset)
+				rx := counterSub(s.PFC.Indications[prio], prev.PFC.Indications[prio], &reset)
+				if tx > 0 || rx > 0 {
+					parts = append(parts, fmt.Sprintf("%s tx +%d rx +%d", global.labels.name(prio), tx, rx))
+				}
+			}
+			if s.PFC.PFCEn != prev.PFC.PFCEn {
+				parts = append(parts, fmt.Sprintf("pfc on %v", s.PFC.Enabled()))
+			}
+		}
+		if prev != nil && s.ETS != nil && (prev.ETS == nil || *s.ETS != *prev.ETS) {
+			parts = append(parts, fmt.Sprintf("ets tc_tsa %v tc_bw %v prio_tc %v", s.ETS.TCTSA, s.ETS.TCTxBW, s.ETS.PrioTC))
+		}
+		prev = s
+		if len(parts) == 0 {
+			if !all {
+				continue
+			}
+			parts = []string{"-"}
+		}
+		fmt.Printf("%s  %s\n", s.Time.Local().Format("15:04:05.000"), strings.Join(parts, ", "))
+	}
+}
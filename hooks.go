@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Event names hooks subscribe to.
+const (
+	eventPFCStorm       = "pfc-storm"
+	eventPeerChanged    = "peer-changed"
+	eventPeerFlapping   = "peer-flapping"
+	eventDriftReapplied = "drift-reapplied"
+)
+
+var hookEvents = []string{eventPFCStorm, eventPeerChanged, eventPeerFlapping, eventDriftReapplied}
+
+// hookTimeout bounds the run time of a hook so a hung command cannot pile
+// up processes.
+const hookTimeout = 30 * time.Second
+
+// hooks maps event names, or "*" for all, to the shell commands run on
+// them. It is a flag.Value taking event=command, repeated.
+type hooks map[string][]string
+
+func (h hooks) String() string {
+	var s []string
+	for event, cmds := range h {
+		for _, cmd := range cmds {
+			s = append(s, event+"="+cmd)
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+func (h hooks) Set(s string) error {
+	event, cmd, ok := strings.Cut(s, "=")
+	if !ok || cmd == "" {
+		return fmt.Errorf("want event=command, got %q", s)
+	}
+	if event != "*" && !slices.Contains(hookEvents, event) {
+		return fmt.Errorf("unknown event %q, want one of %s or *", event, strings.Join(hookEvents, ", "))
+	}
+	h[event] = append(h[event], cmd)
+	return nil
+}
+
+// A hookEvent is the JSON document a hook receives on stdin.
+type hookEvent struct {
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	Ifname string    `json:"ifname"`
+	Detail any       `json:"detail,omitempty"`
+}
+
+// fire runs the hooks of event in the background, passing it on stdin.
+// Failures are logged and otherwise ignored.
+func (h hooks) fire(event, ifname string, detail any) {
+	cmds := slices.Concat(h[event], h["*"])
+	if len(cmds) == 0 {
+		return
+	}
+	b, err := json.Marshal(&hookEvent{Event: event, Time: time.Now(), Ifname: ifname, Detail: detail})
+	if err != nil {
+		log.Errorf("hook %s: encode event: %v", event, err)
+		return
+	}
+	for _, cmd := range cmds {
+		go runHook(event, cmd, b)
+	}
+}
+
+func runHook(event, cmd string, payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, "/bin/sh", "-c", cmd)
+	c.Stdin = bytes.NewReader(payload)
+	c.Env = append(c.Environ(), "DCB_EVENT="+event)
+	out, err := c.CombinedOutput()
+	if err != nil {
+		log.Errorf("hook %s: %q: %v: %s", event, cmd, err, bytes.TrimSpace(out))
+		return
+	}
+	log.Debugf("hook %s: %q ran", event, cmd)
+}
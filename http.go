@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// newHTTPHandler returns the REST API. Objects are encoded as the JSON of
+// the dcb types, as in snapshot files. Requests must carry
+// "Authorization: Bearer <token>" unless the token is empty. hist, if set,
+// serves the samples of serve -history.
+func newHTTPHandler(cl *dcb.Client, token *httpToken, hist *history) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/interfaces/{ifname}", getter(cl.Snapshot))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}", func(w http.ResponseWriter, r *http.Request) {
+		var s dcb.Snapshot
+		if !decodeBody(w, r, &s) {
+			return
+		}
+		var skipped []dcb.Object
+		err := audited(cl, httpPeer(r), "http PUT "+r.URL.Path, r.PathValue("ifname"), func() error {
+			var err error
+			skipped, err = restore(cl, r.PathValue("ifname"), &s)
+			return err
+		})
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string][]dcb.Object{"skipped": skipped})
+	})
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/caps", getter(cl.Probe))
+
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/pfc", getter(cl.GetPFC))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/pfc", setter(cl, "pfc", cl.SetPFC))
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/ets", getter(cl.GetETS))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/ets", setter(cl, "ets", func(ifname string, ets *dcb.IEEEETS) error {
+		if err := ets.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", syscall.EINVAL, err)
+		}
+		return cl.SetETS(ifname, ets)
+	}))
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/maxrate", getter(cl.GetMaxrate))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/maxrate", setter(cl, "maxrate", cl.SetMaxrate))
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/buffer", getter(cl.GetBuffer))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/buffer", setter(cl, "buffer", cl.SetBuffer))
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/apps", getter(cl.GetApp))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/apps", setter(cl, "apps", func(ifname string, apps *[]dcb.App) error {
+		return cl.SetApps(ifname, *apps)
+	}))
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/trust", getter(cl.GetTrust))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/trust", setter(cl, "trust", func(ifname string, sels *[]dcb.Selector) error {
+		return cl.SetTrust(ifname, *sels)
+	}))
+	mux.HandleFunc("GET /v1/interfaces/{ifname}/dcbx", getter(cl.GetDCBX))
+	mux.HandleFunc("PUT /v1/interfaces/{ifname}/dcbx", setter(cl, "dcbx", func(ifname string, mode *uint8) error {
+		return cl.SetDCBX(ifname, *mode)
+	}))
+	if hist != nil {
+		mux.HandleFunc("GET /v1/interfaces/{ifname}/history", hist.handler)
+	}
+
+	filtered := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/v1/interfaces/")
+		if ifname, _, _ := strings.Cut(rest, "/"); ok && !global.ifaces.allows(ifname) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": fmt.Sprintf("ifname: %v, excluded by the interface filter", ifname)})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := token.get()
+		if want == "" {
+			filtered.ServeHTTP(w, r)
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="go-dcb"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid token"})
+			return
+		}
+		filtered.ServeHTTP(w, r)
+	})
+}
+
+func getter[T any](get func(ifname string) (T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, err := get(r.PathValue("ifname"))
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+// setter returns the handler setting object with set, or in the helper.
+func setter[T any](cl *dcb.Client, object string, set func(ifname string, v *T) error) http.HandlerFunc {
+	if global.helper != nil {
+		set = func(ifname string, v *T) error {
+			return global.helper.call(object+".set", ifname, v, nil)
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		v := new(T)
+		if !decodeBody(w, r, v) {
+			return
+		}
+		ifname := r.PathValue("ifname")
+		if err := audited(cl, httpPeer(r), "http PUT "+r.URL.Path, ifname, func() error { return set(ifname, v) }); err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		log.Infof("ifname: %v, %s set over http", ifname, r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:])
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// httpPeer identifies the client of a request for the audit log.
+func httpPeer(r *http.Request) string {
+	return "http " + r.RemoteAddr
+}
+
+// maxBodySize bounds request bodies; a full snapshot is a few KiB.
+const maxBodySize = 1 << 20
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("decode body: %v", err)})
+		return false
+	}
+	return true
+}
+
+// writeHTTPError maps library errors to status codes, along the lines of
+// exitCode.
+func writeHTTPError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, syscall.ENODEV):
+		code = http.StatusNotFound
+	case isNotCapable(err), errors.Is(err, dcb.ErrNoAttribute):
+		code = http.StatusNotImplemented
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		code = http.StatusForbidden
+	case errors.Is(err, syscall.EINVAL), errors.Is(err, syscall.ERANGE):
+		code = http.StatusBadRequest
+	case errors.As(err, new(*dcb.OwnerError)):
+		code = http.StatusConflict
+	}
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debugf("write response: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// An ifaceFilter holds the interfaces the daemon and exporter commands,
+// agent, drift, monitor and serve, may touch or scrape, so management
+// interfaces and virtual devices are left alone whatever their config or
+// clients ask for.
+type ifaceFilter struct {
+	include, exclude []ifaceRule
+}
+
+// An ifaceRule matches interfaces whose key, name, driver or pci, matches
+// the shell pattern pattern.
+type ifaceRule struct {
+	key, pattern string
+}
+
+// ifaceKeys returns, per rule key, the value of an interface the rule
+// matches.
+var ifaceKeys = map[string]func(ifname string) string{
+	"name":   func(ifname string) string { return ifname },
+	"driver": dcb.Driver,
+	"pci":    dcb.PCIAddress,
+}
+
+// loadIfaceFilter reads an interface filter file: include and exclude
+// rules such as "include pci=0000:3b:*" or "exclude name=eno*", one per
+// line, with # comments. An interface is allowed if it matches an include
+// rule, or there are none, and no exclude rule. A missing file allows
+// every interface.
+func loadIfaceFilter(p string) (*ifaceFilter, error) {
+	f := &ifaceFilter{}
+	file, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		k, pattern, ok := strings.Cut(fields[len(fields)-1], "=")
+		if _, known := ifaceKeys[k]; len(fields) != 2 || !ok || !known {
+			return nil, fmt.Errorf("%s:%d: invalid rule %q, want include or exclude name=, driver= or pci=<pattern>", p, n, strings.TrimSpace(line))
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: pattern %q: %w", p, n, pattern, err)
+		}
+		r := ifaceRule{key: k, pattern: pattern}
+		switch fields[0] {
+		case "include":
+			f.include = append(f.include, r)
+		case "exclude":
+			f.exclude = append(f.exclude, r)
+		default:
+			return nil, fmt.Errorf("%s:%d: invalid rule %q, want include or exclude", p, n, fields[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read interface filter %s: %w", p, err)
+	}
+	return f, nil
+}
+
+func (r ifaceRule) matches(ifname string) bool {
+	ok, _ := path.Match(r.pattern, ifaceKeys[r.key](ifname))
+	return ok
+}
+
+// allows reports whether the filter lets the commands touch ifname.
+func (f *ifaceFilter) allows(ifname string) bool {
+	if f == nil {
+		return true
+	}
+	included := len(f.include) == 0
+	for _, r := range f.include {
+		if r.matches(ifname) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, r := range f.exclude {
+		if r.matches(ifname) {
+			return false
+		}
+	}
+	return true
+}
+
+// args returns the interfaces of ifnames, given as arguments, the filter
+// allows, warning about the others.
+func (f *ifaceFilter) args(ifnames []string) []string {
+	var out []string
+	for _, ifname := range ifnames {
+		if !f.allows(ifname) {
+			log.Warnf("ifname: %v, excluded by the interface filter, skipped", ifname)
+			continue
+		}
+		out = append(out, ifname)
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// jsonStyles are the values of -json-style.
+var jsonStyles = []string{"go", "snake", "iproute2"}
+
+// iproute2Keys are the names dcb(8) -j gives the fields of the dcbnl
+// structs; the fields it has no name for are snake cased.
+var iproute2Keys = map[string]string{
+	"ETSCap":      "ets_cap",
+	"TCTxBW":      "tc_bw",
+	"TCRecoBW":    "reco_tc_bw",
+	"TCRecoTSA":   "reco_tc_tsa",
+	"RecoPrioTC":  "reco_prio_tc",
+	"PFCEn":       "prio_pfc",
+	"MBC":         "macsec_bypass",
+	"Prio2Buffer": "prio_buffer",
+	"TotalSize":   "total_size",
+	"TCMaxrate":   "tc_maxrate",
+}
+
+// A jsonNaming renames the keys of the JSON output, as -json-style and
+// -json-keys select. The zero value leaves them as the Go field names.
+type jsonNaming struct {
+	style string
+	// keys are the renames of -json-keys, which take precedence.
+	keys map[string]string
+}
+
+// loadJSONNaming returns the naming of style with the renames of the file
+// path, if any: lines of GoName=name, with # comments.
+func loadJSONNaming(style, path string) (*jsonNaming, error) {
+	n := &jsonNaming{style: style, keys: map[string]string{}}
+	if path == "" {
+		return n, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(line, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("%s:%d: invalid line %q, want GoName=name", path, i, line)
+		}
+		n.keys[from] = to
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// rename returns the output name of the key k. Only identifiers are
+// renamed, so map keys such as interface names are left alone.
+func (n *jsonNaming) rename(k string) string {
+	if n == nil || !isIdentifier(k) {
+		return k
+	}
+	if to, ok := n.keys[k]; ok {
+		return to
+	}
+	return styleKey(n.style, k)
+}
+
+func styleKey(style, k string) string {
+	switch style {
+	case "iproute2":
+		if to, ok := iproute2Keys[k]; ok {
+			return to
+		}
+		return snakeCase(k)
+	case "snake":
+		return snakeCase(k)
+	}
+	return k
+}
+
+// plain reports whether n leaves every key as it is.
+func (n *jsonNaming) plain() bool {
+	return n == nil || ((n.style == "" || n.style == "go") && len(n.keys) == 0)
+}
+
+func isIdentifier(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// snakeCase converts a Go field name to snake case, keeping initialisms
+// together: PFCEn is pfc_en and TCTxBW tc_tx_bw.
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// marshalJSON encodes v, indented if indent is set, with the keys named
+// as -json-style and -json-keys select.
+func marshalJSON(v any, indent bool) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if !global.jsonNaming.plain() {
+		if b, err = renameJSONKeys(b, global.jsonNaming.rename); err != nil {
+			return nil, err
+		}
+	}
+	if !indent {
+		return b, nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// printJSON writes v to stdout as marshalJSON encodes it, with a newline.
+func printJSON(v any, indent bool) error {
+	b, err := marshalJSON(v, indent)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(b, '\n'))
+	return err
+}
+
+// renameJSONKeys returns the JSON document b with the object keys renamed
+// by rename, in their order.
+func renameJSONKeys(b []byte, rename func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if err := renameValue(dec, &out, rename); err != nil {
+		return nil, fmt.Errorf("rename json keys: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func renameValue(dec *json.Decoder, out *bytes.Buffer, rename func(string) string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		out.WriteByte('{')
+		for i := 0; dec.More(); i++ {
+			k, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			key, _ := json.Marshal(rename(k.(string)))
+			out.Write(key)
+			out.WriteByte(':')
+			if err := renameValue(dec, out, rename); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		out.WriteByte('}')
+	case json.Delim('['):
+		out.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := renameValue(dec, out, rename); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		out.WriteByte(']')
+	default:
+		v, err := json.Marshal(tok)
+		if err != nil {
+			return err
+		}
+		out.Write(v)
+	}
+	return nil
+}
+
+// snapshotKeyAliases maps the names every style, and -json-keys, gives the
+// fields of snapshots back to the Go names, so snapshot files written with
+// any naming read back.
+func snapshotKeyAliases() map[string]string {
+	aliases := map[string]string{}
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" {
+				name = f.Name
+			}
+			for _, style := range jsonStyles {
+				if styled := styleKey(style, name); styled != name {
+					aliases[styled] = name
+				}
+			}
+			if global.jsonNaming != nil {
+				if to, ok := global.jsonNaming.keys[name]; ok {
+					aliases[to] = name
+				}
+			}
+			walk(f.Type)
+		}
+	}
+	walk(reflect.TypeOf(dcb.Snapshot{}))
+	return aliases
+}
+
+// normalizeSnapshotKeys renames the keys of the snapshot file b written
+// with another naming than the Go one back to it.
+func normalizeSnapshotKeys(b []byte) ([]byte, error) {
+	aliases := snapshotKeyAliases()
+	return renameJSONKeys(b, func(k string) string {
+		if to, ok := aliases[k]; ok {
+			return to
+		}
+		return k
+	})
+}
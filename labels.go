@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// prioLabels are the user-defined names of the priorities, such as roce
+// for the lossless one, shown next to their numbers.
+type prioLabels [dcb.IEEE_8021Q_MAX_PRIORITIES]string
+
+// loadLabels reads a labels file: label assignments such as "prio3=roce"
+// or "3=roce", separated by spaces or newlines, with # comments. A missing
+// file has no labels.
+func loadLabels(path string) (prioLabels, error) {
+	var l prioLabels
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		for _, field := range strings.Fields(line) {
+			k, v, ok := strings.Cut(field, "=")
+			prio, err := strconv.ParseUint(strings.TrimPrefix(k, "prio"), 10, 8)
+			if !ok || v == "" || err != nil || prio >= dcb.IEEE_8021Q_MAX_PRIORITIES {
+				return l, fmt.Errorf("%s:%d: invalid label %q, want prio<n>=<label>", path, n, field)
+			}
+			l[prio] = v
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return l, fmt.Errorf("read labels %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// name returns prio with its label, if any, e.g. "3 (roce)".
+func (l *prioLabels) name(prio int) string {
+	if l[prio] == "" {
+		return strconv.Itoa(prio)
+	}
+	return fmt.Sprintf("%d (%s)", prio, l[prio])
+}
+
+// counters formats per-priority counters keyed by priority, with their
+// total, e.g. "0:0 3(roce):12 ... total 12".
+func (l *prioLabels) counters(v [dcb.IEEE_8021QAZ_MAX_TCS]uint64) string {
+	var sb strings.Builder
+	var total uint64
+	for prio, n := range v {
+		sb.WriteString(strconv.Itoa(prio))
+		if l[prio] != "" {
+			fmt.Fprintf(&sb, "(%s)", l[prio])
+		}
+		fmt.Fprintf(&sb, ":%d ", n)
+		total += n
+	}
+	fmt.Fprintf(&sb, "total %d", total)
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// linkSpeed returns the speed of ifname in Mb/s as reported by sysfs, or 0
+// if the link is down or the driver does not report one.
+func linkSpeed(ifname string) uint64 {
+	b, err := os.ReadFile("/sys/class/net/" + ifname + "/speed")
+	if err != nil {
+		return 0
+	}
+	speed, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil || speed <= 0 {
+		return 0
+	}
+	return uint64(speed)
+}
+
+// linkInfo returns the link state of ifname, or nil if it cannot be read,
+// which only makes the output less detailed.
+func linkInfo(cl *dcb.Client, ifname string) *dcb.LinkInfo {
+	li, err := cl.LinkInfo(ifname)
+	if err != nil {
+		log.Debug(err)
+		return nil
+	}
+	return li
+}
+
+func printLink(li *dcb.LinkInfo) {
+	speed := "unknown"
+	if li.Speed > 0 {
+		speed = fmt.Sprintf("%d Mb/s", li.Speed)
+	}
+	fmt.Printf("link: %s, speed %s, mtu %d", li.OperState, speed, li.MTU)
+	if li.Driver != "" {
+		fmt.Printf(", driver %s", li.Driver)
+	}
+	if li.Firmware != "" {
+		fmt.Printf(", firmware %s", li.Firmware)
+	}
+	fmt.Println()
+}
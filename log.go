@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log *logrus.Logger
+
+func init() {
+	log = logrus.New()
+	log.SetFormatter(textFormatter())
+	// diagnostics go to stderr so stdout carries nothing but data
+	log.SetOutput(os.Stderr)
+	log.SetLevel(logrus.InfoLevel)
+	log.SetReportCaller(true)
+}
+
+func textFormatter() logrus.Formatter {
+	return &logrus.TextFormatter{
+		DisableColors:   true,
+		ForceQuote:      true,
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02T15:04:05.000000000Z07:00", // rfc3339NanoFixed
+		DisableSorting:  false,
+	}
+}
+
+// logOptions are the logging settings taken from flags, defaulting to the
+// DCB_LOG_* environment variables.
+type logOptions struct {
+	level  string
+	format string
+	output string
+}
+
+func (o *logOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.level, "log-level", envOr("DCB_LOG_LEVEL", "info"),
+		"log level: trace, debug, info, warn, error (env DCB_LOG_LEVEL)")
+	fs.StringVar(&o.format, "log-format", envOr("DCB_LOG_FORMAT", "text"),
+		"log format: text or json (env DCB_LOG_FORMAT)")
+	fs.StringVar(&o.output, "log-output", envOr("DCB_LOG_OUTPUT", "stderr"),
+		"log destination: stderr, stdout or a file path (env DCB_LOG_OUTPUT)")
+}
+
+// apply configures the global logger.
+func (o *logOptions) apply() error {
+	level, err := logrus.ParseLevel(o.level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+
+	switch o.format {
+	case "text":
+		log.SetFormatter(textFormatter())
+	case "json":
+		log.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05.000000000Z07:00", // rfc3339NanoFixed
+		})
+	default:
+		return fmt.Errorf("unknown log format %q", o.format)
+	}
+
+	var w io.Writer
+	switch o.output {
+	case "", "stderr":
+		w = os.Stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		f, err := os.OpenFile(o.output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("open log output: %w", err)
+		}
+		w = f
+	}
+	log.SetOutput(w)
+	return nil
+}
+
+func envOr(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
@@ -1,182 +1,209 @@
 package main
 
 import (
-	"bytes"
-	"encoding/binary"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
 
+	"github.com/fanzu8/go-dcb/dcb"
 	"github.com/mdlayher/netlink"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sys/unix"
 )
 
-var log *logrus.Logger
-
-func init() {
-	log = logrus.New()
-	log.SetFormatter(&logrus.TextFormatter{
-		DisableColors:   true,
-		ForceQuote:      true,
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02T15:04:05.000000000Z07:00", // rfc3339NanoFixed
-		DisableSorting:  false,
-	})
-	log.SetOutput(os.Stdout)
-	log.SetLevel(logrus.InfoLevel)
-	log.SetReportCaller(true)
+// defaultCommand runs when the first argument is not a command name, so
+// `go-dcb <ifname>` keeps working.
+const defaultCommand = "get"
+
+// global holds the flags shared by all commands.
+var global struct {
+	strict bool
+	verify bool
+	force  bool
+	raw    bool
+	// notCapable is how commands over several interfaces treat those
+	// without DCB support: skip, mark or error.
+	notCapable string
+	labels     prioLabels
+	log        logOptions
+	audit      auditOptions
+	// capture records the netlink messages with -nl-capture.
+	capture *dcb.Capture
+	// debugNetlink logs every netlink message as annotated hex.
+	debugNetlink bool
+	// ifaces limits the interfaces of the daemon and exporter commands.
+	ifaces *ifaceFilter
+	// readBuffer and writeBuffer are the socket buffer sizes, 0 for the
+	// kernel default.
+	readBuffer, writeBuffer int
+	// helper makes the changes of agent and serve with -helper.
+	helper *privHelper
+	// jsonNaming names the keys of the JSON output with -json-style and
+	// -json-keys.
+	jsonNaming *jsonNaming
 }
 
-const (
-	// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L293
-	DCB_CMD_IEEE_GET = 21
-
-	// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L372
-	DCB_ATTR_IFNAME        = 1
-	DCB_ATTR_IEEE_PFC      = 2
-	DCB_ATTR_IEEE_PEER_PFC = 5
-	DCB_ATTR_IEEE          = 13
-
-	// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L27
-	/* IEEE 802.1Qaz std supported values */
-	IEEE_8021QAZ_MAX_TCS = 8
-)
-
-// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L157
-type ieeePFC struct { // struct ieee_pfc
-	PFCCap      uint8
-	PFCEn       uint8
-	MBC         uint8
-	Delay       uint16
-	_pad        [3]uint8
-	Requests    [IEEE_8021QAZ_MAX_TCS]uint64 // count of the sent pfc frames
-	Indications [IEEE_8021QAZ_MAX_TCS]uint64 // count of the received pfc frames
-}
-
-// https://github.com/torvalds/linux/blob/v5.10/include/uapi/linux/dcbnl.h#L264
-type dcbMsg struct { // struct dcbmsg
-	family uint8
-	cmd    uint8
-	_pad   uint16
+func main() {
+	os.Exit(run())
 }
 
-func (m *dcbMsg) MarshalBinary() ([]byte, error) {
-	buf := new(bytes.Buffer)
-	if err := binary.Write(buf, binary.LittleEndian, m); err != nil {
-		return nil, err
+func run() int {
+	flag.BoolVar(&global.strict, "strict", false, "have the kernel strictly validate requests (NETLINK_GET_STRICT_CHK)")
+	flag.BoolVar(&global.verify, "verify", true, "read every change back and fail, exit 4, if the driver did not apply it as requested")
+	flag.BoolVar(&global.force, "force", false, "change interfaces whose config is owned by lldpad or the NIC firmware, with a warning")
+	flag.BoolVar(&global.raw, "raw", false, "print values in the units of the kernel: rates in kbit/s, sizes in bytes and delays in bit times")
+	flag.StringVar(&global.notCapable, "not-capable", "mark", "how commands over several interfaces treat those without DCB support, such as virtual ones: skip them silently, mark them as not capable in the output and a warning, or error")
+	flag.BoolVar(&global.debugNetlink, "debug-netlink", false, "log every netlink message sent and received as annotated hex, at debug level, which the flag enables")
+	flag.Func("nl-read-buffer", "kernel receive buffer of the netlink sockets, e.g. 8MiB, for large dumps across many interfaces", sizeFlag(&global.readBuffer))
+	flag.Func("nl-write-buffer", "kernel send buffer of the netlink sockets, e.g. 1MiB", sizeFlag(&global.writeBuffer))
+	capturePath := flag.String("nl-capture", "", "record all netlink messages to this file, for the replay-decode command")
+	ifaceFilterPath := flag.String("iface-filter", envOr("DCB_IFACE_FILTER", "/etc/go-dcb/interfaces"), "file of include and exclude rules, e.g. exclude name=eno*, limiting the interfaces agent, drift, monitor and serve touch (env DCB_IFACE_FILTER)")
+	helperCmd := flag.String("helper", envOr("DCB_HELPER", ""), "command line of a privileged helper, e.g. \"sudo -n go-dcb rpc\", making the changes of agent and serve so they can run without CAP_NET_ADMIN (env DCB_HELPER)")
+	labelsPath := flag.String("labels", envOr("DCB_LABELS", "/etc/go-dcb/labels"), "file naming priorities, e.g. prio3=roce, for the counter output (env DCB_LABELS)")
+	jsonStyle := flag.String("json-style", envOr("DCB_JSON_STYLE", "go"), "key naming of the JSON output of snapshot, apply, health, monitor, fleet and replay-decode: go field names such as PFCEn, snake such as pfc_en, or iproute2 as dcb -j names them, such as prio_pfc (env DCB_JSON_STYLE)")
+	jsonKeysPath := flag.String("json-keys", envOr("DCB_JSON_KEYS", ""), "file of GoName=name lines renaming JSON output keys over -json-style, e.g. PFCEn=pfc_enable (env DCB_JSON_KEYS)")
+	global.log.register(flag.CommandLine)
+	global.audit.register(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+	if err := global.log.apply(); err != nil {
+		log.Errorf("configure logging: %v", err)
+		return exitUsage
 	}
-	return buf.Bytes(), nil
-}
-
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Printf("usage: %s <ifname>\n", os.Args[0])
-		os.Exit(1)
+	if !slices.Contains(notCapableModes, global.notCapable) {
+		log.Errorf("invalid -not-capable %q, want skip, mark or error", global.notCapable)
+		return exitUsage
 	}
-	ifname := os.Args[1]
-
-	c, err := netlink.Dial(unix.NETLINK_ROUTE, nil)
-	if err != nil {
-		log.Fatalf("netlink dial: %v", err)
+	if global.debugNetlink && !log.IsLevelEnabled(logrus.DebugLevel) {
+		log.SetLevel(logrus.DebugLevel)
 	}
-	defer c.Close()
-
-	dcbmsg := &dcbMsg{
-		family: unix.AF_UNSPEC,
-		cmd:    uint8(DCB_CMD_IEEE_GET),
+	var err error
+	if global.labels, err = loadLabels(*labelsPath); err != nil {
+		log.Error(err)
+		return exitUsage
 	}
-	dcbmsgb, err := dcbmsg.MarshalBinary()
-	if err != nil {
-		log.Fatalf("marshal dcbmsg: %v", err)
+	if global.ifaces, err = loadIfaceFilter(*ifaceFilterPath); err != nil {
+		log.Error(err)
+		return exitUsage
 	}
-
-	ae := netlink.NewAttributeEncoder()
-	ae.String(DCB_ATTR_IFNAME, ifname)
-	attrs, err := ae.Encode()
-	if err != nil {
-		log.Fatalf("encode attributes: %v", err)
+	if !slices.Contains(jsonStyles, *jsonStyle) {
+		log.Errorf("invalid -json-style %q, want go, snake or iproute2", *jsonStyle)
+		return exitUsage
 	}
-
-	req := netlink.Message{
-		Header: netlink.Header{
-			Type:  unix.RTM_GETDCB,
-			Flags: netlink.Request | netlink.Acknowledge,
-		},
-		Data: append(dcbmsgb, attrs...),
+	if global.jsonNaming, err = loadJSONNaming(*jsonStyle, *jsonKeysPath); err != nil {
+		log.Error(err)
+		return exitUsage
 	}
-
-	msgs, err := c.Execute(req)
-	if err != nil {
-		var opErr *netlink.OpError
-		if errors.As(err, &opErr) {
-			if errors.Is(opErr.Err, unix.ENODEV) ||
-				// virtual iface, such as bond, lo etc.
-				errors.Is(opErr.Err, unix.EOPNOTSUPP) {
-				log.Warnf("ifname: %v, get ieee pfc: %v", ifname, opErr.Error())
-			}
+	if *helperCmd != "" {
+		if global.helper, err = newPrivHelper(*helperCmd); err != nil {
+			log.Error(err)
+			return exitUsage
 		}
-		log.Fatalf("ifname: %v, get ieee pfc: %v", ifname, err)
+		defer global.helper.Close()
 	}
-
-	for _, m := range msgs {
-		if len(m.Data) <= len(dcbmsgb) {
-			log.Infof("invalid dcbmsg length: %d", len(m.Data))
-			continue
-		}
-
-		ad, err := netlink.NewAttributeDecoder(m.Data[len(dcbmsgb):])
+	if *capturePath != "" {
+		finish, err := openCapture(*capturePath)
 		if err != nil {
-			log.Fatalf("decode top-level attributes: %v", err)
-		}
-		for ad.Next() {
-			switch ad.Type() {
-			case DCB_ATTR_IFNAME:
-				fmt.Printf("ifname: %s\n", ad.String())
-			case DCB_ATTR_IEEE:
-				ad.Nested(func(nad *netlink.AttributeDecoder) error {
-					for nad.Next() {
-						switch nad.Type() {
-						case DCB_ATTR_IEEE_PFC:
-							ieeepfc, err := parseIEEEPFC(nad.Bytes())
-							if err != nil {
-								log.Fatalf("parse ieee pfc: %v", err)
-							}
-							fmt.Printf("ieee pfc: %+v\n", ieeepfc)
-						case DCB_ATTR_IEEE_PEER_PFC:
-							// TODO: support peer pfc
-						}
-					}
-					return nil
-				})
-			}
+			log.Error(err)
+			return exitUsage
 		}
+		defer finish()
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+	c, ok := commands[args[0]]
+	if ok {
+		args = args[1:]
+	} else {
+		c = commands[defaultCommand]
 	}
+	return c.execute(args)
 }
 
-func parseIEEEPFC(b []byte) (*ieeePFC, error) {
-	pad := 3
-	if len(b) < 1+1+1+2+pad+IEEE_8021QAZ_MAX_TCS*8*2 {
-		return nil, fmt.Errorf("invalid struct ieee_pfc length %d", len(b))
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "usage: %s [global flags] <command> [flags] [args]\n", progName())
+	fmt.Fprintf(w, "       %s [global flags] <ifname> [ifname...]\n\ncommands:\n", progName())
+	for _, c := range sortedCommands() {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.help)
 	}
+	fmt.Fprintf(w, "\nglobal flags:\n")
+	flag.PrintDefaults()
+}
+
+func progName() string {
+	return filepath.Base(os.Args[0])
+}
 
-	p := &ieeePFC{
-		PFCCap: b[0],
-		PFCEn:  b[1],
-		MBC:    b[2],
-		Delay:  binary.BigEndian.Uint16(b[3:5]),
+// dial opens a Client with the global options and poolSize sockets.
+func dial(poolSize int) (*dcb.Client, error) {
+	opts := []dcb.Option{
+		dcb.WithPoolSize(poolSize),
+		dcb.WithStrictCheck(global.strict),
+		dcb.WithVerify(global.verify),
+		dcb.WithCapture(global.capture),
+		dcb.WithBuffers(global.readBuffer, global.writeBuffer),
 	}
+	if global.debugNetlink {
+		opts = append(opts, dcb.WithTrace(traceNetlink))
+	}
+	cl, err := dcb.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial dcb client: %w", err)
+	}
+	return cl, nil
+}
 
-	off := 1 + 1 + 1 + 2 + pad
-	for i := 0; i < IEEE_8021QAZ_MAX_TCS; i++ {
-		p.Requests[i] = binary.BigEndian.Uint64(b[off : off+8])
-		off += 8
+// withClient runs fn with a single-socket Client.
+func withClient(fn func(cl *dcb.Client) int) int {
+	cl, err := dial(1)
+	if err != nil {
+		log.Error(err)
+		return exitNetlink
 	}
-	for i := 0; i < IEEE_8021QAZ_MAX_TCS; i++ {
-		p.Indications[i] = binary.BigEndian.Uint64(b[off : off+8])
-		off += 8
+	defer cl.Close()
+	return fn(cl)
+}
+
+// isNotCapable reports whether err means the interface does not exist or
+// does not implement dcbnl at all.
+func isNotCapable(err error) bool {
+	var opErr *netlink.OpError
+	if errors.As(err, &opErr) {
+		if errors.Is(opErr.Err, syscall.ENODEV) ||
+			// virtual iface, such as bond, lo etc.
+			errors.Is(opErr.Err, syscall.EOPNOTSUPP) {
+			return true
+		}
 	}
+	return false
+}
 
-	return p, nil
+// isNetlinkError reports whether err was produced by the netlink socket,
+// including permission errors.
+func isNetlinkError(err error) bool {
+	var opErr *netlink.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES)
 }
 
+// traceNetlink logs m, a line per log entry so the dump stays readable in
+// the text and json log formats alike.
+func traceNetlink(m netlink.Message, sent bool) {
+	dir := "received"
+	if sent {
+		dir = "sent"
+	}
+	entry := log.WithField("netlink", dir)
+	for _, line := range strings.Split(strings.TrimSuffix(dcb.DumpMessage(m), "\n"), "\n") {
+		entry.Debug(line)
+	}
+}
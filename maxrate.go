@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("maxrate", "<ifname>", "show the tx rate limit of each traffic class")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			m, err := cl.GetMaxrate(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printMaxrate(args[0], m)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(setGroup, "maxrate", "<ifname>", "change the tx rate limit of traffic classes")
+	set.ifaceArgs = true
+	rates := set.fs.String("rates", "", `comma-separated limit per tc, such as 10Gbit, 500Mbit, 12.5% of the link speed, a number of kbit/s or "unlimited"; empty entries are left as is, or give tc:limit pairs such as 3:10Gbit`)
+	set.run = func(args []string) int {
+		if len(args) != 1 || *rates == "" {
+			set.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			m, err := cl.GetMaxrate(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			if err := applyMaxrateFlags(m, *rates, linkSpeed(args[0])); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if err := audited(cl, cliUser(), "set maxrate", args[0], func() error { return cl.SetMaxrate(args[0], m) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+}
+
+// applyMaxrateFlags updates m from the set maxrate flags. Percentages are
+// of speedMbps, 0 if the link speed is unknown.
+func applyMaxrateFlags(m *dcb.IEEEMaxrate, rates string, speedMbps uint64) error {
+	return editList(rates, len(m.TCMaxrate), func(tc int, p string) error {
+		r, err := parseRate(p, speedMbps)
+		if err != nil {
+			return fmt.Errorf("tc %d: %w", tc, err)
+		}
+		m.TCMaxrate[tc] = r
+		return nil
+	})
+}
+
+func printMaxrate(ifname string, m *dcb.IEEEMaxrate) {
+	fmt.Printf("ifname: %s\n", ifname)
+	for tc, r := range m.TCMaxrate {
+		fmt.Printf("tc %d: %s\n", tc, formatRate(r))
+	}
+}
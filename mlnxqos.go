@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// An mlnxQOSLine is a line of an mlnx_qos description: the options of an
+// mlnx_qos command, as in runbooks written for the Mellanox tools, parsed
+// into edits of a snapshot.
+type mlnxQOSLine struct {
+	// ifname is the -i of the line, "" if none, which applies the line to
+	// every interface.
+	ifname string
+	edits  []func(dst, base *dcb.Snapshot)
+}
+
+// readMLNXQOS parses an mlnx_qos description: lines of mlnx_qos options,
+// such as "--trust dscp --pfc 0,0,0,1,0,0,0,0", optionally starting with
+// the mlnx_qos command itself. Lines ending in a backslash continue on the
+// next, # starts a comment.
+func readMLNXQOS(r io.Reader) ([]*mlnxQOSLine, error) {
+	var lines []*mlnxQOSLine
+	sc := bufio.NewScanner(r)
+	var cont string
+	start := 0
+	for num := 1; sc.Scan(); num++ {
+		if cont == "" {
+			start = num
+		}
+		text, _, _ := strings.Cut(sc.Text(), "#")
+		text = cont + text
+		if t, ok := strings.CutSuffix(strings.TrimSpace(text), `\`); ok {
+			cont = t + " "
+			continue
+		}
+		cont = ""
+		args := strings.Fields(text)
+		if len(args) > 0 && (args[0] == "mlnx_qos" || strings.HasSuffix(args[0], "/mlnx_qos")) {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		l, err := parseMLNXQOSArgs(args)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		lines = append(lines, l)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("no mlnx_qos options")
+	}
+	return lines, nil
+}
+
+func parseMLNXQOSArgs(args []string) (*mlnxQOSLine, error) {
+	l := &mlnxQOSLine{}
+	for i := 0; i < len(args); i++ {
+		name, value, ok := strings.Cut(args[i], "=")
+		if !ok {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("option %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		edit, err := parseMLNXQOSOption(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", name, value, err)
+		}
+		if edit == nil {
+			l.ifname = value
+			continue
+		}
+		l.edits = append(l.edits, edit)
+	}
+	return l, nil
+}
+
+// parseMLNXQOSOption returns the edit the mlnx_qos option name makes, or nil
+// for -i, the interface.
+func parseMLNXQOSOption(name, value string) (func(dst, base *dcb.Snapshot), error) {
+	switch name {
+	case "-i", "--interface":
+		return nil, nil
+	case "--trust":
+		var trust []dcb.Selector
+		switch value {
+		case "pcp":
+			trust = []dcb.Selector{dcb.DCB_APP_SEL_PCP}
+		case "dscp":
+			trust = []dcb.Selector{dcb.IEEE_8021QAZ_APP_SEL_DSCP}
+		default:
+			return nil, errors.New("want pcp or dscp")
+		}
+		return func(dst, _ *dcb.Snapshot) {
+			dst.Trust = trust
+		}, nil
+	case "--pfc":
+		en, err := mlnxQOSList(value, 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, base *dcb.Snapshot) {
+			pfc := objectOf(&dst.PFC, base.PFC)
+			pfc.PFCEn = 0
+			for prio, on := range en {
+				pfc.PFCEn |= uint8(on) << prio
+			}
+		}, nil
+	case "--prio_tc":
+		tcs, err := mlnxQOSList(value, dcb.IEEE_8021QAZ_MAX_TCS-1)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, base *dcb.Snapshot) {
+			ets := objectOf(&dst.ETS, base.ETS)
+			for prio, tc := range tcs {
+				ets.PrioTC[prio] = uint8(tc)
+			}
+		}, nil
+	case "--tsa":
+		var tsa []dcb.TSA
+		for _, s := range splitList(value) {
+			t, err := dcb.ParseTSA(s)
+			if err != nil {
+				return nil, err
+			}
+			tsa = append(tsa, t)
+		}
+		if len(tsa) > dcb.IEEE_8021QAZ_MAX_TCS {
+			return nil, fmt.Errorf("more than %d values", dcb.IEEE_8021QAZ_MAX_TCS)
+		}
+		return func(dst, base *dcb.Snapshot) {
+			copy(objectOf(&dst.ETS, base.ETS).TCTSA[:], tsa)
+		}, nil
+	case "--tcbw":
+		bw, err := mlnxQOSList(value, 100)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, base *dcb.Snapshot) {
+			ets := objectOf(&dst.ETS, base.ETS)
+			for tc, v := range bw {
+				ets.TCTxBW[tc] = uint8(v)
+			}
+		}, nil
+	case "--ratelimit":
+		var rates []uint64
+		for _, s := range splitList(value) {
+			// Gb/s, 0 for unlimited
+			kbps, ok := parseDecimal(s, 1e6)
+			if !ok {
+				return nil, fmt.Errorf("invalid rate %q, want Gb/s that are a whole number of kbit/s", s)
+			}
+			rates = append(rates, kbps)
+		}
+		if len(rates) > dcb.IEEE_8021QAZ_MAX_TCS {
+			return nil, fmt.Errorf("more than %d values", dcb.IEEE_8021QAZ_MAX_TCS)
+		}
+		return func(dst, base *dcb.Snapshot) {
+			copy(objectOf(&dst.Maxrate, base.Maxrate).TCMaxrate[:], rates)
+		}, nil
+	case "--prio2buffer":
+		bufs, err := mlnxQOSList(value, dcb.DCBX_MAX_BUFFERS-1)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, base *dcb.Snapshot) {
+			b := objectOf(&dst.Buffer, base.Buffer)
+			for prio, buf := range bufs {
+				b.Prio2Buffer[prio] = uint8(buf)
+			}
+		}, nil
+	case "--buffer_size":
+		var sizes []uint32
+		for _, s := range splitList(value) {
+			n, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size %q, want bytes", s)
+			}
+			sizes = append(sizes, uint32(n))
+		}
+		if len(sizes) > dcb.DCBX_MAX_BUFFERS {
+			return nil, fmt.Errorf("more than %d values", dcb.DCBX_MAX_BUFFERS)
+		}
+		return func(dst, base *dcb.Snapshot) {
+			copy(objectOf(&dst.Buffer, base.Buffer).BufferSize[:], sizes)
+		}, nil
+	case "--dscp2prio":
+		op, rest, _ := strings.Cut(value, ",")
+		m, err := mlnxQOSList(rest, 63)
+		if err != nil || len(m) != 2 || m[1] > dcb.IEEE_8021QAZ_MAX_TCS-1 || (op != "set" && op != "del") {
+			return nil, errors.New("want set,<dscp>,<prio> or del,<dscp>,<prio>")
+		}
+		app := dcb.App{Selector: dcb.IEEE_8021QAZ_APP_SEL_DSCP, Protocol: uint16(m[0]), Priority: uint8(m[1])}
+		return func(dst, base *dcb.Snapshot) {
+			if dst.Apps == nil {
+				dst.Apps = append([]dcb.App{}, base.Apps...)
+			}
+			// a DSCP maps to one priority, set replaces its entry
+			dst.Apps = slices.DeleteFunc(dst.Apps, func(a dcb.App) bool {
+				return a.Selector == app.Selector && a.Protocol == app.Protocol && (op == "set" || a.Priority == app.Priority)
+			})
+			if op == "set" {
+				dst.Apps = append(dst.Apps, app)
+			}
+		}, nil
+	}
+	return nil, errors.New("unknown or unsupported mlnx_qos option")
+}
+
+// mlnxQOSList parses a comma-separated list of at most 8 numbers up to
+// limit.
+func mlnxQOSList(s string, limit uint64) ([]uint64, error) {
+	parts := splitList(s)
+	if len(parts) > dcb.IEEE_8021QAZ_MAX_TCS {
+		return nil, fmt.Errorf("more than %d values", dcb.IEEE_8021QAZ_MAX_TCS)
+	}
+	vals := make([]uint64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 64)
+		if err != nil || v > limit {
+			return nil, fmt.Errorf("invalid value %q, want 0 to %d", p, limit)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// objectOf returns *dst, first setting it to a copy of base, or the zero
+// value if base is nil, so edits change the current state of an object.
+func objectOf[T any](dst **T, base *T) *T {
+	if *dst == nil {
+		v := new(T)
+		if base != nil {
+			*v = *base
+		}
+		*dst = v
+	}
+	return *dst
+}
+
+// mlnxQOSSnapshot applies the lines of an mlnx_qos description meant for
+// ifname, with the -i of ifname or none, on top of base, the current state
+// of ifname. The objects the lines do not touch are left nil, so a restore
+// of the result leaves them as they are.
+func mlnxQOSSnapshot(lines []*mlnxQOSLine, ifname string, base *dcb.Snapshot) (*dcb.Snapshot, error) {
+	s := &dcb.Snapshot{Schema: dcb.SnapshotSchema, Ifname: ifname, Time: base.Time}
+	applied := false
+	for _, l := range lines {
+		if l.ifname != "" && l.ifname != ifname {
+			continue
+		}
+		for _, edit := range l.edits {
+			edit(s, base)
+		}
+		applied = true
+	}
+	if !applied {
+		return nil, fmt.Errorf("no line for %s", ifname)
+	}
+	if s.ETS != nil {
+		if err := s.ETS.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
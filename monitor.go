@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/api/dcbpb"
+	"github.com/fanzu8/go-dcb/dcb"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	c := newCommand("monitor", "<ifname> [ifname...]", "poll the PFC counters of interfaces and print them at every tick")
+	c.ifaceArgs = true
+	var sched schedule
+	sched.register(c.fs, time.Second)
+	count := c.fs.Int("count", 0, "stop after this many ticks, 0 to run until interrupted")
+	counters := registerCounters(c.fs)
+	pause := c.fs.Bool("pause", false, "with text output, also estimate the time each priority was paused per tick, from the PFC frames and -pause-quanta")
+	quanta := c.fs.Uint("pause-quanta", dcb.DefaultPauseQuanta, "pause time counted per PFC frame, in quanta of 512 bit times")
+	output := c.fs.String("output", "text", "output format: text, csv with a row per interface and priority, ndjson with an object per interface and tick, or pb with a length-delimited dcb.v1.Sample protobuf per interface and tick")
+	c.run = func(ifnames []string) int {
+		if len(ifnames) == 0 || sched.interval <= 0 || sched.jitter < 0 || *quanta > 0xffff {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if ifnames = global.ifaces.args(ifnames); len(ifnames) == 0 {
+			log.Error("every interface is excluded by the interface filter")
+			return exitUsage
+		}
+		if !slices.Contains(counterSources, *counters) {
+			log.Errorf("unknown counter source %q", *counters)
+			return exitUsage
+		}
+		var emit func(t time.Time, r dcb.Result)
+		switch *output {
+		case "text":
+			last := map[string]pfcReading{}
+			emit = func(t time.Time, r dcb.Result) {
+				line := fmt.Sprintf("%s %s requests %s, indications %s", t.Format(time.RFC3339), r.Ifname,
+					global.labels.counters(r.PFC.Requests), global.labels.counters(r.PFC.Indications))
+				if prev, ok := last[r.Ifname]; ok && *pause {
+					line += ", paused " + formatPauses(prev, pfcReading{t, r.PFC}, uint16(*quanta), linkSpeed(r.Ifname))
+				}
+				last[r.Ifname] = pfcReading{t, r.PFC}
+				fmt.Println(line)
+			}
+		case "csv":
+			w := newStatsCSV(os.Stdout)
+			emit = func(t time.Time, r dcb.Result) {
+				w.write(t, r.Ifname, r.PFC)
+				// flush each tick so the output can be followed
+				w.flush()
+			}
+		case "ndjson":
+			emit = func(t time.Time, r dcb.Result) {
+				if err := printJSON(&monitorRecord{Schema: monitorSchema, Time: t, Ifname: r.Ifname, PFC: r.PFC}, false); err != nil {
+					log.Errorf("write ndjson: %v", err)
+				}
+			}
+		case "pb":
+			w := bufio.NewWriter(os.Stdout)
+			emit = func(t time.Time, r dcb.Result) {
+				_, err := protodelim.MarshalTo(w, &dcbpb.Sample{Ifname: r.Ifname, Time: timestamppb.New(t), Pfc: pfcToPB(r.PFC)})
+				if err == nil {
+					err = w.Flush()
+				}
+				if err != nil {
+					log.Errorf("write pb: %v", err)
+				}
+			}
+		default:
+			log.Errorf("unknown output format %q", *output)
+			return exitUsage
+		}
+
+		cl, err := dial(min(dcb.DefaultConcurrency, len(ifnames)))
+		if err != nil {
+			log.Error(err)
+			return exitNetlink
+		}
+		defer cl.Close()
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		show := func(r dcb.Result) {
+			if r.Err != nil {
+				log.Error(r.Err)
+				return
+			}
+			applyCounterSource(*counters, r.Ifname, r.PFC)
+			emit(time.Now(), r)
+		}
+		tick := time.Now()
+		start := tick
+		for n := 1; ; n++ {
+			if sched.stagger {
+				for i, ifname := range ifnames {
+					if !sleepUntil(start.Add(sched.offset(i, len(ifnames))), sigs) {
+						return exitOK
+					}
+					pfc, err := cl.GetPFC(ifname)
+					show(dcb.Result{Ifname: ifname, PFC: pfc, Err: err})
+				}
+			} else {
+				results, _ := cl.GetMany(ifnames, dcb.DefaultConcurrency)
+				for _, r := range results {
+					show(r)
+				}
+			}
+			if n == *count {
+				return exitOK
+			}
+			tick = sched.next(tick)
+			start = tick.Add(sched.delay())
+			if !sleepUntil(start, sigs) {
+				return exitOK
+			}
+		}
+	}
+}
+
+// monitorSchema is the version of monitorRecord, see dcb.SnapshotSchema.
+const monitorSchema = 1
+
+// A monitorRecord is a line of ndjson monitor output, described by
+// schema/monitor.json.
+type monitorRecord struct {
+	Schema int          `json:"schema"`
+	Time   time.Time    `json:"time"`
+	Ifname string       `json:"ifname"`
+	PFC    *dcb.IEEEPFC `json:"pfc"`
+}
+
+// A pfcReading is the PFC counters of an interface and when they were
+// read.
+type pfcReading struct {
+	time time.Time
+	pfc  *dcb.IEEEPFC
+}
+
+// formatPauses formats the estimated pause time of the priorities paused
+// between the readings prev and cur, with its share of the time between
+// them, such as "tx 3:12ms(1.2%) rx none".
+func formatPauses(prev, cur pfcReading, quanta uint16, speedMbps uint64) string {
+	if speedMbps == 0 {
+		return "unknown, no link speed"
+	}
+	interval := cur.time.Sub(prev.time)
+	sent, received := dcb.PauseTimes(prev.pfc, cur.pfc, quanta, speedMbps, interval)
+	list := func(d [dcb.IEEE_8021QAZ_MAX_TCS]time.Duration) string {
+		var parts []string
+		for prio, p := range d {
+			if p > 0 {
+				parts = append(parts, fmt.Sprintf("%s:%v(%s)", global.labels.name(prio), p.Round(time.Microsecond), pauseShare(p, interval)))
+			}
+		}
+		if len(parts) == 0 {
+			return "none"
+		}
+		return strings.Join(parts, " ")
+	}
+	return "tx " + list(sent) + " rx " + list(received)
+}
+
+// pauseShare formats the share of interval the pause time d is.
+func pauseShare(d, interval time.Duration) string {
+	if interval <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*d.Seconds()/interval.Seconds())
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// parseSpeed parses a link speed such as "25G", "100Gbit", "800M" or a bare
+// number of Mb/s and returns it in Mb/s.
+func parseSpeed(s string) (uint64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	v = strings.TrimSuffix(v, "bit")
+	v = strings.TrimSuffix(v, "b/s")
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(v, "t"):
+		mult, v = 1e6, strings.TrimSuffix(v, "t")
+	case strings.HasSuffix(v, "g"):
+		mult, v = 1e3, strings.TrimSuffix(v, "g")
+	case strings.HasSuffix(v, "m"):
+		v = strings.TrimSuffix(v, "m")
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("invalid link speed %q", s)
+	}
+	return uint64(f * mult), nil
+}
+
+// parseLength parses a cable length in meters, such as "30", "30m" or
+// "0.5km".
+func parseLength(s string) (float64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(v, "km"):
+		mult, v = 1e3, strings.TrimSuffix(v, "km")
+	case strings.HasSuffix(v, "m"):
+		v = strings.TrimSuffix(v, "m")
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid cable length %q", s)
+	}
+	return f * mult, nil
+}
+
+// parsePrios parses a comma-separated list of priorities such as "3,4".
+func parsePrios(s string) ([]uint8, error) {
+	var prios []uint8
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		p, err := strconv.ParseUint(f, 10, 8)
+		if err != nil || p >= dcb.IEEE_8021Q_MAX_PRIORITIES {
+			return nil, fmt.Errorf("invalid priority %q", f)
+		}
+		prios = append(prios, uint8(p))
+	}
+	return prios, nil
+}
+
+// parseDSCPRange parses a DSCP value or inclusive range such as "46" or
+// "24-31".
+func parseDSCPRange(s string) (from, to uint8, err error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hi = lo
+	}
+	l, err1 := strconv.ParseUint(lo, 10, 8)
+	h, err2 := strconv.ParseUint(hi, 10, 8)
+	if err1 != nil || err2 != nil || l > h || h > dcb.DSCPMax {
+		return 0, 0, fmt.Errorf("invalid dscp range %q", s)
+	}
+	return uint8(l), uint8(h), nil
+}
+
+// editList calls set with the index and value of each entry of the
+// comma-separated list s of at most n values, for changing part of a
+// list: either positional entries, empty ones left as is, such as
+// ",,40,20", or index:value pairs such as "2:40,3:20".
+func editList(s string, n int, set func(i int, v string) error) error {
+	parts := splitList(s)
+	pairs := strings.Contains(s, ":")
+	if !pairs && len(parts) > n {
+		return fmt.Errorf("%d values given, want at most %d", len(parts), n)
+	}
+	for i, p := range parts {
+		if pairs {
+			idx, v, ok := strings.Cut(p, ":")
+			j, err := strconv.Atoi(idx)
+			if !ok || err != nil || j < 0 || j >= n {
+				return fmt.Errorf("invalid entry %q, want <index>:<value> with an index from 0 to %d", p, n-1)
+			}
+			i, p = j, v
+		}
+		if p == "" {
+			continue
+		}
+		if err := set(i, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
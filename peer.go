@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("peer", "<ifname>", "show the config the link partner advertised over DCBX")
+	c.ifaceArgs = true
+	cee := c.fs.Bool("cee", false, "show the CEE DCBX peer, for links running CEE rather than IEEE DCBX")
+	history := c.fs.String("history", "", "show instead the peer advertisements recorded by the agent serving -health on this address, host:port or unix:/path")
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if *history != "" {
+			h, err := fetchPeerHistory(*history, args[0])
+			if err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			printPeerHistory(args[0], h)
+			return exitOK
+		}
+		return withClient(func(cl *dcb.Client) int {
+			if *cee {
+				st, err := cl.GetCEE(args[0])
+				if err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+				printCEEPeer(args[0], st)
+				return exitOK
+			}
+			peer, err := cl.GetPeer(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printPeer(args[0], peer)
+			return exitOK
+		})
+	}
+}
+
+func printPeer(ifname string, peer *dcb.Peer) {
+	fmt.Printf("ifname: %s\n", ifname)
+	if peer.ETS == nil && peer.PFC == nil && peer.Apps == nil {
+		fmt.Println("peer: nothing advertised")
+		return
+	}
+	if peer.ETS != nil {
+		fmt.Printf("peer ets: %+v\n", peer.ETS)
+	}
+	if peer.PFC != nil {
+		fmt.Printf("peer pfc: pfc_cap %d pfc_en %#x mbc %d delay %d\n", peer.PFC.PFCCap, peer.PFC.PFCEn, peer.PFC.MBC, peer.PFC.Delay)
+	}
+	for _, a := range peer.Apps {
+		printApp("peer app", a)
+	}
+}
+
+func printCEEPeer(ifname string, cee *dcb.CEE) {
+	fmt.Printf("ifname: %s\n", ifname)
+	peer := cee.Peer
+	if peer.PG == nil && peer.PFC == nil && peer.AppInfo == nil && peer.Apps == nil {
+		fmt.Println("cee peer: nothing advertised")
+		return
+	}
+	if peer.PG != nil {
+		fmt.Printf("cee peer pg: %+v\n", peer.PG)
+	}
+	if peer.PFC != nil {
+		fmt.Printf("cee peer pfc: %+v\n", peer.PFC)
+		if cee.PFCEn != nil && *cee.PFCEn != peer.PFC.PFCEn {
+			log.Warnf("ifname: %v, pfc_en %#x differs from the peer's %#x", ifname, *cee.PFCEn, peer.PFC.PFCEn)
+		}
+	}
+	if peer.AppInfo != nil {
+		fmt.Printf("cee peer app info: %+v\n", peer.AppInfo)
+	}
+	for _, a := range peer.Apps {
+		printApp("cee peer app", a)
+	}
+}
+
+// peerHistoryTimeout bounds the query of the agent.
+const peerHistoryTimeout = 10 * time.Second
+
+// fetchPeerHistory gets the peer history of ifname from the /peers of the
+// agent at addr.
+func fetchPeerHistory(addr, ifname string) (*peerHistory, error) {
+	hc := &http.Client{Timeout: peerHistoryTimeout}
+	host := addr
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		host = "agent"
+		hc.Transport = &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		}}
+	} else if strings.HasPrefix(addr, ":") {
+		host = "localhost" + addr
+	}
+	url := "http://" + host + "/peers/" + ifname
+	resp, err := hc.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	var h peerHistory
+	if err := json.NewDecoder(resp.Body).Decode(&h); err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	return &h, nil
+}
+
+func printPeerHistory(ifname string, h *peerHistory) {
+	fmt.Printf("ifname: %s\n", ifname)
+	flapping := ""
+	if h.Flapping {
+		flapping = ", flapping"
+	}
+	fmt.Printf("peer changes: %d since the agent started%s\n", h.Changes, flapping)
+	for _, r := range h.Records {
+		line := r.Time.Format(time.RFC3339)
+		if r.PFC != nil {
+			line += fmt.Sprintf(" pfc_en %#x", r.PFC.PFCEn)
+		}
+		if r.ETS != nil {
+			line += fmt.Sprintf(" prio_tc %v tc_tsa %v tc_bw %v", r.ETS.PrioTC, r.ETS.TCTSA, r.ETS.TCTxBW)
+		}
+		if r.PFC == nil && r.ETS == nil {
+			line += " nothing advertised"
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// A peerRecord is an advertisement of the link partner, with when it was
+// first seen.
+type peerRecord struct {
+	Time time.Time    `json:"time"`
+	ETS  *dcb.IEEEETS `json:"ets,omitempty"`
+	PFC  *dcb.IEEEPFC `json:"pfc,omitempty"`
+}
+
+// A peerHistory holds the last distinct ETS and PFC advertisements of the
+// link partner of an interface, oldest first, dropping the oldest beyond
+// its size.
+type peerHistory struct {
+	Records []peerRecord `json:"records"`
+	// Changes counts the changes of the advertisement since the agent
+	// started, including those dropped from Records.
+	Changes int `json:"changes"`
+	// Flapping is set while the advertisement changes more often than the
+	// agent's -peer-flaps within -peer-flap-window.
+	Flapping bool `json:"flapping"`
+}
+
+// add records peer seen at t if it differs from the last record, keeping at
+// most size records, and reports whether it did.
+func (h *peerHistory) add(t time.Time, peer *dcb.Peer, size int) bool {
+	r := peerRecord{Time: t, ETS: peer.ETS, PFC: peer.PFC}
+	if n := len(h.Records); n > 0 {
+		last := h.Records[n-1]
+		if reflect.DeepEqual(last.ETS, r.ETS) && reflect.DeepEqual(last.PFC, r.PFC) {
+			return false
+		}
+		h.Changes++
+	}
+	if len(h.Records) < size {
+		h.Records = append(h.Records, r)
+	} else {
+		copy(h.Records, h.Records[1:])
+		h.Records[len(h.Records)-1] = r
+	}
+	return true
+}
+
+// changesSince returns the number of changes recorded after t: the records
+// after t but the first the agent saw, which changed from nothing.
+func (h *peerHistory) changesSince(t time.Time) int {
+	n := 0
+	for i, r := range h.Records {
+		if r.Time.After(t) && (i > 0 || h.Changes >= len(h.Records)) {
+			n++
+		}
+	}
+	return n
+}
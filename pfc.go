@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	set := newSubcommand(setGroup, "pfc", "<ifname>", "change the priorities PFC is enabled on, the MACsec bypass capability or the delay allowance")
+	set.ifaceArgs = true
+	prios := set.fs.String("prios", "", `comma-separated priorities to enable PFC on, "none" to disable it, or +prio and -prio entries such as +3,-4 enabling or disabling those only`)
+	mbc := set.fs.String("mbc", "", "MACsec bypass capability advertised to the peer: on or off")
+	delay := set.fs.String("delay", "", "delay allowance in bit times, or as a time at the link speed such as 2.5us, see the delay command")
+	set.run = func(args []string) int {
+		if len(args) != 1 || (*prios == "" && *mbc == "" && *delay == "") {
+			set.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			pfc, err := cl.GetPFC(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			if err := applyPFCFlags(pfc, *prios, *mbc, *delay, linkSpeed(args[0])); err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			if err := audited(cl, cliUser(), "set pfc", args[0], func() error { return cl.SetPFC(args[0], pfc) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+}
+
+// applyPFCFlags updates pfc from the set pfc flags and validates the result.
+// speedMbps converts a delay given as a time.
+func applyPFCFlags(pfc *dcb.IEEEPFC, prios, mbc, delay string, speedMbps uint64) error {
+	switch {
+	case prios == "":
+	case prios == "none":
+		pfc.PFCEn = 0
+	case strings.ContainsAny(prios, "+-"):
+		if err := editPFCPrios(pfc, prios); err != nil {
+			return err
+		}
+	default:
+		ps, err := parsePrios(prios)
+		if err != nil {
+			return err
+		}
+		pfc.PFCEn = 0
+		for _, p := range ps {
+			pfc.PFCEn |= 1 << p
+		}
+	}
+	switch mbc {
+	case "":
+	case "on":
+		pfc.MBC = 1
+	case "off":
+		pfc.MBC = 0
+	default:
+		return fmt.Errorf("invalid mbc %q, want on or off", mbc)
+	}
+	if delay != "" {
+		bits, err := parseDelay(delay, speedMbps)
+		if err != nil {
+			return err
+		}
+		if bits > 0xffff {
+			return fmt.Errorf("delay %d bit times exceeds the 16-bit ieee_pfc delay field", bits)
+		}
+		pfc.Delay = uint16(bits)
+	}
+	return pfc.Validate()
+}
+
+// editPFCPrios enables the priorities of the +prio entries of prios and
+// disables those of the -prio ones, leaving the others as they are.
+func editPFCPrios(pfc *dcb.IEEEPFC, prios string) error {
+	for _, e := range splitList(prios) {
+		if e == "" {
+			continue
+		}
+		on := e[0] == '+'
+		if !on && e[0] != '-' {
+			return fmt.Errorf("invalid priority %q, want +prio or -prio entries only", e)
+		}
+		ps, err := parsePrios(e[1:])
+		if err != nil || len(ps) != 1 {
+			return fmt.Errorf("invalid priority %q", e)
+		}
+		if on {
+			pfc.PFCEn |= 1 << ps[0]
+		} else {
+			pfc.PFCEn &^= 1 << ps[0]
+		}
+	}
+	return nil
+}
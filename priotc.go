@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("prio-tc", "<ifname>", "show the priority to traffic class map of ETS")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			ets, err := cl.GetETS(args[0])
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			printPrioTC(args[0], ets)
+			return exitOK
+		})
+	}
+
+	set := newSubcommand(setGroup, "prio-tc", "<ifname> <prio>:<tc>...", "remap priorities to traffic classes")
+	set.ifaceArgs = true
+	set.run = func(args []string) int {
+		if len(args) < 2 {
+			set.fs.Usage()
+			return exitUsage
+		}
+		m, err := parsePrioTC(args[1:])
+		if err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			if err := audited(cl, cliUser(), "set prio-tc", args[0], func() error { return cl.SetPrioTC(args[0], m) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			return exitOK
+		})
+	}
+}
+
+// parsePrioTC parses mappings such as "0:0 3:1".
+func parsePrioTC(args []string) (map[uint8]uint8, error) {
+	m := map[uint8]uint8{}
+	for _, arg := range args {
+		p, t, ok := strings.Cut(arg, ":")
+		prio, err1 := strconv.ParseUint(p, 10, 8)
+		tc, err2 := strconv.ParseUint(t, 10, 8)
+		if !ok || err1 != nil || err2 != nil ||
+			prio >= dcb.IEEE_8021Q_MAX_PRIORITIES || tc >= dcb.IEEE_8021QAZ_MAX_TCS {
+			return nil, fmt.Errorf("invalid mapping %q, want <prio>:<tc>", arg)
+		}
+		if _, dup := m[uint8(prio)]; dup {
+			return nil, fmt.Errorf("priority %d mapped twice", prio)
+		}
+		m[uint8(prio)] = uint8(tc)
+	}
+	return m, nil
+}
+
+func printPrioTC(ifname string, ets *dcb.IEEEETS) {
+	fmt.Printf("ifname: %s\n", ifname)
+	pairs := make([]string, len(ets.PrioTC))
+	for prio, tc := range ets.PrioTC {
+		pairs[prio] = fmt.Sprintf("%d:%d", prio, tc)
+	}
+	fmt.Printf("prio-tc: %s\n", strings.Join(pairs, " "))
+
+	for tc := 0; tc < dcb.IEEE_8021QAZ_MAX_TCS; tc++ {
+		var prios []string
+		for prio, t := range ets.PrioTC {
+			if int(t) == tc {
+				prios = append(prios, fmt.Sprint(prio))
+			}
+		}
+		if len(prios) > 0 {
+			fmt.Printf("tc %d: prio %s\n", tc, strings.Join(prios, ","))
+		}
+	}
+}
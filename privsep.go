@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// errNoNetAdmin is returned for changes the process lacks the privilege
+// for. It wraps EPERM, as the kernel would fail the set, so it maps to the
+// same exit code and status codes.
+var errNoNetAdmin = fmt.Errorf("changing the dcb config needs CAP_NET_ADMIN: run as root or with sudo, grant the binary cap_net_admin, or give daemons a -helper; read-only queries work without it: %w", syscall.EPERM)
+
+// checkWritable fails changes up front, before the reads leading to them,
+// when neither the process nor a helper can make them.
+func checkWritable() error {
+	if global.helper != nil || hasNetAdmin() {
+		return nil
+	}
+	return errNoNetAdmin
+}
+
+// A privHelper makes the changes of a daemon running unprivileged in a
+// privileged helper process, the rpc command run by a command line such as
+// "sudo -n go-dcb rpc", started on the first change and again after it
+// exits. The daemon keeps reading the config itself.
+type privHelper struct {
+	args []string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	id     int
+}
+
+// newPrivHelper returns the helper run by the command line cmdline, split
+// at spaces.
+func newPrivHelper(cmdline string) (*privHelper, error) {
+	args := strings.Fields(cmdline)
+	if len(args) == 0 {
+		return nil, errors.New("empty helper command")
+	}
+	return &privHelper{args: args}, nil
+}
+
+// A helperError is the error of a request the helper answered, with the
+// JSON-RPC or exit code of its response.
+type helperError struct {
+	Code    int
+	Message string
+}
+
+func (e *helperError) Error() string {
+	return "helper: " + e.Message
+}
+
+// Unwrap returns the errno the code stands for, so callers classify the
+// error as they do those of the library.
+func (e *helperError) Unwrap() error {
+	switch e.Code {
+	case exitUsage, rpcInvalidParams:
+		return syscall.EINVAL
+	case exitNotCapable:
+		return dcb.ErrNoAttribute
+	}
+	return nil
+}
+
+// call runs method for ifname with value in the helper and decodes its
+// result into result, unless nil.
+func (h *privHelper) call(method, ifname string, value, result any) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cmd == nil {
+		if err := h.start(); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("helper: encode %s: %w", method, err)
+	}
+	h.id++
+	req := rpcRequest{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprint(h.id)), Method: method}
+	req.Params, _ = json.Marshal(rpcParams{Ifname: ifname, Value: b})
+	line, _ := json.Marshal(req)
+	if _, err := h.stdin.Write(append(line, '\n')); err != nil {
+		h.stop()
+		return fmt.Errorf("helper: send %s: %w", method, err)
+	}
+	reply, err := h.stdout.ReadBytes('\n')
+	if err != nil {
+		h.stop()
+		return fmt.Errorf("helper: read %s response: %w", method, err)
+	}
+	var resp struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(reply, &resp); err != nil || string(resp.ID) != string(req.ID) {
+		h.stop()
+		return fmt.Errorf("helper: invalid %s response %q", method, strings.TrimSpace(string(reply)))
+	}
+	if resp.Error != nil {
+		return &helperError{Code: resp.Error.Code, Message: resp.Error.Message}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("helper: decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// start runs the helper. h.mu must be held.
+func (h *privHelper) start() error {
+	cmd := exec.Command(h.args[0], h.args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("helper: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("helper: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("helper: start %s: %w", h.args[0], err)
+	}
+	log.Infof("helper %s started, pid %d", strings.Join(h.args, " "), cmd.Process.Pid)
+	h.cmd, h.stdin, h.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// stop ends a helper that failed, so the next call starts another. h.mu
+// must be held.
+func (h *privHelper) stop() {
+	h.stdin.Close()
+	h.cmd.Process.Kill()
+	if err := h.cmd.Wait(); err != nil {
+		log.Warnf("helper exited: %v", err)
+	}
+	h.cmd = nil
+}
+
+// Close ends the helper, letting it finish the request it runs.
+func (h *privHelper) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cmd == nil {
+		return nil
+	}
+	h.stdin.Close()
+	err := h.cmd.Wait()
+	h.cmd = nil
+	return err
+}
+
+// restore applies s to ifname as cl.Restore does, in the helper if there
+// is one.
+func restore(cl *dcb.Client, ifname string, s *dcb.Snapshot) ([]dcb.Object, error) {
+	if global.helper == nil {
+		return cl.Restore(ifname, s)
+	}
+	var res struct {
+		Skipped []dcb.Object `json:"skipped"`
+	}
+	err := global.helper.call("snapshot.set", ifname, s, &res)
+	return res.Skipped, err
+}
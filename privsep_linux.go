@@ -0,0 +1,15 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// hasNetAdmin reports whether the process has CAP_NET_ADMIN in its
+// effective set, which dcbnl set commands need and get commands do not.
+func hasNetAdmin() bool {
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &data[0]); err != nil {
+		// cannot tell, let the kernel decide
+		return true
+	}
+	return data[unix.CAP_NET_ADMIN/32].Effective&(1<<(unix.CAP_NET_ADMIN%32)) != 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("qcn", "<ifname>", "show the IEEE 802.1Qau QCN counters, or with -watch their per traffic class rates")
+	c.ifaceArgs = true
+	watch := c.fs.Bool("watch", false, "poll the counters and print their rates at every tick until interrupted")
+	var sched schedule
+	sched.register(c.fs, time.Second)
+	c.run = func(args []string) int {
+		if len(args) != 1 || sched.interval <= 0 || sched.jitter < 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		ifname := args[0]
+
+		cl, err := dial(1)
+		if err != nil {
+			log.Error(err)
+			return exitNetlink
+		}
+		defer cl.Close()
+
+		stats, err := cl.GetQCNStats(ifname)
+		if err != nil {
+			log.Error(err)
+			return exitCode(err)
+		}
+		if !*watch {
+			fmt.Printf("ifname: %s\n", ifname)
+			fmt.Printf("ieee qcn stats: %+v\n", stats)
+			return exitOK
+		}
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		tick := time.Now()
+		last, lastTime := stats, tick
+		for {
+			tick = sched.next(tick)
+			if !sleepUntil(tick.Add(sched.delay()), sigs) {
+				return exitOK
+			}
+			stats, err := cl.GetQCNStats(ifname)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			now := time.Now()
+			r := qcnRates(last, stats, now.Sub(lastTime))
+			if r.Reset {
+				log.Warnf("ifname: %v, qcn counters reset, driver reloaded?", ifname)
+			}
+			fmt.Printf("%s %s rp active %% %v rps created/s %v\n", now.Format(time.RFC3339), ifname, r.RPActive, r.Created)
+			last, lastTime = stats, now
+		}
+	}
+}
+
+// A qcnRate is the per traffic class rate of change of the QCN counters
+// between two polls.
+type qcnRate struct {
+	// RPActive is the centiseconds a reaction point was active per second,
+	// that is the percentage of the time the tc was rate limited.
+	RPActive [dcb.IEEE_8021QAZ_MAX_TCS]float64
+	// Created is the reaction points created per second.
+	Created [dcb.IEEE_8021QAZ_MAX_TCS]float64
+	// Reset is set when a counter went backwards, see counterDelta.
+	Reset bool
+}
+
+func qcnRates(prev, cur *dcb.IEEEQCNStats, elapsed time.Duration) qcnRate {
+	var r qcnRate
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return r
+	}
+	for i := range cur.RPPPRPCentiseconds {
+		r.RPActive[i] = float64(counterSub(cur.RPPPRPCentiseconds[i], prev.RPPPRPCentiseconds[i], &r.Reset)) / secs
+		r.Created[i] = float64(counterSub(uint64(cur.RPPPCreatedRPs[i]), uint64(prev.RPPPCreatedRPs[i]), &r.Reset)) / secs
+	}
+	return r
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("remap", "<ifname> <from-prio> <to-prio>", "move a class of traffic to another priority, exchanging the PFC, prio_tc, buffer and APP config of the two priorities")
+	c.ifaceArgs = true
+	yes := c.fs.Bool("yes", false, "apply without asking for confirmation")
+	dryRun := c.fs.Bool("dry-run", false, "print the plan only")
+	c.run = func(args []string) int {
+		if len(args) != 3 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		ifname := args[0]
+		from, err1 := strconv.ParseUint(args[1], 10, 8)
+		to, err2 := strconv.ParseUint(args[2], 10, 8)
+		if err1 != nil || err2 != nil || from >= dcb.IEEE_8021Q_MAX_PRIORITIES || to >= dcb.IEEE_8021Q_MAX_PRIORITIES || from == to {
+			log.Errorf("invalid priorities %q and %q, want two different priorities 0 to %d", args[1], args[2], dcb.IEEE_8021Q_MAX_PRIORITIES-1)
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			have, err := cl.Snapshot(ifname)
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			want, err := dcb.SwapPriorities(have, uint8(from), uint8(to))
+			if err != nil {
+				log.Error(err)
+				return exitUsage
+			}
+			bridge := pfcBridge(have.PFC, uint8(from), uint8(to))
+			changes := dcb.Pending(have, want)
+			if len(changes) == 0 {
+				log.Infof("ifname: %v, priorities %d and %d are configured alike, nothing to do", ifname, from, to)
+				return exitOK
+			}
+			printRemapPlan(ifname, uint8(from), uint8(to), bridge, changes)
+			if *dryRun {
+				return exitOK
+			}
+			if !*yes && !confirm("apply?") {
+				log.Info("not applied")
+				return exitFailure
+			}
+			err = audited(cl, cliUser(), fmt.Sprintf("remap %d %d", from, to), ifname, func() error {
+				if bridge != nil {
+					if err := cl.SetPFC(ifname, bridge); err != nil {
+						return err
+					}
+				}
+				skipped, err := cl.Restore(ifname, want)
+				for _, obj := range skipped {
+					log.Warnf("ifname: %v, %s not available on this driver or kernel, skipped", ifname, obj)
+				}
+				return err
+			})
+			if err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Infof("ifname: %v, priority %d moved to %d", ifname, from, to)
+			return exitOK
+		})
+	}
+}
+
+// pfcBridge returns the PFC config enabling both from and to, set before
+// the rest of a remap so the traffic stays lossless while its APP entries
+// move, or nil if PFC is not enabled on one of them only or pfc_cap
+// allows no further priority.
+func pfcBridge(pfc *dcb.IEEEPFC, from, to uint8) *dcb.IEEEPFC {
+	if pfc == nil || (pfc.PFCEn&(1<<from) != 0) == (pfc.PFCEn&(1<<to) != 0) {
+		return nil
+	}
+	b := *pfc
+	b.PFCEn |= 1<<from | 1<<to
+	if err := b.Validate(); err != nil {
+		log.Warnf("pfc cannot be enabled on both priorities during the remap, %v", err)
+		return nil
+	}
+	return &b
+}
+
+func printRemapPlan(ifname string, from, to uint8, bridge *dcb.IEEEPFC, changes []dcb.Change) {
+	fmt.Printf("ifname: %s\n", ifname)
+	fmt.Printf("plan: exchange the config of priorities %d and %d\n", from, to)
+	step := 1
+	if bridge != nil {
+		fmt.Printf("  %d. enable pfc on priorities %v\n", step, bridge.Enabled())
+		step++
+	}
+	fmt.Printf("  %d. apply:\n", step)
+	for _, ch := range changes {
+		fmt.Printf("     %s\n", ch)
+	}
+}
+
+// confirm asks question on stderr and reports whether the answer read from
+// stdin is yes.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("reset", "<ifname>", "return the IEEE config of an interface to defaults: no APP entries, PFC off, flat ETS, all priorities on buffer 0")
+	c.ifaceArgs = true
+	c.run = func(args []string) int {
+		if len(args) != 1 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			if err := audited(cl, cliUser(), "reset", args[0], func() error { return cl.Reset(args[0]) }); err != nil {
+				log.Error(err)
+				return exitCode(err)
+			}
+			log.Infof("ifname: %v, dcb config reset", args[0])
+			return exitOK
+		})
+	}
+}
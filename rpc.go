@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("rpc", "", "serve JSON-RPC 2.0 requests read from stdin, one per line, writing a response per line to stdout")
+	c.run = func(args []string) int {
+		if len(args) != 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			if err := serveRPC(cl, os.Stdin, os.Stdout); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			return exitOK
+		})
+	}
+}
+
+// JSON-RPC 2.0 error codes of the protocol. Errors of the operations have
+// the exit code the command would return as their code instead.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcParams are the parameters of every method: the interface, and the
+// value to set for the set methods, encoded as in the REST API.
+type rpcParams struct {
+	Ifname string          `json:"ifname"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// An rpcMethod runs a method for ifname with the value, nil for the get
+// methods, and returns its result.
+type rpcMethod func(cl *dcb.Client, ifname string, value json.RawMessage) (any, error)
+
+// rpcMethods mirror the routes of the REST API, see newHTTPHandler.
+var rpcMethods = map[string]rpcMethod{
+	"snapshot.get": rpcGetter((*dcb.Client).Snapshot),
+	"snapshot.set": func(cl *dcb.Client, ifname string, value json.RawMessage) (any, error) {
+		var s dcb.Snapshot
+		if err := decodeRPCValue(value, &s); err != nil {
+			return nil, err
+		}
+		var skipped []dcb.Object
+		err := audited(cl, "rpc "+cliUser(), "rpc snapshot.set", ifname, func() error {
+			var err error
+			skipped, err = cl.Restore(ifname, &s)
+			return err
+		})
+		return map[string][]dcb.Object{"skipped": skipped}, err
+	},
+	"caps.get": rpcGetter((*dcb.Client).Probe),
+	"pfc.get":  rpcGetter((*dcb.Client).GetPFC),
+	"pfc.set":  rpcSetter("pfc.set", (*dcb.Client).SetPFC),
+	"ets.get":  rpcGetter((*dcb.Client).GetETS),
+	"ets.set": rpcSetter("ets.set", func(cl *dcb.Client, ifname string, ets *dcb.IEEEETS) error {
+		if err := ets.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", syscall.EINVAL, err)
+		}
+		return cl.SetETS(ifname, ets)
+	}),
+	"maxrate.get": rpcGetter((*dcb.Client).GetMaxrate),
+	"maxrate.set": rpcSetter("maxrate.set", (*dcb.Client).SetMaxrate),
+	"buffer.get":  rpcGetter((*dcb.Client).GetBuffer),
+	"buffer.set":  rpcSetter("buffer.set", (*dcb.Client).SetBuffer),
+	"apps.get":    rpcGetter((*dcb.Client).GetApp),
+	"apps.set": rpcSetter("apps.set", func(cl *dcb.Client, ifname string, apps *[]dcb.App) error {
+		return cl.SetApps(ifname, *apps)
+	}),
+	"trust.get": rpcGetter((*dcb.Client).GetTrust),
+	"trust.set": rpcSetter("trust.set", func(cl *dcb.Client, ifname string, sels *[]dcb.Selector) error {
+		return cl.SetTrust(ifname, *sels)
+	}),
+	"dcbx.get": rpcGetter((*dcb.Client).GetDCBX),
+	"dcbx.set": rpcSetter("dcbx.set", func(cl *dcb.Client, ifname string, mode *uint8) error {
+		return cl.SetDCBX(ifname, *mode)
+	}),
+}
+
+func rpcGetter[T any](get func(cl *dcb.Client, ifname string) (T, error)) rpcMethod {
+	return func(cl *dcb.Client, ifname string, _ json.RawMessage) (any, error) {
+		return get(cl, ifname)
+	}
+}
+
+func rpcSetter[T any](method string, set func(cl *dcb.Client, ifname string, v *T) error) rpcMethod {
+	return func(cl *dcb.Client, ifname string, value json.RawMessage) (any, error) {
+		v := new(T)
+		if err := decodeRPCValue(value, v); err != nil {
+			return nil, err
+		}
+		err := audited(cl, "rpc "+cliUser(), "rpc "+method, ifname, func() error { return set(cl, ifname, v) })
+		return true, err
+	}
+}
+
+// errRPCParams marks errors in the parameters of a request.
+type errRPCParams struct{ err error }
+
+func (e *errRPCParams) Error() string { return e.err.Error() }
+
+func decodeRPCValue(value json.RawMessage, v any) error {
+	if len(value) == 0 {
+		return &errRPCParams{fmt.Errorf("params.value is required")}
+	}
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &errRPCParams{fmt.Errorf("decode params.value: %w", err)}
+	}
+	return nil
+}
+
+// serveRPC answers the requests read from r, one JSON object per line,
+// with a line written to w each, in order, until r ends. Notifications,
+// requests without an id, get no response. Batches are not supported.
+func serveRPC(cl *dcb.Client, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxBodySize)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp := handleRPC(cl, line)
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read requests: %w", err)
+	}
+	return nil
+}
+
+// handleRPC runs the request line and returns its response, nil for a
+// notification.
+func handleRPC(cl *dcb.Client, line []byte) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return rpcFail(nil, rpcParseError, fmt.Sprintf("parse request: %v", err))
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFail(req.ID, rpcInvalidRequest, `invalid request, want jsonrpc "2.0" and a method`)
+	}
+	method, ok := rpcMethods[req.Method]
+	if !ok {
+		return rpcFail(req.ID, rpcMethodNotFound, fmt.Sprintf("unknown method %q", req.Method))
+	}
+	var p rpcParams
+	if err := json.Unmarshal(req.Params, &p); err != nil || p.Ifname == "" {
+		return rpcFail(req.ID, rpcInvalidParams, "invalid params, want an object with ifname")
+	}
+	if !global.ifaces.allows(p.Ifname) {
+		return rpcFail(req.ID, exitUsage, fmt.Sprintf("ifname: %v, excluded by the interface filter", p.Ifname))
+	}
+	result, err := method(cl, p.Ifname, p.Value)
+	if len(req.ID) == 0 {
+		if err != nil {
+			log.Error(err)
+		}
+		return nil
+	}
+	if errors.As(err, new(*errRPCParams)) {
+		return rpcFail(req.ID, rpcInvalidParams, err.Error())
+	}
+	if err != nil {
+		return rpcFail(req.ID, exitCode(err), err.Error())
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func rpcFail(id json.RawMessage, code int, msg string) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
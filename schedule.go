@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// A schedule spaces the polls of a loop: every interval, optionally
+// aligned to multiples of the interval on the wall clock, so polls every
+// 30s run at :00 and :30, and delayed by a random jitter so hosts started
+// together do not poll in lockstep.
+type schedule struct {
+	interval time.Duration
+	jitter   time.Duration
+	align    bool
+	// stagger spreads the interfaces evenly over the interval instead of
+	// polling them all at once.
+	stagger bool
+}
+
+func (s *schedule) register(fs *flag.FlagSet, interval time.Duration) {
+	fs.DurationVar(&s.interval, "interval", interval, "poll interval")
+	fs.DurationVar(&s.jitter, "jitter", 0, "delay each tick by a random duration up to this")
+	fs.BoolVar(&s.align, "align", false, "align ticks to multiples of the interval on the wall clock")
+	fs.BoolVar(&s.stagger, "stagger", false, "spread the interfaces over the interval rather than polling all at each tick")
+}
+
+// next returns the tick after the one at last, without jitter. A loop that
+// fell behind skips the ticks it missed.
+func (s *schedule) next(last time.Time) time.Time {
+	t := last.Add(s.interval)
+	if s.align {
+		t = last.Truncate(s.interval).Add(s.interval)
+	}
+	if now := time.Now(); t.Before(now) {
+		t = now
+		if s.align {
+			t = now.Truncate(s.interval).Add(s.interval)
+		}
+	}
+	return t
+}
+
+// delay returns the jitter to poll a tick with.
+func (s *schedule) delay() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.jitter)
+}
+
+// offset returns when, after the start of a tick, the i-th of n
+// interfaces is polled.
+func (s *schedule) offset(i, n int) time.Duration {
+	if !s.stagger || n == 0 {
+		return 0
+	}
+	return s.interval * time.Duration(i) / time.Duration(n)
+}
+
+// sleepUntil waits until t and reports whether it did, or false when a
+// signal arrived first.
+func sleepUntil(t time.Time, sigs <-chan os.Signal) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-sigs:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"embed"
+	"os"
+	"slices"
+)
+
+// schemas are the JSON Schemas of the machine-readable output, by name.
+//
+//go:embed schema/*.json
+var schemas embed.FS
+
+func init() {
+	c := newCommand("schema", "[snapshot|monitor|drift|check|health]", "print the JSON Schema of snapshot files, monitor ndjson records, drift reports, apply -check reports or health reports")
+	c.choices = []string{"check", "drift", "health", "monitor", "snapshot"}
+	c.run = func(args []string) int {
+		name := "snapshot"
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if len(args) > 1 || !slices.Contains(c.choices, name) {
+			c.fs.Usage()
+			return exitUsage
+		}
+		b, err := schemas.ReadFile("schema/" + name + ".json")
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		os.Stdout.Write(b)
+		return exitOK
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// selftestIfaces are the interfaces selftest creates, as ip(8) arguments
+// after "link add name".
+var selftestIfaces = [][]string{
+	{"dcbtest0", "type", "dummy"},
+	{"dcbtest1", "type", "veth", "peer", "name", "dcbtest2"},
+}
+
+func init() {
+	c := newCommand("selftest", "[ifname...]", "check the decoders and run set/get round trips against dummy and veth interfaces it creates, netdevsim ones with -netdevsim, and the test interfaces given, before touching production NICs")
+	c.ifaceArgs = true
+	create := c.fs.Bool("create", true, "create a dummy interface and a veth pair, with ip(8), to check how interfaces without DCB support are reported; needs CAP_NET_ADMIN")
+	keep := c.fs.Bool("keep", false, "keep the created interfaces")
+	nsimPorts := c.fs.Int("netdevsim", 0, "also create a netdevsim device with this many ports and run the round trips and the event check on them; needs the netdevsim module")
+	nsimID := c.fs.Int("netdevsim-id", 4242, "bus ID of the netdevsim device")
+	eventTimeout := c.fs.Duration("event-timeout", 15*time.Second, "how long to wait for the event of a change; changes without a notification are only found by polling, every 5s")
+	c.run = func(ifnames []string) int {
+		if *nsimPorts < 0 || *eventTimeout <= 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		checks := map[string][]dcb.Check{"decoders": dcb.SelfTestDecoders()}
+		order := []string{"decoders"}
+		// the interfaces given and those of netdevsim get the event check,
+		// the dummy and veth ones have no DCB to change
+		events := slices.Clone(ifnames)
+		if *nsimPorts > 0 {
+			nsim, err := dcb.NewNetdevSim(*nsimID, *nsimPorts)
+			if err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			if !*keep {
+				defer func() {
+					if err := nsim.Close(); err != nil {
+						log.Warn(err)
+					}
+				}()
+			}
+			ifnames = append(ifnames, nsim.Ifnames...)
+			events = append(events, nsim.Ifnames...)
+		}
+		if *create {
+			if !hasNetAdmin() {
+				log.Warn("no CAP_NET_ADMIN, not creating test interfaces")
+			} else {
+				created, err := createSelftestIfaces()
+				if !*keep {
+					defer deleteSelftestIfaces(created)
+				}
+				if err != nil {
+					log.Error(err)
+					return exitFailure
+				}
+				ifnames = append(created, ifnames...)
+			}
+		}
+		code := withClient(func(cl *dcb.Client) int {
+			for _, ifname := range ifnames {
+				checks[ifname] = cl.SelfTest(ifname)
+				if slices.Contains(events, ifname) && !dcb.Failed(checks[ifname]) {
+					checks[ifname] = append(checks[ifname], cl.SelfTestEvents(ifname, *eventTimeout))
+				}
+				order = append(order, ifname)
+			}
+			return exitOK
+		})
+		if code != exitOK {
+			return code
+		}
+
+		failed := false
+		for _, name := range order {
+			printChecks(name, checks[name])
+			failed = failed || dcb.Failed(checks[name])
+		}
+		if failed {
+			return exitFailure
+		}
+		return exitOK
+	}
+}
+
+// createSelftestIfaces creates the interfaces of selftestIfaces and returns
+// the names of those created, also on error.
+func createSelftestIfaces() ([]string, error) {
+	var created []string
+	for _, args := range selftestIfaces {
+		out, err := exec.Command("ip", append([]string{"link", "add", "name"}, args...)...).CombinedOutput()
+		if err != nil {
+			return created, fmt.Errorf("create %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		created = append(created, args[0])
+		if i := len(args) - 1; args[i-2] == "name" {
+			// the veth peer, deleted along with it
+			created = append(created, args[i])
+		}
+	}
+	return created, nil
+}
+
+// deleteSelftestIfaces deletes the interfaces created by
+// createSelftestIfaces. Deleting a veth also deletes its peer, which is
+// then gone already.
+func deleteSelftestIfaces(ifnames []string) {
+	for _, ifname := range ifnames {
+		if _, err := net.InterfaceByName(ifname); err != nil {
+			continue
+		}
+		if out, err := exec.Command("ip", "link", "del", ifname).CombinedOutput(); err != nil {
+			log.Warnf("delete %s: %v: %s", ifname, err, strings.TrimSpace(string(out)))
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("serve", "", "run a daemon exposing DCB configuration to remote controllers")
+	grpcAddr := c.fs.String("grpc", "", "serve the gRPC API on this address, host:port or unix:/path")
+	httpAddr := c.fs.String("http", "", "serve the REST API on this address, host:port or unix:/path")
+	tokenFile := c.fs.String("token-file", envOr("DCB_TOKEN_FILE", ""), "file holding the bearer token the REST and gRPC APIs require (env DCB_TOKEN_FILE)")
+	retention := c.fs.Duration("history", 0, "keep this long of PFC and ETS samples of the interfaces in memory, served at /v1/interfaces/{ifname}/history of the REST API for the history command; 0 keeps none")
+	historyInterval := c.fs.Duration("history-interval", time.Second, "sampling interval of -history")
+	c.run = func(args []string) int {
+		if len(args) != 0 || (*grpcAddr == "" && *httpAddr == "") || *retention < 0 || *historyInterval <= 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		token := &httpToken{path: *tokenFile}
+		if err := token.load(); err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		if *httpAddr != "" && token.get() == "" && !strings.HasPrefix(*httpAddr, "unix:") {
+			log.Warnf("rest api on %v has no token, anyone reaching it can change the dcb config", *httpAddr)
+		}
+		if *grpcAddr != "" && token.get() == "" && !strings.HasPrefix(*grpcAddr, "unix:") {
+			log.Warnf("grpc api on %v has no token, anyone reaching it can change the dcb config", *grpcAddr)
+		}
+
+		if *retention > 0 && *httpAddr == "" {
+			log.Warn("-history is served by the rest api, which -http is not given for, not sampling")
+		}
+		if err := checkWritable(); err != nil {
+			log.Warnf("serving read-only, sets will fail: %v", err)
+		}
+
+		return withClient(func(cl *dcb.Client) int {
+			var hist *history
+			if *retention > 0 && *httpAddr != "" {
+				hist = newHistory(*historyInterval, *retention)
+				ctx, cancel := context.WithCancel(context.Background())
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					hist.run(ctx, cl)
+				}()
+				// stopped before the Client is closed
+				defer func() {
+					cancel()
+					<-done
+				}()
+			}
+			var servers []server
+			if *grpcAddr != "" {
+				srv := newGRPCServer(cl, token)
+				servers = append(servers, server{"grpc", *grpcAddr, srv.Serve, srv.GracefulStop})
+			}
+			if *httpAddr != "" {
+				srv := &http.Server{Handler: newHTTPHandler(cl, token, hist)}
+				servers = append(servers, server{"http", *httpAddr,
+					func(ln net.Listener) error {
+						if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+							return err
+						}
+						return nil
+					},
+					func() { srv.Shutdown(context.Background()) },
+				})
+			}
+			return runServers(servers, func() {
+				if err := token.load(); err != nil {
+					log.Errorf("reload token, keeping the previous one: %v", err)
+				}
+			})
+		})
+	}
+}
+
+// A server is one of the APIs of serve.
+type server struct {
+	name  string
+	addr  string
+	serve func(net.Listener) error
+	stop  func()
+}
+
+// runServers serves all servers until SIGINT or SIGTERM, or until one of
+// them fails, which stops the others. SIGHUP calls reload.
+func runServers(servers []server, reload func()) int {
+	lns := make([]net.Listener, len(servers))
+	for i, srv := range servers {
+		ln, err := listen(srv.addr)
+		if err != nil {
+			log.Error(err)
+			for _, ln := range lns[:i] {
+				ln.Close()
+			}
+			return exitFailure
+		}
+		lns[i] = ln
+	}
+
+	shutdown := sync.OnceFunc(func() {
+		for _, srv := range servers {
+			srv.stop()
+		}
+	})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+	go func() {
+		for sig := range sigs {
+			if sig == syscall.SIGHUP {
+				log.Infof("%v received, reloading", sig)
+				reload()
+				continue
+			}
+			log.Infof("%v received, stopping", sig)
+			shutdown()
+			return
+		}
+	}()
+
+	// the listeners are open, clients can connect from now on
+	sdNotify("READY=1")
+	defer sdNotify("STOPPING=1")
+	if wd := sdWatchdog(); wd > 0 {
+		go func() {
+			for range time.Tick(wd / 2) {
+				sdNotify("WATCHDOG=1")
+			}
+		}()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		code = exitOK
+	)
+	for i, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Infof("serving %s on %v", srv.name, lns[i].Addr())
+			if err := srv.serve(lns[i]); err != nil {
+				log.Errorf("%s: %v", srv.name, err)
+				mu.Lock()
+				code = exitFailure
+				mu.Unlock()
+				shutdown()
+			}
+		}()
+	}
+	wg.Wait()
+	return code
+}
+
+// httpToken is the bearer token of the REST API, re-read from path on
+// SIGHUP so it can be rotated without dropping connections. An empty file
+// is an error rather than no token, so a truncated or half-written file
+// does not open the API.
+type httpToken struct {
+	path  string
+	value atomic.Pointer[string]
+}
+
+func (t *httpToken) load() error {
+	if t.path == "" {
+		t.value.Store(new(string))
+		return nil
+	}
+	b, err := os.ReadFile(t.path)
+	if err != nil {
+		return err
+	}
+	v := strings.TrimSpace(string(b))
+	if v == "" {
+		return fmt.Errorf("token file %s is empty", t.path)
+	}
+	t.value.Store(&v)
+	return nil
+}
+
+func (t *httpToken) get() string { return *t.value.Load() }
+
+// listen opens addr, a TCP host:port or unix:/path for a unix socket, which
+// a stale socket file from a previous run does not block.
+func listen(addr string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		os.Remove(path)
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", addr)
+}
@@ -0,0 +1,5 @@
+package main
+
+// setGroup holds the commands changing device configuration, one per
+// attribute.
+var setGroup = newGroup("set", "change the DCB configuration of an interface")
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("show", "<ifname> [ifname...]", "show the whole DCB state of interfaces, in one of the output formats of the library's renderers")
+	c.ifaceArgs = true
+	format := c.fs.String("format", "table", "output format: "+strings.Join(dcb.Renderers(), ", "))
+	c.run = func(ifnames []string) int {
+		if len(ifnames) == 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		r, ok := dcb.LookupRenderer(*format)
+		if !ok {
+			log.Errorf("unknown format %q, want one of %s", *format, strings.Join(dcb.Renderers(), ", "))
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			code := exitOK
+			for i, ifname := range ifnames {
+				cfg, err := cl.GetAll(ifname)
+				if err != nil {
+					log.Error(err)
+					code = exitCode(err)
+					continue
+				}
+				b, err := r.Render(cfg)
+				if err != nil {
+					log.Errorf("ifname: %v, render %s: %v", ifname, *format, err)
+					return exitFailure
+				}
+				switch {
+				case i == 0:
+				case *format == "yaml":
+					// a document per interface
+					os.Stdout.WriteString("---\n")
+				case *format == "table":
+					os.Stdout.WriteString("\n")
+				}
+				os.Stdout.Write(b)
+			}
+			return code
+		})
+	}
+}
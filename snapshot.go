@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+func init() {
+	c := newCommand("snapshot", "<ifname> [ifname...]", "save the full DCB state of interfaces as JSON")
+	c.ifaceArgs = true
+	out := c.fs.String("o", "-", "output file, - for stdout")
+	only := c.fs.String("only", "", "save only these comma-separated objects: "+objectNames(dcb.SnapshotObjects))
+	exclude := c.fs.String("exclude", "", "leave out these comma-separated objects, and stats for the PFC counters")
+	output := c.fs.String("output", "json", "output format: json, or pb with a length-delimited dcb.v1.Config protobuf per interface")
+	provenance := c.fs.Bool("provenance", false, "annotate each object, and APP entry, with its source: local, learned from the peer through dcbx, or unknown; json only")
+	c.run = func(ifnames []string) int {
+		if len(ifnames) == 0 {
+			c.fs.Usage()
+			return exitUsage
+		}
+		objs, stats, err := selectObjects(*only, *exclude)
+		if err != nil {
+			log.Error(err)
+			return exitUsage
+		}
+		write := writeSnapshots
+		switch *output {
+		case "json":
+		case "pb":
+			write = writeSnapshotsPB
+		default:
+			log.Errorf("unknown output format %q", *output)
+			return exitUsage
+		}
+		if *provenance && *output != "json" {
+			log.Error("-provenance needs -output json")
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			snaps := make([]*dcb.Snapshot, 0, len(ifnames))
+			var annotated []annotatedSnapshot
+			for _, ifname := range ifnames {
+				s, err := cl.SnapshotOf(ifname, objs...)
+				if len(ifnames) > 1 && skipNotCapable(err) {
+					continue
+				}
+				if err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+				if !stats && s.PFC != nil {
+					s.PFC.Requests, s.PFC.Indications = [dcb.IEEE_8021QAZ_MAX_TCS]uint64{}, [dcb.IEEE_8021QAZ_MAX_TCS]uint64{}
+				}
+				snaps = append(snaps, s)
+				if *provenance {
+					p, err := cl.Provenance(ifname, s)
+					if err != nil {
+						log.Error(err)
+						return exitCode(err)
+					}
+					annotated = append(annotated, annotatedSnapshot{s, p})
+				}
+			}
+			if *provenance {
+				write = func(path string, _ []*dcb.Snapshot) error { return writeJSONFile(path, annotated) }
+			}
+			if err := write(*out, snaps); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			return exitOK
+		})
+	}
+
+	r := newCommand("restore", "<file>", "reapply the DCB state saved by snapshot")
+	ifname := r.fs.String("ifname", "", "restore a single-interface snapshot onto this interface instead")
+	r.run = func(args []string) int {
+		if len(args) != 1 {
+			r.fs.Usage()
+			return exitUsage
+		}
+		b, err := os.ReadFile(args[0])
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		config, err := parseConfig(args[0], b)
+		if err != nil {
+			log.Error(err)
+			return exitFailure
+		}
+		snaps := config.snaps
+		if !config.plain() {
+			// patterns and templates apply to the interfaces of the host
+			ifnames := []string{*ifname}
+			if *ifname == "" {
+				if ifnames, err = hostInterfaces(); err != nil {
+					log.Errorf("list interfaces: %v", err)
+					return exitNetlink
+				}
+			}
+			if snaps, err = config.expand(ifnames); err != nil {
+				log.Error(err)
+				return exitFailure
+			}
+			if len(snaps) == 0 {
+				log.Errorf("%s applies to none of %s", args[0], strings.Join(ifnames, ", "))
+				return exitUsage
+			}
+		}
+		if *ifname != "" && len(snaps) != 1 {
+			log.Errorf("-ifname needs a single-interface snapshot, %s has %d", args[0], len(snaps))
+			return exitUsage
+		}
+		return withClient(func(cl *dcb.Client) int {
+			for _, s := range snaps {
+				target := s.Ifname
+				if *ifname != "" {
+					target = *ifname
+				}
+				var skipped []dcb.Object
+				err := audited(cl, cliUser(), "restore "+args[0], target, func() error {
+					var err error
+					skipped, err = cl.Restore(target, s)
+					return err
+				})
+				for _, obj := range skipped {
+					log.Warnf("ifname: %v, %s not available on this driver or kernel, skipped", target, obj)
+				}
+				if err != nil {
+					log.Error(err)
+					return exitCode(err)
+				}
+				log.Infof("ifname: %v, restored snapshot of %v taken %v", target, s.Ifname, s.Time.Format("2006-01-02 15:04:05"))
+			}
+			return exitOK
+		})
+	}
+}
+
+// An annotatedSnapshot is a snapshot with the provenance of its objects,
+// as snapshot -provenance saves it. Restore ignores the annotation.
+type annotatedSnapshot struct {
+	*dcb.Snapshot
+	Provenance *dcb.Provenance `json:"provenance"`
+}
+
+func writeSnapshots(path string, snaps []*dcb.Snapshot) error {
+	return writeJSONFile(path, snaps)
+}
+
+// writeJSONFile writes v, indented and with the keys of -json-style, to
+// the file path, or stdout for -.
+func writeJSONFile(path string, v any) error {
+	b, err := marshalJSON(v, true)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	b = append(b, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func writeSnapshotsPB(path string, snaps []*dcb.Snapshot) error {
+	var b []byte
+	for _, s := range snaps {
+		var err error
+		if b, err = (protodelim.MarshalOptions{}).MarshalAppend(b, snapshotToPB(s)); err != nil {
+			return fmt.Errorf("encode snapshot: %w", err)
+		}
+	}
+	if path == "-" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func readSnapshots(path string) ([]*dcb.Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshots(path, b)
+}
+
+// decodeSnapshots decodes the content b of the snapshot file path, written
+// with any -json-style.
+func decodeSnapshots(path string, b []byte) ([]*dcb.Snapshot, error) {
+	var snaps []*dcb.Snapshot
+	// invalid JSON is left for Unmarshal to report
+	if nb, err := normalizeSnapshotKeys(b); err == nil {
+		b = nb
+	}
+	if err := json.Unmarshal(b, &snaps); err != nil {
+		return nil, fmt.Errorf("decode snapshot %s: %w", path, err)
+	}
+	for _, s := range snaps {
+		if err := s.CheckSchema(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return snaps, nil
+}
+
+// selectObjects returns the objects of -only minus those of -exclude, and
+// whether the PFC counters are kept.
+func selectObjects(only, exclude string) ([]dcb.Object, bool, error) {
+	objs := dcb.SnapshotObjects
+	if only != "" {
+		objs = nil
+		for _, name := range strings.Split(only, ",") {
+			obj := dcb.Object(strings.TrimSpace(name))
+			if !slices.Contains(dcb.SnapshotObjects, obj) {
+				return nil, false, fmt.Errorf("unknown object %q in -only, want %s", name, objectNames(dcb.SnapshotObjects))
+			}
+			objs = append(objs, obj)
+		}
+	}
+	stats := true
+	for _, name := range strings.Split(exclude, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case name == "stats":
+			stats = false
+		case slices.Contains(dcb.SnapshotObjects, dcb.Object(name)):
+			objs = slices.DeleteFunc(slices.Clone(objs), func(o dcb.Object) bool { return o == dcb.Object(name) })
+		default:
+			return nil, false, fmt.Errorf("unknown object %q in -exclude, want %s or stats", name, objectNames(dcb.SnapshotObjects))
+		}
+	}
+	if len(objs) == 0 {
+		return nil, false, fmt.Errorf("-only and -exclude leave no object")
+	}
+	return objs, stats, nil
+}
+
+func objectNames(objs []dcb.Object) string {
+	names := make([]string, len(objs))
+	for i, obj := range objs {
+		names[i] = string(obj)
+	}
+	return strings.Join(names, ",")
+}
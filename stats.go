@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// statsCSV writes PFC counters as CSV, one row per interface and priority,
+// under a header row.
+type statsCSV struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newStatsCSV(w io.Writer) *statsCSV {
+	return &statsCSV{w: csv.NewWriter(w)}
+}
+
+func (s *statsCSV) write(t time.Time, ifname string, pfc *dcb.IEEEPFC) {
+	if !s.header {
+		s.w.Write([]string{"time", "ifname", "priority", "label", "pfc_enabled", "requests", "indications"})
+		s.header = true
+	}
+	ts := t.UTC().Format(time.RFC3339Nano)
+	for prio := 0; prio < dcb.IEEE_8021QAZ_MAX_TCS; prio++ {
+		s.w.Write([]string{
+			ts, ifname, strconv.Itoa(prio), global.labels[prio],
+			strconv.FormatBool(pfc.PFCEn&(1<<prio) != 0),
+			strconv.FormatUint(pfc.Requests[prio], 10),
+			strconv.FormatUint(pfc.Indications[prio], 10),
+		})
+	}
+}
+
+// flush writes buffered rows out, logging a failing writer.
+func (s *statsCSV) flush() {
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		log.Errorf("write csv: %v", err)
+	}
+}
+
+// counterSources are the values of the -counters flag, choosing where the
+// PFC counters come from: the dcbnl ones only, the ethtool pause counters
+// when the dcbnl ones are all zero, as some drivers leave them, or the
+// larger of both per priority.
+var counterSources = []string{"dcbnl", "fallback", "merge"}
+
+func registerCounters(fs *flag.FlagSet) *string {
+	return fs.String("counters", "dcbnl", "source of the pfc counters: dcbnl, fallback to the ethtool pause counters if the dcbnl ones are all zero, or merge both taking the larger per priority")
+}
+
+// applyCounterSource replaces the counters of pfc as src asks, leaving them
+// as they are if the driver has no ethtool pause counters.
+func applyCounterSource(src, ifname string, pfc *dcb.IEEEPFC) {
+	var zero [dcb.IEEE_8021QAZ_MAX_TCS]uint64
+	switch {
+	case src == "dcbnl":
+		return
+	case src == "fallback" && (pfc.Requests != zero || pfc.Indications != zero):
+		return
+	}
+	ps, err := dcb.EthtoolPauseStats(ifname)
+	if err != nil {
+		log.Debug(err)
+		return
+	}
+	for prio := range pfc.Requests {
+		pfc.Requests[prio] = max(pfc.Requests[prio], ps.Requests[prio])
+		pfc.Indications[prio] = max(pfc.Indications[prio], ps.Indications[prio])
+	}
+}
+
+// counterState is the PFC counters a previous run saw, keyed by interface,
+// for reporting deltas between cron-driven runs.
+type counterState map[string]counterEntry
+
+type counterEntry struct {
+	Time        time.Time                        `json:"time"`
+	Requests    [dcb.IEEE_8021QAZ_MAX_TCS]uint64 `json:"requests"`
+	Indications [dcb.IEEE_8021QAZ_MAX_TCS]uint64 `json:"indications"`
+}
+
+// loadCounterState reads the state file at path. A missing file is an
+// empty state, as on the first run.
+func loadCounterState(path string) (counterState, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return counterState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st := counterState{}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("decode counter state %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// save writes the state to path through a temporary file, so a run killed
+// midway leaves the previous state intact.
+func (st counterState) save(path string) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("encode counter state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// A counterDelta is the change of the PFC counters of an interface since
+// the previous run.
+type counterDelta struct {
+	Since       time.Time
+	Requests    [dcb.IEEE_8021QAZ_MAX_TCS]uint64
+	Indications [dcb.IEEE_8021QAZ_MAX_TCS]uint64
+	// Reset is set when a counter went backwards, as after a driver reload;
+	// the deltas of the reset counters are then their current values.
+	Reset bool
+}
+
+// update records pfc as the counters of ifname seen at t and returns the
+// delta since the previous run, or nil on the first.
+func (st counterState) update(ifname string, t time.Time, pfc *dcb.IEEEPFC) *counterDelta {
+	prev, ok := st[ifname]
+	st[ifname] = counterEntry{Time: t, Requests: pfc.Requests, Indications: pfc.Indications}
+	if !ok {
+		return nil
+	}
+	d := &counterDelta{Since: prev.Time}
+	for i := range pfc.Requests {
+		d.Requests[i] = counterSub(pfc.Requests[i], prev.Requests[i], &d.Reset)
+		d.Indications[i] = counterSub(pfc.Indications[i], prev.Indications[i], &d.Reset)
+	}
+	return d
+}
+
+// counterSub returns the increase of a counter from old to cur. A counter
+// that went backwards was reset, its increase is then cur and reset is set.
+func counterSub(cur, old uint64, reset *bool) uint64 {
+	if cur < old {
+		*reset = true
+		return cur
+	}
+	return cur - old
+}
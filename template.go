@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// A configFile is a snapshot file read as desired state, which may express
+// the policy of a fleet rather than of one host: its snapshots may have a
+// shell pattern as ifname, such as ens*f[01], applying to every interface
+// matching it, and the file may be a text/template executed for each
+// interface with its configVars, such as
+//
+//	{{if ge .Speed 100000}} ... {{end}}
+//
+// with the functions mul and div for arithmetic on integers.
+type configFile struct {
+	path string
+	tmpl *template.Template // nil if the file has no template actions
+	// snaps is the decoded file if tmpl is nil.
+	snaps []*dcb.Snapshot
+}
+
+// configVars are the variables of a config template for an interface.
+type configVars struct {
+	Ifname string
+	Speed  int    // link speed in Mb/s, 0 if down or unknown
+	PCI    string // PCI address, "" if not a PCI device
+	// Slot is PCI without the function, the same for the ports of an
+	// adapter.
+	Slot string
+}
+
+var configFuncs = template.FuncMap{
+	"mul": func(a, b int) int { return a * b },
+	"div": func(a, b int) int { return a / b },
+}
+
+// parseConfig parses the content b of the config file name.
+func parseConfig(name string, b []byte) (*configFile, error) {
+	f := &configFile{path: name}
+	if bytes.Contains(b, []byte("{{")) {
+		tmpl, err := template.New(name).Funcs(configFuncs).Parse(string(b))
+		if err != nil {
+			return nil, err
+		}
+		f.tmpl = tmpl
+		return f, nil
+	}
+	snaps, err := decodeSnapshots(name, b)
+	if err != nil {
+		return nil, err
+	}
+	f.snaps = snaps
+	for _, s := range snaps {
+		if _, err := path.Match(s.Ifname, ""); err != nil {
+			return nil, fmt.Errorf("%s: ifname %q: %w", name, s.Ifname, err)
+		}
+	}
+	return f, nil
+}
+
+// plain reports whether the file is a plain snapshot file, without
+// template actions nor patterns, which needs no expansion.
+func (f *configFile) plain() bool {
+	if f.tmpl != nil {
+		return false
+	}
+	for _, s := range f.snaps {
+		if isPattern(s.Ifname) {
+			return false
+		}
+	}
+	return true
+}
+
+// expand returns the snapshot of each of ifnames the file has one for: the
+// snapshot of the interface by name or else that of the first pattern
+// matching it, renamed to the interface. Interfaces nothing applies to are
+// left out.
+func (f *configFile) expand(ifnames []string) ([]*dcb.Snapshot, error) {
+	var out []*dcb.Snapshot
+	for _, ifname := range ifnames {
+		snaps := f.snaps
+		if f.tmpl != nil {
+			var buf bytes.Buffer
+			if err := f.tmpl.Execute(&buf, varsOf(ifname)); err != nil {
+				return nil, err
+			}
+			var err error
+			if snaps, err = decodeSnapshots(f.path+" for "+ifname, buf.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+		s, err := matchSnapshot(snaps, ifname)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		if s != nil {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// matchSnapshot returns the snapshot of snaps for ifname, nil if none.
+func matchSnapshot(snaps []*dcb.Snapshot, ifname string) (*dcb.Snapshot, error) {
+	for _, s := range snaps {
+		if s.Ifname == ifname {
+			return s, nil
+		}
+	}
+	for _, s := range snaps {
+		ok, err := path.Match(s.Ifname, ifname)
+		if err != nil {
+			return nil, fmt.Errorf("ifname %q: %w", s.Ifname, err)
+		}
+		if ok {
+			c := *s
+			c.Ifname = ifname
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func varsOf(ifname string) configVars {
+	v := configVars{Ifname: ifname, Speed: int(linkSpeed(ifname)), PCI: dcb.PCIAddress(ifname)}
+	if i := strings.LastIndexByte(v.PCI, '.'); i >= 0 {
+		v.Slot = v.PCI[:i]
+	}
+	return v
+}
+
+func isPattern(ifname string) bool {
+	return strings.ContainsAny(ifname, `*?[\`)
+}
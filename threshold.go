@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+// A threshold is a -fail-if expression such as "pfc_rx_rate[prio3] >
+// 1000/s". Without a priority it is breached when any priority is.
+type threshold struct {
+	expr   string
+	metric string
+	prio   int // -1 for any
+	op     string
+	value  float64
+}
+
+// thresholdMetrics are the metrics thresholds compare, computed from the
+// PFC counters at the start and the end of the sampling window.
+var thresholdMetrics = map[string]func(d, secs float64) float64{
+	"pfc_tx":      func(d, secs float64) float64 { return d },
+	"pfc_rx":      func(d, secs float64) float64 { return d },
+	"pfc_tx_rate": func(d, secs float64) float64 { return d / secs },
+	"pfc_rx_rate": func(d, secs float64) float64 { return d / secs },
+}
+
+var thresholdRE = regexp.MustCompile(`^\s*([a-z_]+)(?:\[prio([0-7])\])?\s*(>=|<=|==|!=|>|<)\s*([0-9.eE+]+)(/s)?\s*$`)
+
+func parseThreshold(s string) (threshold, error) {
+	m := thresholdRE.FindStringSubmatch(s)
+	if m == nil {
+		return threshold{}, fmt.Errorf("invalid threshold %q, want metric[prioN] op value", s)
+	}
+	if thresholdMetrics[m[1]] == nil {
+		return threshold{}, fmt.Errorf("unknown metric %q in %q, want pfc_tx, pfc_rx, pfc_tx_rate or pfc_rx_rate", m[1], s)
+	}
+	if m[5] != "" && !strings.HasSuffix(m[1], "_rate") {
+		return threshold{}, fmt.Errorf("%q: /s applies to rates only", s)
+	}
+	v, err := strconv.ParseFloat(m[4], 64)
+	if err != nil {
+		return threshold{}, fmt.Errorf("invalid value in threshold %q", s)
+	}
+	t := threshold{expr: strings.TrimSpace(s), metric: m[1], prio: -1, op: m[3], value: v}
+	if m[2] != "" {
+		t.prio, _ = strconv.Atoi(m[2])
+	}
+	return t, nil
+}
+
+// thresholds is a flag.Value collecting repeated -fail-if flags.
+type thresholds []threshold
+
+func (ts *thresholds) String() string {
+	var s []string
+	for _, t := range *ts {
+		s = append(s, t.expr)
+	}
+	return strings.Join(s, ", ")
+}
+
+func (ts *thresholds) Set(s string) error {
+	t, err := parseThreshold(s)
+	if err != nil {
+		return err
+	}
+	*ts = append(*ts, t)
+	return nil
+}
+
+// eval checks t against the counters first and last sampled secs apart.
+func (t threshold) eval(first, last *dcb.IEEEPFC, secs float64) dcb.Check {
+	c := dcb.Check{Name: t.expr, Status: dcb.CheckPass}
+	counters := func(p *dcb.IEEEPFC) [dcb.IEEE_8021QAZ_MAX_TCS]uint64 {
+		if strings.HasPrefix(t.metric, "pfc_tx") {
+			return p.Requests
+		}
+		return p.Indications
+	}
+	a, b := counters(first), counters(last)
+	var vals []string
+	for prio := range b {
+		if t.prio >= 0 && prio != t.prio {
+			continue
+		}
+		d := float64(0)
+		if b[prio] >= a[prio] {
+			d = float64(b[prio] - a[prio])
+		}
+		v := thresholdMetrics[t.metric](d, secs)
+		if compare(v, t.op, t.value) {
+			c.Status = dcb.CheckFail
+			vals = append(vals, fmt.Sprintf("prio%d %.6g", prio, v))
+		}
+	}
+	if c.Status == dcb.CheckFail {
+		c.Detail = "breached: " + strings.Join(vals, ", ")
+	}
+	return c
+}
+
+func compare(v float64, op string, limit float64) bool {
+	switch op {
+	case ">":
+		return v > limit
+	case ">=":
+		return v >= limit
+	case "<":
+		return v < limit
+	case "<=":
+		return v <= limit
+	case "==":
+		return v == limit
+	case "!=":
+		return v != limit
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/fanzu8/go-dcb/dcb"
+)
+
+func init() {
+	c := newCommand("top", "[ifname...]", "show the PFC counters of interfaces with live rates and peer mismatches, refreshed on an interval, as top does")
+	c.ifaceArgs = true
+	interval := c.fs.Duration("interval", 2*time.Second, "refresh interval")
+	counters := registerCounters(c.fs)
+	all := c.fs.Bool("all", false, "also show the priorities without PFC, and the interfaces without DCB support")
+	quanta := c.fs.Uint("pause-quanta", dcb.DefaultPauseQuanta, "pause time counted per PFC frame for the paused columns, in quanta of 512 bit times")
+	c.run = func(ifnames []string) int {
+		if *interval <= 0 || *quanta > 0xffff {
+			c.fs.Usage()
+			return exitUsage
+		}
+		if !slices.Contains(counterSources, *counters) {
+			log.Errorf("unknown counter source %q", *counters)
+			return exitUsage
+		}
+		if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+			log.Error("top needs a terminal, use monitor to record the counters")
+			return exitUsage
+		}
+		if len(ifnames) == 0 {
+			var err error
+			if ifnames, err = hostInterfaces(); err != nil {
+				log.Errorf("list interfaces: %v", err)
+				return exitNetlink
+			}
+		}
+		cl, err := dial(min(dcb.DefaultConcurrency, max(len(ifnames), 1)))
+		if err != nil {
+			log.Error(err)
+			return exitNetlink
+		}
+		defer cl.Close()
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		// hide the cursor while drawing, shown again on exit
+		fmt.Print("\x1b[?25l")
+		defer fmt.Print("\x1b[?25h\n")
+
+		t := &topView{counters: *counters, all: *all, quanta: uint16(*quanta), last: map[string]pfcReading{}}
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+		for {
+			os.Stdout.Write(t.frame(cl, ifnames))
+			select {
+			case <-sigs:
+				return exitOK
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// topView renders the frames of top, keeping the previous counters of
+// each interface to compute the rates.
+type topView struct {
+	counters string
+	all      bool
+	quanta   uint16
+	last     map[string]pfcReading
+}
+
+// ANSI attributes of the frame.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiReverse = "\x1b[7m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiClear   = "\x1b[H\x1b[2J"
+)
+
+// frame queries the interfaces and renders a screen: a line per interface
+// with its lossless priorities and how it compares with the peer, then a
+// row per priority with the PFC frames sent and received, in total, per
+// second and as the estimated share of time paused since the previous
+// frame. Priorities whose counters moved are highlighted.
+func (t *topView) frame(cl *dcb.Client, ifnames []string) []byte {
+	var b bytes.Buffer
+	now := time.Now()
+	b.WriteString(ansiClear)
+	fmt.Fprintf(&b, "%s%s - %d interfaces, %s counters%s\n\n", ansiBold, now.Format(time.TimeOnly), len(ifnames), t.counters, ansiReset)
+	results, _ := cl.GetMany(ifnames, dcb.DefaultConcurrency)
+	for _, r := range results {
+		if r.Err != nil {
+			if !t.all && isNotCapable(r.Err) {
+				continue
+			}
+			fmt.Fprintf(&b, "%s%-16s %v%s\n\n", ansiRed, r.Ifname, r.Err, ansiReset)
+			continue
+		}
+		applyCounterSource(t.counters, r.Ifname, r.PFC)
+		prev, seen := t.last[r.Ifname]
+		t.last[r.Ifname] = pfcReading{now, r.PFC}
+
+		peer := peerCheck(cl, r.Ifname, r.PFC)
+		color := ""
+		switch peer.Status {
+		case dcb.CheckFail:
+			color = ansiRed
+		case dcb.CheckWarn:
+			color = ansiYellow
+		}
+		fmt.Fprintf(&b, "%s%-16s%s pfc on %v  %speer %s: %s%s\n", ansiBold, r.Ifname, ansiReset,
+			r.PFC.Enabled(), color, peer.Status, peer.Detail, ansiReset)
+		fmt.Fprintf(&b, "  %-14s %14s %12s %8s %14s %12s %8s\n", "prio", "tx pause", "tx/s", "paused", "rx pause", "rx/s", "paused")
+		secs := now.Sub(prev.time).Seconds()
+		var sent, received [dcb.IEEE_8021QAZ_MAX_TCS]time.Duration
+		speed := linkSpeed(r.Ifname)
+		if seen {
+			sent, received = dcb.PauseTimes(prev.pfc, r.PFC, t.quanta, speed, now.Sub(prev.time))
+		}
+		for prio := 0; prio < dcb.IEEE_8021QAZ_MAX_TCS; prio++ {
+			tx, rx := r.PFC.Requests[prio], r.PFC.Indications[prio]
+			lossless := r.PFC.PFCEn&(1<<prio) != 0
+			if !t.all && !lossless && tx == 0 && rx == 0 {
+				continue
+			}
+			txRate, rxRate, txPaused, rxPaused, moved := "-", "-", "-", "-", false
+			if seen && secs > 0 {
+				if speed > 0 {
+					txPaused, rxPaused = pauseShare(sent[prio], now.Sub(prev.time)), pauseShare(received[prio], now.Sub(prev.time))
+				}
+				var reset bool
+				dtx, drx := counterSub(tx, prev.pfc.Requests[prio], &reset), counterSub(rx, prev.pfc.Indications[prio], &reset)
+				txRate, rxRate = fmt.Sprintf("%.1f", float64(dtx)/secs), fmt.Sprintf("%.1f", float64(drx)/secs)
+				moved = dtx > 0 || drx > 0
+			}
+			line := fmt.Sprintf("  %-14s %14d %12s %8s %14d %12s %8s", global.labels.name(prio), tx, txRate, txPaused, rx, rxRate, rxPaused)
+			if moved {
+				line = ansiReverse + line + ansiReset
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("highlighted: pause frames since the last refresh; paused: estimated share of the time the peer (tx) or this end (rx) was paused; ctrl-c to quit\n")
+	return b.Bytes()
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The human-readable forms of the values the kernel holds in its own units.
+// With the global -raw flag the kernel values are printed instead, for
+// scripts; the parsers accept both forms either way.
+
+// rateUnits are the maxrate units in kbit/s, largest first.
+var rateUnits = []struct {
+	suffix string
+	kbps   uint64
+}{{"Tbit", 1e9}, {"Gbit", 1e6}, {"Mbit", 1e3}, {"kbit", 1}}
+
+// formatRate formats a maxrate limit in kbit/s, such as "25Gbit", in the
+// largest unit that shows it exactly with at most three decimals, so that
+// parseRate reads back the same value.
+func formatRate(kbps uint64) string {
+	switch {
+	case global.raw:
+		return strconv.FormatUint(kbps, 10)
+	case kbps == 0:
+		return "unlimited"
+	}
+	return rateWithUnit(kbps)
+}
+
+// rateWithUnit formats kbps as formatRate does, whatever -raw; 0 is "0kbit".
+func rateWithUnit(kbps uint64) string {
+	for _, u := range rateUnits {
+		if kbps >= u.kbps && (u.kbps < 1e3 || kbps%(u.kbps/1e3) == 0) {
+			return formatDecimal(kbps, u.kbps) + u.suffix
+		}
+	}
+	return strconv.FormatUint(kbps, 10) + "kbit"
+}
+
+// parseRate parses a maxrate limit such as "25Gbit", "25G", "500Mbit",
+// "unlimited", a percentage of the link speed such as "12.5%", a number of
+// bit/s such as "8000bit", or a bare number of kbit/s, and returns it in
+// kbit/s. Values that are not a whole number of kbit/s are rejected rather
+// than rounded. Percentages need the link speed, speedMbps 0 rejects them.
+func parseRate(s string, speedMbps uint64) (uint64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if v == "unlimited" {
+		return 0, nil
+	}
+	if pct, ok := strings.CutSuffix(v, "%"); ok {
+		if speedMbps == 0 {
+			return 0, fmt.Errorf("rate %q: link speed unknown, give the rate in bits", s)
+		}
+		// 1% of 1 Mb/s is 10 kbit/s
+		kbps, ok := parseDecimal(pct, speedMbps*10)
+		if !ok || kbps > speedMbps*1e3 {
+			return 0, fmt.Errorf("invalid rate %q, want a percentage of the %d Mb/s link that is a whole number of kbit/s", s, speedMbps)
+		}
+		return kbps, nil
+	}
+	v, bit := strings.CutSuffix(v, "bit")
+	mult, prefixed := uint64(1), false
+	for _, u := range rateUnits {
+		if p := strings.ToLower(u.suffix[:1]); strings.HasSuffix(v, p) {
+			mult, v, prefixed = u.kbps, strings.TrimSuffix(v, p), true
+			break
+		}
+	}
+	var kbps uint64
+	var ok bool
+	if bit && !prefixed {
+		// bit/s, as tc takes them
+		var bps uint64
+		bps, ok = parseDecimal(v, 1)
+		kbps, ok = bps/1000, ok && bps%1000 == 0
+	} else {
+		kbps, ok = parseDecimal(v, mult)
+	}
+	if !ok {
+		return 0, fmt.Errorf("invalid rate %q, want a whole number of kbit/s such as 25Gbit, 500Mbit or 12.5%%", s)
+	}
+	return kbps, nil
+}
+
+// parseBandwidth parses an ETS bandwidth share such as "40", "40%", or a
+// rate such as "25Gbit" converted to a share of the link speed. Shares are
+// whole percents, so "12.5%", or a rate that is not a whole percent of the
+// link, is rejected.
+func parseBandwidth(s string, speedMbps uint64) (uint8, error) {
+	v := strings.TrimSpace(s)
+	pct, ok := parseDecimal(strings.TrimSuffix(v, "%"), 1)
+	if strings.HasSuffix(v, "%") || ok {
+		if !ok || pct > 100 {
+			return 0, fmt.Errorf("invalid bandwidth %q, want a whole percent from 0 to 100", s)
+		}
+		return uint8(pct), nil
+	}
+	kbps, err := parseRate(v, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q, want a whole percent or a rate", s)
+	}
+	if speedMbps == 0 {
+		return 0, fmt.Errorf("bandwidth %q: link speed unknown, give the bandwidth in percent", s)
+	}
+	if kbps > speedMbps*1e3 {
+		return 0, fmt.Errorf("bandwidth %q exceeds the %d Mb/s link", s, speedMbps)
+	}
+	// 1% of 1 Mb/s is 10 kbit/s
+	if kbps%(speedMbps*10) != 0 {
+		return 0, fmt.Errorf("bandwidth %q is not a whole percent of the %d Mb/s link", s, speedMbps)
+	}
+	return uint8(kbps / (speedMbps * 10)), nil
+}
+
+// parseDecimal parses the unsigned decimal number s scaled by scale, such as
+// "12.5" by 1000 to 12500. It fails unless the result is a whole number
+// that fits a uint64; exponents, signs, special values and bare points, as
+// in ".5" or "5.", are not accepted.
+func parseDecimal(s string, scale uint64) (uint64, bool) {
+	whole, frac, dot := strings.Cut(s, ".")
+	if whole == "" || dot && frac == "" || strings.Trim(whole+frac, "0123456789") != "" {
+		return 0, false
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > 19 {
+		return 0, false
+	}
+	w, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	var f uint64
+	if frac != "" {
+		if f, err = strconv.ParseUint(frac, 10, 64); err != nil {
+			return 0, false
+		}
+	}
+	hi, lo := bits.Mul64(w, scale)
+	if hi != 0 {
+		return 0, false
+	}
+	fhi, flo := bits.Mul64(f, scale)
+	den := uint64(1)
+	for range frac {
+		den *= 10
+	}
+	if fhi >= den {
+		return 0, false
+	}
+	q, r := bits.Div64(fhi, flo, den)
+	if r != 0 {
+		return 0, false
+	}
+	n, carry := bits.Add64(lo, q, 0)
+	return n, carry == 0
+}
+
+// formatDecimal formats v divided by scale, a power of ten, exactly and
+// without trailing zeros.
+func formatDecimal(v, scale uint64) string {
+	s := strconv.FormatUint(v/scale, 10)
+	if r := v % scale; r != 0 {
+		frac := strconv.FormatUint(r+scale, 10)[1:]
+		s += "." + strings.TrimRight(frac, "0")
+	}
+	return s
+}
+
+// formatSize formats a buffer size in bytes, such as "256KiB".
+func formatSize(bytes uint64) string {
+	switch {
+	case global.raw:
+		return strconv.FormatUint(bytes, 10)
+	case bytes >= 1<<20:
+		return trimFloat(float64(bytes)/(1<<20)) + "MiB"
+	case bytes >= 1<<10:
+		return trimFloat(float64(bytes)/(1<<10)) + "KiB"
+	}
+	return strconv.FormatUint(bytes, 10) + "B"
+}
+
+// parseSize parses a buffer size such as "256KiB", "256K", "1.5MiB" or a
+// bare number of bytes, and returns it in bytes. The prefixes are binary.
+func parseSize(s string) (uint64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	v = strings.TrimSuffix(v, "b")
+	v = strings.TrimSuffix(v, "i")
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(v, "g"):
+		mult, v = 1<<30, strings.TrimSuffix(v, "g")
+	case strings.HasSuffix(v, "m"):
+		mult, v = 1<<20, strings.TrimSuffix(v, "m")
+	case strings.HasSuffix(v, "k"):
+		mult, v = 1<<10, strings.TrimSuffix(v, "k")
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 || f*mult > math.MaxUint32 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(math.Round(f * mult)), nil
+}
+
+// formatDelay formats a delay in bit times as the time it lasts at the link
+// speed, along with the bit times; speedMbps 0 means the speed is unknown.
+func formatDelay(bits, speedMbps uint64) string {
+	switch {
+	case global.raw:
+		return strconv.FormatUint(bits, 10)
+	case speedMbps == 0:
+		return fmt.Sprintf("%d bit times (%s)", bits, formatSize((bits+7)/8))
+	}
+	return fmt.Sprintf("%v at %d Mb/s (%d bit times, %s)", time.Duration(bits*1e3/speedMbps), speedMbps, bits, formatSize((bits+7)/8))
+}
+
+// parseDelay parses a delay such as "2.5us", "2.5µs", "800ns" or a bare
+// number of bit times, and returns it in bit times. Times need the link
+// speed to be converted, speedMbps 0 rejects them.
+func parseDelay(s string, speedMbps uint64) (uint64, error) {
+	v := strings.TrimSpace(s)
+	if bits, err := strconv.ParseUint(strings.TrimSuffix(v, "bit"), 10, 64); err == nil {
+		return bits, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid delay %q, want bit times or a time such as 2us", s)
+	}
+	if speedMbps == 0 {
+		return 0, fmt.Errorf("delay %q: link speed unknown, give the delay in bit times", s)
+	}
+	// a bit lasts 1µs at 1 Mb/s
+	return uint64(math.Round(float64(d.Nanoseconds()) * float64(speedMbps) / 1e3)), nil
+}
+
+// trimFloat formats v with at most two decimals and no trailing zeros.
+func trimFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// sizeFlag returns a flag.Func setter parsing a size into dst.
+func sizeFlag(dst *int) func(string) error {
+	return func(s string) error {
+		n, err := parseSize(s)
+		if err != nil {
+			return err
+		}
+		*dst = int(n)
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s     string
+		scale uint64
+		want  uint64
+		ok    bool
+	}{
+		{"0", 1, 0, true},
+		{"12", 1, 12, true},
+		{"12.5", 1000, 12500, true},
+		{"1.0", 1, 1, true},
+		{"1.500", 1000, 1500, true},
+		{"0.1", 10, 1, true},
+		// 4.35 * 100 is 434.99999999999994 in floating point
+		{"4.35", 100, 435, true},
+		{"18446744073709551615", 1, math.MaxUint64, true},
+		{"1.8446744073709551615", 1e19, math.MaxUint64, true},
+
+		{"12.5", 1, 0, false},
+		{"0.01", 10, 0, false},
+		{"18446744073709551616", 1, 0, false},
+		{"18446744073709551615", 10, 0, false},
+		{"", 1, 0, false},
+		{".5", 10, 0, false},
+		{"5.", 10, 0, false},
+		{"1e3", 1, 0, false},
+		{"-1", 1, 0, false},
+		{"+1", 1, 0, false},
+		{"1_000", 1, 0, false},
+		{"Inf", 1, 0, false},
+		{"1.2.3", 100, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDecimal(tt.s, tt.scale)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseDecimal(%q, %d) = %d, %v, want %d, %v", tt.s, tt.scale, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		s         string
+		speedMbps uint64
+		want      uint64
+		ok        bool
+	}{
+		{"unlimited", 0, 0, true},
+		{"25Gbit", 0, 25e6, true},
+		{"25G", 0, 25e6, true},
+		{"25gbit", 0, 25e6, true},
+		{"500Mbit", 0, 500e3, true},
+		{"1.5Mbit", 0, 1500, true},
+		{"4.35Gbit", 0, 4350e3, true},
+		{"2Tbit", 0, 2e9, true},
+		{"10kbit", 0, 10, true},
+		{"10K", 0, 10, true},
+		{"10", 0, 10, true},
+		{" 10 ", 0, 10, true},
+		{"8000bit", 0, 8, true},
+		{"12.5%", 100000, 12.5e6, true},
+		{"100%", 25000, 25e6, true},
+		{"0.1%", 1, 1, true},
+
+		// not a whole number of kbit/s
+		{"1.5kbit", 0, 0, false},
+		{"1.5", 0, 0, false},
+		{"10bit", 0, 0, false},
+		{"1500bit", 0, 0, false},
+		{"0.01%", 1, 0, false},
+		// percentages need the link speed and stay within it
+		{"12.5%", 0, 0, false},
+		{"101%", 1000, 0, false},
+		// odd units and forms
+		{"bit", 0, 0, false},
+		{"G", 0, 0, false},
+		{"10Gbps", 0, 0, false},
+		{"10Gb", 0, 0, false},
+		{"10 Gbit", 0, 0, false},
+		{"10bitbit", 0, 0, false},
+		{"1e3", 0, 0, false},
+		{"-5G", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseRate(tt.s, tt.speedMbps)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseRate(%q, %d) = %d, %v, want %d, ok %v", tt.s, tt.speedMbps, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRateRoundTrip(t *testing.T) {
+	for _, kbps := range []uint64{0, 1, 999, 1000, 1500, 12345678, 25e6, 1e9, 2.5e9, 1 << 40, math.MaxUint64} {
+		s := rateWithUnit(kbps)
+		got, err := parseRate(s, 0)
+		if err != nil || got != kbps {
+			t.Errorf("parseRate(rateWithUnit(%d) = %q) = %d, %v", kbps, s, got, err)
+		}
+	}
+}
+
+func TestParseBandwidth(t *testing.T) {
+	tests := []struct {
+		s         string
+		speedMbps uint64
+		want      uint8
+		ok        bool
+	}{
+		{"0", 0, 0, true},
+		{"40", 0, 40, true},
+		{"40%", 0, 40, true},
+		{"100%", 0, 100, true},
+		{"25Gbit", 100000, 25, true},
+		{"1000Mbit", 10000, 10, true},
+
+		// shares are whole percents
+		{"12.5%", 0, 0, false},
+		{"12.5", 0, 0, false},
+		{"12.5Gbit", 100000, 0, false},
+		{"101", 0, 0, false},
+		{"%", 0, 0, false},
+		// rates need the link speed and stay within it
+		{"25Gbit", 0, 0, false},
+		{"200Gbit", 100000, 0, false},
+		{"10bit", 100000, 0, false},
+		{"-40", 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseBandwidth(tt.s, tt.speedMbps)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseBandwidth(%q, %d) = %d, %v, want %d, ok %v", tt.s, tt.speedMbps, got, err, tt.want, tt.ok)
+		}
+	}
+}